@@ -0,0 +1,374 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+// Command gotestjson reports the results of go test to CI Visibility from its
+// JSON output, for modules which can't be instrumented with orchestrion or by
+// calling gotesting.RunM in TestMain:
+//
+//	go test -json ./... | DD_API_KEY=... DD_CIVISIBILITY_AGENTLESS_ENABLED=true gotestjson
+//
+// A test session is reported for the whole run, and a test by test or subtest,
+// tagged with the CI provider and git metadata of the environment like the
+// tests instrumented in process. The test modules and suites are named like the
+// gotesting integration names them: the module after the package (with the
+// _test suffix for the external tests) and the suite after the file declaring
+// the test, which is found by running go list in the working directory. The
+// tests whose file can't be found are reported in a suite named after the
+// package instead, which doesn't match the gotesting naming. The packages
+// without test files aren't reported. The output of the failed tests is
+// reported as their error message.
+//
+// The output of the tests is written to stdout unless -quiet is set, and the
+// command exits with a non-zero status if any test or package failed, so it
+// can replace go test in a pipeline.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/integrations"
+)
+
+// testFramework is the name of the testing framework reported.
+const testFramework = "golang.org/pkg/testing"
+
+func main() {
+	var (
+		command = flag.String("command", "go test -json", "Command which produced the JSON output, reported as the test session command")
+		quiet   = flag.Bool("quiet", false, "Don't write the output of the tests to stdout")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: go test -json [packages] | gotestjson [flags]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	integrations.EnsureCiVisibilityInitialization()
+
+	var output io.Writer = os.Stdout
+	if *quiet {
+		output = io.Discard
+	}
+	c := newConverter(*command, output)
+	if err := c.convert(os.Stdin); err != nil {
+		fmt.Fprintf(os.Stderr, "gotestjson: %v\n", err)
+	}
+	exitCode := c.close()
+	integrations.ExitCiVisibility()
+	os.Exit(exitCode)
+}
+
+// event is an event of the go test JSON output, see go doc test2json.
+type event struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// pkg holds the state of a package.
+type pkg struct {
+	name  string
+	start time.Time
+	// locations holds the location of the test functions of the package, by name.
+	locations map[string]location
+	// modules holds the test modules of the package, by name, created with their first test.
+	modules map[string]integrations.TestModule
+	// suites holds the test suites of the package, by location.
+	suites map[location]integrations.TestSuite
+	// output holds the output which isn't part of a test, e.g. build errors.
+	output strings.Builder
+	// tests holds the tests running, by name.
+	tests map[string]*test
+}
+
+// location is the test module and suite of a test function.
+type location struct {
+	module string
+	suite  string
+}
+
+// test holds the state of a running test.
+type test struct {
+	test   integrations.Test
+	output strings.Builder
+}
+
+// converter reports the events of the go test JSON output as CI Visibility events.
+type converter struct {
+	command string
+	output  io.Writer
+	// locate returns the location of the test functions of a package, by name.
+	locate   func(pkg string) map[string]location
+	session  integrations.TestSession
+	packages map[string]*pkg
+	failed   bool
+	lastTime time.Time
+}
+
+func newConverter(command string, output io.Writer) *converter {
+	return &converter{
+		command:  command,
+		output:   output,
+		locate:   locateTests,
+		packages: map[string]*pkg{},
+	}
+}
+
+// convert reads the JSON events from r until EOF.
+func (c *converter) convert(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var e event
+		if err := json.Unmarshal(line, &e); err != nil || e.Action == "" {
+			// Lines which aren't events, e.g. build errors written by go test itself.
+			fmt.Fprintf(c.output, "%s\n", line)
+			continue
+		}
+		c.handle(e)
+	}
+	return scanner.Err()
+}
+
+// handle handles an event.
+func (c *converter) handle(e event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	c.lastTime = e.Time
+	if e.Action == "output" {
+		fmt.Fprint(c.output, e.Output)
+	}
+	if e.Package == "" {
+		return
+	}
+
+	p := c.getOrCreatePackage(e)
+	if e.Test == "" {
+		switch e.Action {
+		case "output":
+			p.output.WriteString(e.Output)
+		case "pass", "skip":
+			c.closePackage(e.Package, p, e.Time, "")
+		case "fail":
+			c.failed = true
+			c.closePackage(e.Package, p, e.Time, p.output.String())
+		}
+		return
+	}
+
+	t := p.tests[e.Test]
+	switch e.Action {
+	case "run":
+		if t == nil {
+			p.tests[e.Test] = &test{test: c.getOrCreateSuite(p, e.Test).CreateTest(e.Test, integrations.WithTestStartTime(e.Time))}
+		}
+	case "output":
+		if t != nil {
+			t.output.WriteString(e.Output)
+		}
+	case "pass", "bench", "fail", "skip":
+		if t == nil {
+			// Benchmarks are only reported once completed.
+			t = &test{test: c.getOrCreateSuite(p, e.Test).CreateTest(e.Test, integrations.WithTestStartTime(e.Time.Add(-elapsed(e))))}
+		}
+		delete(p.tests, e.Test)
+		finishTime := integrations.WithTestFinishTime(e.Time)
+		switch e.Action {
+		case "fail":
+			c.failed = true
+			t.test.SetError(integrations.WithErrorInfo("failure", testMessage(t.output.String()), ""))
+			t.test.Close(integrations.ResultStatusFail, finishTime)
+		case "skip":
+			t.test.Close(integrations.ResultStatusSkip, finishTime, integrations.WithTestSkipReason(testMessage(t.output.String())))
+		default:
+			t.test.Close(integrations.ResultStatusPass, finishTime)
+		}
+	}
+}
+
+// getOrCreatePackage returns the state of the package of an event.
+func (c *converter) getOrCreatePackage(e event) *pkg {
+	if p, ok := c.packages[e.Package]; ok {
+		return p
+	}
+	p := &pkg{
+		name:    e.Package,
+		start:   e.Time,
+		modules: map[string]integrations.TestModule{},
+		suites:  map[location]integrations.TestSuite{},
+		tests:   map[string]*test{},
+	}
+	c.packages[e.Package] = p
+	return p
+}
+
+// getOrCreateSuite returns the test suite of a test of a package, creating the session, module and suite as
+// needed, so that nothing is reported for the packages without tests.
+func (c *converter) getOrCreateSuite(p *pkg, name string) integrations.TestSuite {
+	if p.locations == nil {
+		p.locations = c.locate(p.name)
+		if p.locations == nil {
+			p.locations = map[string]location{}
+		}
+	}
+	fn, _, _ := strings.Cut(name, "/")
+	loc, ok := p.locations[fn]
+	if !ok {
+		loc = location{module: p.name, suite: p.name}
+	}
+	if suite, ok := p.suites[loc]; ok {
+		return suite
+	}
+	suite := c.getOrCreateModule(p, loc.module).GetOrCreateSuite(loc.suite, integrations.WithTestSuiteStartTime(p.start))
+	p.suites[loc] = suite
+	return suite
+}
+
+// getOrCreateModule returns the test module of a package with the given name, creating the session as needed.
+func (c *converter) getOrCreateModule(p *pkg, name string) integrations.TestModule {
+	if module, ok := p.modules[name]; ok {
+		return module
+	}
+	if c.session == nil {
+		c.session = integrations.CreateTestSession(
+			integrations.WithTestSessionCommand(c.command),
+			integrations.WithTestSessionFramework(testFramework, runtime.Version()),
+			integrations.WithTestSessionStartTime(p.start))
+	}
+	module := c.session.GetOrCreateModule(name,
+		integrations.WithTestModuleFramework(testFramework, runtime.Version()),
+		integrations.WithTestModuleStartTime(p.start))
+	p.modules[name] = module
+	return module
+}
+
+// closePackage closes the tests left running by a package, e.g. because of a panic or a timeout, and the
+// package suites and modules. The failure of a package without tests, e.g. a build failure, is reported in
+// a module named after the package.
+func (c *converter) closePackage(name string, p *pkg, finishTime time.Time, failure string) {
+	for _, t := range p.tests {
+		message := testMessage(t.output.String())
+		if message == "" {
+			message = testMessage(p.output.String())
+		}
+		t.test.SetError(integrations.WithErrorInfo("failure", message, ""))
+		t.test.Close(integrations.ResultStatusFail, integrations.WithTestFinishTime(finishTime))
+	}
+	if message := testMessage(failure); message != "" {
+		if len(p.modules) == 0 {
+			c.getOrCreateModule(p, p.name)
+		}
+		for _, module := range p.modules {
+			module.SetError(integrations.WithErrorInfo("failure", message, ""))
+		}
+	}
+	for _, suite := range p.suites {
+		suite.Close(integrations.WithTestSuiteFinishTime(finishTime))
+	}
+	for _, module := range p.modules {
+		module.Close(integrations.WithTestModuleFinishTime(finishTime))
+	}
+	delete(c.packages, name)
+}
+
+// close closes the packages which didn't complete and the session, and returns the exit code of the run.
+func (c *converter) close() int {
+	for name, p := range c.packages {
+		c.failed = true
+		c.closePackage(name, p, c.lastTime, p.output.String())
+	}
+	exitCode := 0
+	if c.failed {
+		exitCode = 1
+	}
+	if c.session != nil {
+		c.session.Close(exitCode, integrations.WithTestSessionFinishTime(c.lastTime))
+	}
+	return exitCode
+}
+
+// elapsed returns the duration of a test from its completion event.
+func elapsed(e event) time.Duration {
+	return time.Duration(e.Elapsed * float64(time.Second))
+}
+
+// testMessage returns the output of a test without the lines written by the testing package to frame it.
+func testMessage(output string) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" ||
+			trimmed == "FAIL" ||
+			trimmed == "PASS" ||
+			strings.HasPrefix(trimmed, "FAIL\t") ||
+			strings.HasPrefix(trimmed, "ok  \t") ||
+			strings.HasPrefix(trimmed, "=== ") ||
+			strings.HasPrefix(trimmed, "--- ") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// locateTests returns the module and suite of the test functions of a package as the gotesting integration
+// names them: the module after the package of the test function and the suite after the file declaring it.
+// The package is listed with go list, so it returns nil if it isn't found from the working directory.
+func locateTests(pkg string) map[string]location {
+	out, err := exec.Command("go", "list", "-json", pkg).Output()
+	if err != nil {
+		return nil
+	}
+	var info struct {
+		Dir          string
+		ImportPath   string
+		TestGoFiles  []string
+		XTestGoFiles []string
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return nil
+	}
+	locations := map[string]location{}
+	fset := token.NewFileSet()
+	for module, files := range map[string][]string{
+		info.ImportPath:           info.TestGoFiles,
+		info.ImportPath + "_test": info.XTestGoFiles,
+	} {
+		for _, file := range files {
+			f, err := parser.ParseFile(fset, filepath.Join(info.Dir, file), nil, parser.SkipObjectResolution)
+			if err != nil {
+				continue
+			}
+			for _, decl := range f.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+					locations[fn.Name.Name] = location{module: module, suite: file}
+				}
+			}
+		}
+	}
+	return locations
+}
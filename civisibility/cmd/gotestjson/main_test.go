@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/mocktracer"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/integrations"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mockTracer mocktracer.Tracer
+
+func TestMain(m *testing.M) {
+	mockTracer = integrations.InitializeCIVisibilityMock()
+	os.Exit(m.Run())
+}
+
+const testOutput = `{"Time":"2025-01-01T10:00:00Z","Action":"start","Package":"example.com/a"}
+{"Time":"2025-01-01T10:00:00.1Z","Action":"run","Package":"example.com/a","Test":"TestPass"}
+{"Time":"2025-01-01T10:00:00.1Z","Action":"output","Package":"example.com/a","Test":"TestPass","Output":"=== RUN   TestPass\n"}
+{"Time":"2025-01-01T10:00:00.2Z","Action":"output","Package":"example.com/a","Test":"TestPass","Output":"--- PASS: TestPass (0.10s)\n"}
+{"Time":"2025-01-01T10:00:00.2Z","Action":"pass","Package":"example.com/a","Test":"TestPass","Elapsed":0.1}
+{"Time":"2025-01-01T10:00:00.2Z","Action":"run","Package":"example.com/a","Test":"TestFail"}
+{"Time":"2025-01-01T10:00:00.2Z","Action":"run","Package":"example.com/a","Test":"TestFail/sub"}
+{"Time":"2025-01-01T10:00:00.3Z","Action":"output","Package":"example.com/a","Test":"TestFail/sub","Output":"    a_test.go:12: expected 1, got 2\n"}
+{"Time":"2025-01-01T10:00:00.3Z","Action":"output","Package":"example.com/a","Test":"TestFail/sub","Output":"    --- FAIL: TestFail/sub (0.10s)\n"}
+{"Time":"2025-01-01T10:00:00.3Z","Action":"fail","Package":"example.com/a","Test":"TestFail/sub","Elapsed":0.1}
+{"Time":"2025-01-01T10:00:00.3Z","Action":"fail","Package":"example.com/a","Test":"TestFail","Elapsed":0.1}
+{"Time":"2025-01-01T10:00:00.3Z","Action":"run","Package":"example.com/a","Test":"TestSkip"}
+{"Time":"2025-01-01T10:00:00.3Z","Action":"output","Package":"example.com/a","Test":"TestSkip","Output":"    a_test.go:20: not on this platform\n"}
+{"Time":"2025-01-01T10:00:00.3Z","Action":"skip","Package":"example.com/a","Test":"TestSkip","Elapsed":0}
+{"Time":"2025-01-01T10:00:00.4Z","Action":"output","Package":"example.com/a","Output":"FAIL\texample.com/a\t0.4s\n"}
+{"Time":"2025-01-01T10:00:00.4Z","Action":"fail","Package":"example.com/a","Elapsed":0.4}
+# example.com/b
+{"Time":"2025-01-01T10:00:00.5Z","Action":"start","Package":"example.com/b"}
+{"Time":"2025-01-01T10:00:00.5Z","Action":"run","Package":"example.com/b","Test":"TestPanic"}
+{"Time":"2025-01-01T10:00:00.6Z","Action":"output","Package":"example.com/b","Output":"panic: boom\n"}
+{"Time":"2025-01-01T10:00:00.7Z","Action":"start","Package":"example.com/c"}
+{"Time":"2025-01-01T10:00:00.7Z","Action":"output","Package":"example.com/c","Output":"?   \texample.com/c\t[no test files]\n"}
+{"Time":"2025-01-01T10:00:00.7Z","Action":"skip","Package":"example.com/c","Elapsed":0}
+`
+
+func TestConvert(t *testing.T) {
+	mockTracer.Reset()
+
+	var output strings.Builder
+	c := newConverter("go test -json ./...", &output)
+	c.locate = func(pkg string) map[string]location {
+		if pkg != "example.com/a" {
+			return nil
+		}
+		return map[string]location{
+			"TestPass": {module: "example.com/a", suite: "a_test.go"},
+			"TestFail": {module: "example.com/a_test", suite: "x_test.go"},
+			"TestSkip": {module: "example.com/a", suite: "a_test.go"},
+		}
+	}
+	require.NoError(t, c.convert(strings.NewReader(testOutput)))
+	assert.Equal(t, 1, c.close())
+	assert.Contains(t, output.String(), "a_test.go:12: expected 1, got 2\n")
+	assert.Contains(t, output.String(), "# example.com/b\n")
+
+	spans := mockTracer.FinishedSpans()
+	byType := map[string][]*mocktracer.Span{}
+	for _, span := range spans {
+		byType[span.Tag(ext.SpanType).(string)] = append(byType[span.Tag(ext.SpanType).(string)], span)
+	}
+	require.Len(t, byType[constants.SpanTypeTestSession], 1)
+	// The package without test files isn't reported.
+	var modules, suites []string
+	for _, span := range byType[constants.SpanTypeTestModule] {
+		modules = append(modules, span.Tag(constants.TestModule).(string))
+	}
+	for _, span := range byType[constants.SpanTypeTestSuite] {
+		suites = append(suites, span.Tag(constants.TestSuite).(string))
+	}
+	assert.ElementsMatch(t, []string{"example.com/a", "example.com/a_test", "example.com/b"}, modules)
+	assert.ElementsMatch(t, []string{"a_test.go", "x_test.go", "example.com/b"}, suites)
+
+	session := byType[constants.SpanTypeTestSession][0]
+	assert.Equal(t, "go test -json ./...", session.Tag(constants.TestCommand))
+	assert.Equal(t, constants.TestStatusFail, session.Tag(constants.TestStatus))
+	assert.NotNil(t, session.Tag(constants.GitCommitSHA))
+
+	tests := map[string]*mocktracer.Span{}
+	for _, span := range byType[constants.SpanTypeTest] {
+		tests[span.Tag(constants.TestName).(string)] = span
+	}
+	require.Len(t, tests, 5)
+
+	pass := tests["TestPass"]
+	assert.Equal(t, constants.TestStatusPass, pass.Tag(constants.TestStatus))
+	assert.Equal(t, "example.com/a", pass.Tag(constants.TestModule))
+	assert.Equal(t, "a_test.go", pass.Tag(constants.TestSuite))
+	assert.Equal(t, "2025-01-01T10:00:00.1Z", pass.StartTime().UTC().Format("2006-01-02T15:04:05.999Z"))
+	assert.Equal(t, "2025-01-01T10:00:00.2Z", pass.FinishTime().UTC().Format("2006-01-02T15:04:05.999Z"))
+
+	sub := tests["TestFail/sub"]
+	assert.Equal(t, constants.TestStatusFail, sub.Tag(constants.TestStatus))
+	assert.Equal(t, "    a_test.go:12: expected 1, got 2", sub.Tag(ext.ErrorMsg))
+	assert.Equal(t, "example.com/a_test", sub.Tag(constants.TestModule))
+	assert.Equal(t, "x_test.go", sub.Tag(constants.TestSuite))
+	assert.Equal(t, constants.TestStatusFail, tests["TestFail"].Tag(constants.TestStatus))
+
+	skip := tests["TestSkip"]
+	assert.Equal(t, constants.TestStatusSkip, skip.Tag(constants.TestStatus))
+	assert.Equal(t, "    a_test.go:20: not on this platform", skip.Tag(constants.TestSkipReason))
+
+	// The test interrupted by the panic is failed with the output of the package.
+	panicked := tests["TestPanic"]
+	assert.Equal(t, constants.TestStatusFail, panicked.Tag(constants.TestStatus))
+	assert.Equal(t, "panic: boom", panicked.Tag(ext.ErrorMsg))
+	// Its file isn't known, so its suite is named after the package.
+	assert.Equal(t, "example.com/b", panicked.Tag(constants.TestSuite))
+}
+
+func TestLocateTests(t *testing.T) {
+	const pkg = "github.com/DataDog/dd-trace-go/v2/civisibility/cmd/gotestjson"
+	locations := locateTests(pkg)
+	assert.Equal(t, location{module: pkg, suite: "main_test.go"}, locations["TestLocateTests"])
+	assert.Nil(t, locateTests("example.com/unknown"))
+}
+
+func TestTestMessage(t *testing.T) {
+	output := "=== RUN   TestA\n    a_test.go:1: first\n    a_test.go:2: second\n--- FAIL: TestA (0.00s)\nFAIL\nFAIL\texample.com/a\t0.1s\n"
+	assert.Equal(t, "    a_test.go:1: first\n    a_test.go:2: second", testMessage(output))
+}
@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+// Command offlineupload uploads the CI Visibility payloads recorded in offline
+// mode, i.e. by tests run with DD_CIVISIBILITY_OFFLINE_DIR set, typically on
+// runners without network access.
+//
+// The payloads are sent as they were recorded, so the test events keep their
+// original timestamps and the git and CI metadata of the run that produced
+// them. The destination is configured with the same environment variables as
+// the tracer: DD_CIVISIBILITY_AGENTLESS_ENABLED, DD_API_KEY and DD_SITE for
+// agentless mode, or DD_TRACE_AGENT_URL to go through an agent:
+//
+//	DD_CIVISIBILITY_AGENTLESS_ENABLED=true DD_API_KEY=... offlineupload -dir ./dd-offline
+//
+// The command must run in the git repository of the tests: before the payloads,
+// it uploads the commits missing on the backend as the library does when it has
+// network access, unless -git=false is set.
+//
+// Uploaded payloads are removed from the directory unless -keep is set, so the
+// command can be run again to retry the payloads that failed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/integrations"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/net"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/offline"
+)
+
+func main() {
+	var (
+		dir  = flag.String("dir", os.Getenv(constants.CIVisibilityOfflineDirEnvironmentVariable), "Directory of the recorded payloads (defaults to $"+constants.CIVisibilityOfflineDirEnvironmentVariable+")")
+		keep = flag.Bool("keep", false, "Keep the payloads in the directory once uploaded")
+		git  = flag.Bool("git", true, "Upload the git metadata of the repository of the working directory")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: offlineupload [flags]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *dir == "" || flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// The upload must not be recorded again by the library.
+	os.Unsetenv(constants.CIVisibilityOfflineDirEnvironmentVariable)
+
+	if *git {
+		uploadGit(net.NewClient())
+	}
+	uploaded, failed, err := upload(*dir, newUploader(), *keep)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("uploaded %d payloads from %s", uploaded, *dir)
+	if failed > 0 {
+		log.Fatalf("failed to upload %d payloads", failed)
+	}
+}
+
+// uploader sends the payloads of every kind.
+type uploader struct {
+	testCycle net.Client
+	coverage  net.Client
+}
+
+func newUploader() *uploader {
+	return &uploader{
+		testCycle: net.NewClientForTestCycle(),
+		coverage:  net.NewClientForCodeCoverage(),
+	}
+}
+
+// send sends the payload p.
+func (u *uploader) send(p offline.Payload) error {
+	f, err := os.Open(p.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch p.Kind {
+	case offline.KindTestCycle:
+		if u.testCycle == nil {
+			return fmt.Errorf("no test cycle client, check the agentless configuration")
+		}
+		return u.testCycle.SendTestCyclePayload(f)
+	case offline.KindCoverage:
+		if u.coverage == nil {
+			return fmt.Errorf("no code coverage client, check the agentless configuration")
+		}
+		return u.coverage.SendCoveragePayload(f)
+	default:
+		return fmt.Errorf("unknown payload kind %q", p.Kind)
+	}
+}
+
+// uploadGit uploads the commits of the git repository of the working directory
+// which are missing on the backend. A failure is only logged, the payloads are
+// uploaded anyway.
+func uploadGit(c net.Client) {
+	if c == nil {
+		log.Printf("git: no client, check the agentless configuration")
+		return
+	}
+	bytes, err := integrations.UploadRepositoryChanges(c)
+	if err != nil {
+		log.Printf("git: %v", err)
+		return
+	}
+	log.Printf("git: uploaded %d bytes in pack files", bytes)
+}
+
+// upload sends the payloads of dir, oldest first, and removes the ones which
+// were sent unless keep is set. It returns the number of payloads uploaded and
+// the number of payloads which failed to upload, which are left in place.
+func upload(dir string, u *uploader, keep bool) (uploaded, failed int, err error) {
+	payloads, err := offline.Payloads(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, p := range payloads {
+		if err := u.send(p); err != nil {
+			log.Printf("%s: %v", p.Path, err)
+			failed++
+			continue
+		}
+		uploaded++
+		if keep {
+			continue
+		}
+		if err := os.Remove(p.Path); err != nil {
+			log.Printf("%s: %v", p.Path, err)
+		}
+	}
+	return uploaded, failed, nil
+}
@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/net"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/offline"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpload(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		testCycle []string
+		coverage  int
+		fail      bool
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			http.Error(w, "unavailable", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v2/citestcycle":
+			body, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			data, err := io.ReadAll(body)
+			require.NoError(t, err)
+			testCycle = append(testCycle, string(data))
+		case "/api/v2/citestcov":
+			coverage++
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	t.Setenv(constants.CIVisibilityAgentlessEnabledEnvironmentVariable, "true")
+	t.Setenv(constants.CIVisibilityAgentlessURLEnvironmentVariable, srv.URL)
+	t.Setenv(constants.APIKeyEnvironmentVariable, "12345")
+
+	dir := t.TempDir()
+	t.Setenv(constants.CIVisibilityOfflineDirEnvironmentVariable, dir)
+	for _, p := range []struct{ kind, data string }{
+		{offline.KindTestCycle, "first"},
+		{offline.KindCoverage, "coverage"},
+		{offline.KindTestCycle, "second"},
+	} {
+		_, err := offline.WritePayload(p.kind, strings.NewReader(p.data))
+		require.NoError(t, err)
+	}
+
+	t.Run("keep", func(t *testing.T) {
+		uploaded, failed, err := upload(dir, newUploader(), true)
+		require.NoError(t, err)
+		assert.Equal(t, 3, uploaded)
+		assert.Equal(t, 0, failed)
+		assert.Equal(t, []string{"first", "second"}, testCycle)
+		assert.Equal(t, 1, coverage)
+
+		payloads, err := offline.Payloads(dir)
+		require.NoError(t, err)
+		assert.Len(t, payloads, 3)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		fail = true
+		defer func() { fail = false }()
+		uploaded, failed, err := upload(dir, newUploader(), false)
+		require.NoError(t, err)
+		assert.Equal(t, 0, uploaded)
+		assert.Equal(t, 3, failed)
+
+		payloads, err := offline.Payloads(dir)
+		require.NoError(t, err)
+		assert.Len(t, payloads, 3)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		uploaded, failed, err := upload(dir, newUploader(), false)
+		require.NoError(t, err)
+		assert.Equal(t, 3, uploaded)
+		assert.Equal(t, 0, failed)
+
+		payloads, err := offline.Payloads(dir)
+		require.NoError(t, err)
+		assert.Empty(t, payloads)
+	})
+}
+
+func TestUploadGit(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(repo))
+	defer os.Chdir(wd)
+
+	var commits []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/git/repository/search_commits" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Data []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		for _, c := range req.Data {
+			commits = append(commits, c.ID)
+		}
+		// The backend already has every commit, so there's no pack file to send.
+		json.NewEncoder(w).Encode(req)
+	}))
+	defer srv.Close()
+
+	t.Setenv(constants.CIVisibilityAgentlessEnabledEnvironmentVariable, "true")
+	t.Setenv(constants.CIVisibilityAgentlessURLEnvironmentVariable, srv.URL)
+	t.Setenv(constants.APIKeyEnvironmentVariable, "12345")
+	t.Setenv("DD_GIT_REPOSITORY_URL", "https://github.com/DataDog/example.git")
+	utils.ResetCITags()
+	defer utils.ResetCITags()
+
+	uploadGit(net.NewClient())
+	head, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	require.NoError(t, err)
+	assert.Equal(t, []string{strings.TrimSpace(string(head))}, commits)
+}
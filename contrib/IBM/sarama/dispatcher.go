@@ -71,6 +71,9 @@ func (w *wrappedDispatcher) Run() {
 		next := tracer.StartSpan(w.cfg.consumerSpanName, opts...)
 		// reinject the span context so consumers can pick it up
 		tracer.Inject(next.Context(), carrier)
+		if w.cfg.dataStreamsEnabled {
+			tracer.TagDataStreamsSchema(next, msg.Topic, ext.SchemaOperationDeserialization)
+		}
 		setConsumeCheckpoint(w.cfg.dataStreamsEnabled, w.cfg.groupID, msg)
 		w.messages <- msg
 
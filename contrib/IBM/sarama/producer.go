@@ -203,6 +203,9 @@ func startProducerSpan(cfg *config, version sarama.KafkaVersion, msg *sarama.Pro
 		opts = append(opts, tracer.ChildOf(spanctx))
 	}
 	span := tracer.StartSpan(cfg.producerSpanName, opts...)
+	if cfg.dataStreamsEnabled {
+		tracer.TagDataStreamsSchema(span, msg.Topic, ext.SchemaOperationSerialization)
+	}
 	if version.IsAtLeast(sarama.V0_11_0_0) {
 		// re-inject the span context so consumers can pick it up
 		tracer.Inject(span.Context(), carrier)
@@ -85,6 +85,9 @@ func WrapPartitionConsumer(pc sarama.PartitionConsumer, opts ...Option) sarama.P
 			next := tracer.StartSpan(cfg.consumerSpanName, opts...)
 			// reinject the span context so consumers can pick it up
 			tracer.Inject(next.Context(), carrier)
+			if cfg.dataStreamsEnabled {
+				tracer.TagDataStreamsSchema(next, msg.Topic, ext.SchemaOperationDeserialization)
+			}
 			setConsumeCheckpoint(cfg.dataStreamsEnabled, cfg.groupID, msg)
 
 			wrapped.messages <- msg
@@ -315,6 +318,9 @@ func startProducerSpan(cfg *config, version sarama.KafkaVersion, msg *sarama.Pro
 		opts = append(opts, tracer.ChildOf(spanctx))
 	}
 	span := tracer.StartSpan(cfg.producerSpanName, opts...)
+	if cfg.dataStreamsEnabled {
+		tracer.TagDataStreamsSchema(span, msg.Topic, ext.SchemaOperationSerialization)
+	}
 	if version.IsAtLeast(sarama.V0_11_0_0) {
 		// re-inject the span context so consumers can pick it up
 		tracer.Inject(span.Context(), carrier)
@@ -84,6 +84,9 @@ func (tr *KafkaTracer) StartConsumeSpan(msg Message) *tracer.Span {
 		opts = append(opts, tracer.ChildOf(spanctx))
 	}
 	span, _ := tracer.StartSpanFromContext(tr.ctx, tr.consumerSpanName, opts...)
+	if tr.dsmEnabled {
+		tracer.TagDataStreamsSchema(span, msg.GetTopicPartition().GetTopic(), ext.SchemaOperationDeserialization)
+	}
 	// reinject the span context so consumers can pick it up
 	tracer.Inject(span.Context(), carrier)
 	return span
@@ -53,7 +53,7 @@ func (tr *KafkaTracer) SetConsumeCheckpoint(msg Message) {
 	carrier := NewMessageCarrier(msg)
 	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(
 		datastreams.ExtractFromBase64Carrier(context.Background(), carrier),
-		options.CheckpointParams{PayloadSize: getMsgSize(msg)},
+		options.CheckpointParams{PayloadSize: getMsgSize(msg), TransactionID: tr.transactionID(msg)},
 		edges...,
 	)
 	if !ok {
@@ -70,7 +70,7 @@ func (tr *KafkaTracer) SetProduceCheckpoint(msg Message) {
 	carrier := NewMessageCarrier(msg)
 	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(
 		datastreams.ExtractFromBase64Carrier(context.Background(), carrier),
-		options.CheckpointParams{PayloadSize: getMsgSize(msg)},
+		options.CheckpointParams{PayloadSize: getMsgSize(msg), TransactionID: tr.transactionID(msg)},
 		edges...,
 	)
 	if !ok || tr.librdKafkaVersion < 0x000b0400 {
@@ -80,6 +80,13 @@ func (tr *KafkaTracer) SetProduceCheckpoint(msg Message) {
 	datastreams.InjectToBase64Carrier(ctx, carrier)
 }
 
+func (tr *KafkaTracer) transactionID(msg Message) string {
+	if tr.transactionIDFn == nil {
+		return ""
+	}
+	return tr.transactionIDFn(msg)
+}
+
 func getMsgSize(msg Message) (size int64) {
 	for _, header := range msg.GetHeaders() {
 		size += int64(len(header.GetKey()) + len(header.GetValue()))
@@ -51,3 +51,14 @@ func WithConfig(cm *kafka.ConfigMap) Option {
 
 // WithDataStreams enables the Data Streams monitoring product features: https://www.datadoghq.com/product/data-streams-monitoring/
 var WithDataStreams = tracing.WithDataStreams
+
+// WithDataStreamsTransactionID sets the function extracting the transaction ID of the messages, e.g. an order ID,
+// to follow them across the Data Streams pathways. Messages without transaction ID return an empty string.
+func WithDataStreamsTransactionID(fn func(msg *kafka.Message) string) Option {
+	return tracing.WithDataStreamsTransactionID(func(msg tracing.Message) string {
+		if m, ok := msg.Unwrap().(*kafka.Message); ok {
+			return fn(m)
+		}
+		return ""
+	})
+}
@@ -28,6 +28,7 @@ type KafkaTracer struct {
 	groupID             string
 	tagFns              map[string]func(msg Message) interface{}
 	dsmEnabled          bool
+	transactionIDFn     func(msg Message) string
 	ckgoVersion         CKGoVersion
 	librdKafkaVersion   int
 }
@@ -154,3 +155,11 @@ func WithDataStreams() OptionFn {
 		tr.dsmEnabled = true
 	}
 }
+
+// WithDataStreamsTransactionID sets the function extracting the transaction ID of the messages, e.g. an order ID,
+// to follow them across the Data Streams pathways. Messages without transaction ID return an empty string.
+func WithDataStreamsTransactionID(fn func(msg Message) string) OptionFn {
+	return func(tr *KafkaTracer) {
+		tr.transactionIDFn = fn
+	}
+}
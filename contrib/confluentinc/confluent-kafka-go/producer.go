@@ -74,6 +74,9 @@ func (tr *KafkaTracer) StartProduceSpan(msg Message) *tracer.Span {
 		opts = append(opts, tracer.ChildOf(spanctx))
 	}
 	span, _ := tracer.StartSpanFromContext(tr.ctx, tr.producerSpanName, opts...)
+	if tr.dsmEnabled {
+		tracer.TagDataStreamsSchema(span, msg.GetTopicPartition().GetTopic(), ext.SchemaOperationSerialization)
+	}
 	// inject the span context so consumers can pick it up
 	tracer.Inject(span.Context(), carrier)
 	return span
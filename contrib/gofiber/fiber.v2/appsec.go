@@ -0,0 +1,151 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package fiber
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/DataDog/dd-trace-go/v2/appsec/events"
+	"github.com/DataDog/dd-trace-go/v2/instrumentation/appsec/emitter/httpsec"
+	"github.com/DataDog/dd-trace-go/v2/instrumentation/appsec/trace"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+// withAppSec runs the next handlers of the chain under the AppSec HTTP handler
+// operation. When a blocking action is returned by the WAF, the blocking
+// response is written to the fiber response and the next handlers are not
+// called.
+// Note that the route and path parameters are only known by fiber once the
+// request is routed to its handler, so path parameters are only available to
+// AppSec when the middleware is registered on the route itself.
+func withAppSec(c *fiber.Ctx, span trace.TagSetter) (err error) {
+	op, blockAtomic, ctx := httpsec.StartOperation(c.UserContext(), httpsec.HandlerOperationArgs{
+		Framework:   "github.com/gofiber/fiber/v2",
+		Method:      c.Method(),
+		RequestURI:  string(c.Request().Header.RequestURI()),
+		Host:        c.Hostname(),
+		RemoteAddr:  c.Context().RemoteAddr().String(),
+		Headers:     canonicalHeaders(c.GetReqHeaders()),
+		Cookies:     requestCookies(c),
+		QueryParams: queryParams(c),
+		PathParams:  c.AllParams(),
+	}, span)
+	c.SetUserContext(ctx)
+
+	defer func() {
+		op.Finish(httpsec.HandlerOperationRes{
+			Headers:    canonicalHeaders(c.GetRespHeaders()),
+			StatusCode: c.Response().StatusCode(),
+		})
+		if blockPtr := blockAtomic.Load(); blockPtr != nil && blockPtr.Handler != nil {
+			err = writeBlockingResponse(c, blockPtr.Handler)
+		}
+	}()
+
+	if body := parsedBody(c); body != nil && blockAtomic.Load() == nil {
+		// A blocking decision is reported through blockAtomic, which is
+		// checked right below, so the returned error can be ignored.
+		_ = httpsec.MonitorParsedBody(ctx, body)
+	}
+	if blockPtr := blockAtomic.Load(); blockPtr != nil && blockPtr.Handler != nil {
+		err = writeBlockingResponse(c, blockPtr.Handler)
+		blockPtr.Handler = nil
+		return err
+	}
+
+	err = c.Next()
+	// If the error is a blocking one, the blocking response is written by the
+	// deferred function above and the fiber error handler must not be called.
+	var blockErr *events.BlockingSecurityEvent
+	if errors.As(err, &blockErr) {
+		err = nil
+	}
+	return err
+}
+
+// writeBlockingResponse discards the current fiber response and replaces it
+// with the response written by the given blocking handler.
+func writeBlockingResponse(c *fiber.Ctx, h http.Handler) error {
+	c.Response().Reset()
+	return adaptor.HTTPHandler(h)(c)
+}
+
+// canonicalHeaders returns the given headers with their canonical names, as
+// expected by the AppSec HTTP operation.
+func canonicalHeaders(h map[string][]string) map[string][]string {
+	headers := make(map[string][]string, len(h))
+	for k, v := range h {
+		key := http.CanonicalHeaderKey(k)
+		headers[key] = append(headers[key], v...)
+	}
+	return headers
+}
+
+// requestCookies returns the map of parsed cookies if any, following the
+// specification of the rule address `server.request.cookies`.
+func requestCookies(c *fiber.Ctx) map[string][]string {
+	var cookies map[string][]string
+	c.Request().Header.VisitAllCookie(func(k, v []byte) {
+		if cookies == nil {
+			cookies = make(map[string][]string)
+		}
+		cookies[string(k)] = append(cookies[string(k)], string(v))
+	})
+	return cookies
+}
+
+func queryParams(c *fiber.Ctx) map[string][]string {
+	var query map[string][]string
+	c.Context().QueryArgs().VisitAll(func(k, v []byte) {
+		if query == nil {
+			query = make(map[string][]string)
+		}
+		query[string(k)] = append(query[string(k)], string(v))
+	})
+	return query
+}
+
+// parsedBody parses the request body according to its content type. It
+// returns nil when the body is empty or its content type is not supported.
+func parsedBody(c *fiber.Ctx) any {
+	raw := c.Body()
+	if len(raw) == 0 {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	switch mediaType {
+	case fiber.MIMEApplicationJSON:
+		var body any
+		if err := json.Unmarshal(raw, &body); err != nil {
+			return nil
+		}
+		return body
+	case fiber.MIMEApplicationForm:
+		var form map[string][]string
+		c.Request().PostArgs().VisitAll(func(k, v []byte) {
+			if form == nil {
+				form = make(map[string][]string)
+			}
+			form[string(k)] = append(form[string(k)], string(v))
+		})
+		if form == nil {
+			return nil
+		}
+		return form
+	case fiber.MIMEMultipartForm:
+		form, err := c.MultipartForm()
+		if err != nil || len(form.Value) == 0 {
+			return nil
+		}
+		return form.Value
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package fiber
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pappsec "github.com/DataDog/dd-trace-go/v2/appsec"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/mocktracer"
+	"github.com/DataDog/dd-trace-go/v2/instrumentation/testutils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppSec(t *testing.T) {
+	testutils.StartAppSec(t)
+	if !instr.AppSecEnabled() {
+		t.Skip("appsec disabled")
+	}
+
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Post("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello World!\n")
+	})
+	app.Post("/path/:myPathParam", Middleware(), func(c *fiber.Ctx) error {
+		return c.SendString("Hello World!\n")
+	})
+
+	t.Run("request-uri", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		// Send an LFI attack (according to appsec rule id crs-930-110)
+		res, err := app.Test(httptest.NewRequest("POST", "/../../../secret.txt", nil))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+
+		finished := mt.FinishedSpans()
+		require.Len(t, finished, 1)
+		event, ok := finished[0].Tag("_dd.appsec.json").(string)
+		require.True(t, ok)
+		require.Contains(t, event, "crs-930-110")
+		require.Contains(t, event, "server.request.uri.raw")
+	})
+
+	t.Run("path-params", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		// Send a security scanner attack (according to appsec rule id crs-913-120)
+		res, err := app.Test(httptest.NewRequest("POST", "/path/appscan_fingerprint", nil))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, "Hello World!\n", string(b))
+
+		var event string
+		for _, s := range mt.FinishedSpans() {
+			if e, ok := s.Tag("_dd.appsec.json").(string); ok {
+				event = e
+			}
+		}
+		require.Contains(t, event, "crs-913-120")
+		require.Contains(t, event, "myPathParam")
+		require.Contains(t, event, "server.request.path_params")
+	})
+
+	t.Run("body", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		// Send a PHP injection attack (according to appsec rule id crs-933-130)
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"$globals"}`))
+		req.Header.Set("Content-Type", "application/json")
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		finished := mt.FinishedSpans()
+		require.Len(t, finished, 1)
+		event, ok := finished[0].Tag("_dd.appsec.json").(string)
+		require.True(t, ok)
+		require.Contains(t, event, "crs-933-130")
+		require.Contains(t, event, "server.request.body")
+	})
+}
+
+// Test that blocking works by using custom rules/rules data
+func TestBlocking(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "../../../internal/appsec/testdata/blocking.json")
+
+	testutils.StartAppSec(t)
+	if !instr.AppSecEnabled() {
+		t.Skip("appsec disabled")
+	}
+
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Post("/ip", func(c *fiber.Ctx) error {
+		return c.SendString("Hello World!\n")
+	})
+	app.Post("/user", func(c *fiber.Ctx) error {
+		userID := c.Get("user-id")
+		if err := pappsec.SetUser(c.UserContext(), userID); err != nil {
+			return err
+		}
+		return c.SendString("Hello, " + userID)
+	})
+
+	for _, tc := range []struct {
+		name        string
+		endpoint    string
+		headers     map[string]string
+		body        string
+		shouldBlock bool
+	}{
+		{
+			name:        "ip/block",
+			endpoint:    "/ip",
+			headers:     map[string]string{"x-forwarded-for": "1.2.3.4"},
+			shouldBlock: true,
+		},
+		{
+			name:     "ip/no-block",
+			endpoint: "/ip",
+			headers:  map[string]string{"x-forwarded-for": "1.2.3.5"},
+		},
+		{
+			name:        "user/block",
+			endpoint:    "/user",
+			headers:     map[string]string{"user-id": "blocked-user-1"},
+			shouldBlock: true,
+		},
+		{
+			name:     "user/no-block",
+			endpoint: "/user",
+			headers:  map[string]string{"user-id": "legit-user-1"},
+		},
+		{
+			name:        "body/block",
+			endpoint:    "/ip",
+			headers:     map[string]string{"content-type": "application/x-www-form-urlencoded"},
+			body:        "name=$globals",
+			shouldBlock: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			req := httptest.NewRequest("POST", tc.endpoint, strings.NewReader(tc.body))
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			if tc.shouldBlock {
+				require.Equal(t, http.StatusForbidden, res.StatusCode)
+				require.Equal(t, "true", spans[0].Tag("appsec.blocked"))
+			} else {
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				require.NotContains(t, spans[0].Tags(), "appsec.blocked")
+			}
+			require.Equal(t, fmt.Sprintf("%d", res.StatusCode), spans[0].Tag("http.status_code"))
+		})
+	}
+}
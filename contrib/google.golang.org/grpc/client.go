@@ -122,6 +122,9 @@ func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
 			// it's possible there's already a span on the context even though
 			// we're not tracing calls, so inject it if it's there
 			ctx = injectSpanIntoContext(ctx)
+			if cfg.dataStreams {
+				ctx = setProduceCheckpoint(ctx)
+			}
 
 			var err error
 			stream, err = streamer(ctx, desc, cc, method, opts...)
@@ -189,6 +192,9 @@ func doClientRequest(
 	opts = append(opts, grpc.Peer(&p))
 
 	handlerCtx := injectSpanIntoContext(ctx)
+	if cfg.dataStreams {
+		handlerCtx = setProduceCheckpoint(handlerCtx)
+	}
 	err := handler(handlerCtx, opts)
 
 	setSpanTargetFromPeer(span, p)
@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package grpc
+
+import (
+	"context"
+
+	"github.com/DataDog/dd-trace-go/contrib/google.golang.org/grpc/v2/internal/grpcutil"
+	"github.com/DataDog/dd-trace-go/v2/datastreams"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// setProduceCheckpoint sets a Data Streams checkpoint for a call made with ctx, continuing its pathway,
+// and returns ctx with the resulting pathway in its outgoing metadata.
+func setProduceCheckpoint(ctx context.Context) context.Context {
+	outCtx, ok := tracer.SetDataStreamsCheckpoint(ctx, "direction:out", "type:grpc")
+	if !ok {
+		return ctx
+	}
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		// we have to copy the metadata because its not safe to modify
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	datastreams.InjectToBase64Carrier(outCtx, grpcutil.MDCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// setConsumeCheckpoint sets a Data Streams checkpoint for a call received with ctx, continuing the pathway
+// propagated in its incoming metadata, and returns ctx with the resulting pathway.
+func setConsumeCheckpoint(ctx context.Context) context.Context {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = datastreams.ExtractFromBase64Carrier(ctx, grpcutil.MDCarrier(md))
+	}
+	outCtx, _ := tracer.SetDataStreamsCheckpoint(ctx, "direction:in", "type:grpc")
+	return outCtx
+}
@@ -17,7 +17,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/DataDog/dd-trace-go/contrib/google.golang.org/grpc/v2/internal/grpcutil"
 	"github.com/DataDog/dd-trace-go/instrumentation/testutils/grpc/v2/fixturepb"
+	"github.com/DataDog/dd-trace-go/v2/datastreams"
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/mocktracer"
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
@@ -1097,3 +1099,35 @@ func TestIssue2050(t *testing.T) {
 		return
 	}
 }
+
+func TestDataStreams(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	var pathway datastreams.Pathway
+	capture := func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		pathway, _ = datastreams.PathwayFromContext(ctx)
+		return handler(ctx, req)
+	}
+	rig, err := newRigWithInterceptors(
+		[]grpc.ServerOption{grpc.ChainUnaryInterceptor(UnaryServerInterceptor(WithDataStreams()), capture)},
+		[]grpc.DialOption{grpc.WithInsecure(), grpc.WithUnaryInterceptor(UnaryClientInterceptor(WithDataStreams()))},
+	)
+	require.NoError(t, err)
+	defer rig.Close()
+
+	ctx, _ := tracer.SetDataStreamsCheckpoint(context.Background(), "direction:in", "type:kafka", "topic:orders")
+	_, err = rig.client.Ping(ctx, &fixturepb.FixtureRequest{Name: "pass"})
+	require.NoError(t, err)
+
+	// The pathway continues from the context of the call, with an edge out of the client and in the server.
+	md := rig.fixtureServer.LastRequestMetadata.Load().(metadata.MD)
+	assert.Len(t, md.Get("dd-pathway-ctx-base64"), 1)
+	outCtx, _ := tracer.SetDataStreamsCheckpoint(ctx, "direction:out", "type:grpc")
+	carrier := grpcutil.MDCarrier(metadata.MD{})
+	datastreams.InjectToBase64Carrier(outCtx, carrier)
+	inCtx, _ := tracer.SetDataStreamsCheckpoint(datastreams.ExtractFromBase64Carrier(context.Background(), carrier), "direction:in", "type:grpc")
+	in, _ := datastreams.PathwayFromContext(inCtx)
+	require.NotNil(t, pathway)
+	assert.Equal(t, in.GetHash(), pathway.GetHash())
+}
@@ -64,6 +64,7 @@ type config struct {
 	ignoredMetadata     map[string]struct{}
 	withRequestTags     bool
 	withErrorDetailTags bool
+	dataStreams         bool
 	spanOpts            []tracer.StartSpanOption
 	tags                map[string]interface{}
 }
@@ -79,6 +80,7 @@ func defaults(cfg *config) {
 		"x-datadog-trace-id":          {},
 		"x-datadog-parent-id":         {},
 		"x-datadog-sampling-priority": {},
+		"dd-pathway-ctx-base64":       {},
 	}
 }
 
@@ -204,6 +206,16 @@ func WithErrorDetailTags() OptionFn {
 	}
 }
 
+// WithDataStreams enables the Data Streams Monitoring of the calls. The client interceptors propagate
+// the pathway of the context of the calls in their metadata, and the server interceptors continue it in
+// the context of the handlers, so that a synchronous call between two asynchronous stages doesn't break
+// the pathway.
+func WithDataStreams() OptionFn {
+	return func(cfg *config) {
+		cfg.dataStreams = true
+	}
+}
+
 // WithCustomTag will attach the value to the span tagged by the key.
 func WithCustomTag(key string, value interface{}) OptionFn {
 	return func(cfg *config) {
@@ -84,6 +84,9 @@ func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
 	instr.Logger().Debug("contrib/google.golang.org/grpc: Configuring StreamServerInterceptor: %#v", cfg)
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
 		ctx := ss.Context()
+		if cfg.dataStreams {
+			ctx = setConsumeCheckpoint(ctx)
+		}
 		// if we've enabled call tracing, create a span
 		_, um := cfg.untracedMethods[info.FullMethod]
 		if cfg.traceStreamCalls && !um {
@@ -145,6 +148,9 @@ func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
 				tracer.Tag(ext.SpanKind, ext.SpanKindServer))...,
 		)
 		span.SetTag(tagMethodKind, methodKindUnary)
+		if cfg.dataStreams {
+			ctx = setConsumeCheckpoint(ctx)
+		}
 		withMetadataTags(ctx, cfg, span)
 		withRequestTags(cfg, req, span)
 		if instr.AppSecEnabled() {
@@ -45,6 +45,9 @@ type CommonConfig struct {
 	ServiceName   string
 	ResourceNamer func(*http.Request) string
 	SpanOpts      []tracer.StartSpanOption
+	DataStreams   bool
+	// DataStreamsTransactionID extracts the transaction ID of the requests, if set.
+	DataStreamsTransactionID func(*http.Request) string
 }
 
 type Config struct {
@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package wrap
+
+import (
+	"net/http"
+
+	"github.com/DataDog/dd-trace-go/v2/datastreams"
+	"github.com/DataDog/dd-trace-go/v2/datastreams/options"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
+)
+
+// setProduceCheckpoint sets a Data Streams checkpoint for a request sent, continuing the pathway of its
+// context, and propagates the resulting pathway in its headers.
+func setProduceCheckpoint(req *http.Request, transactionID func(*http.Request) string) {
+	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(req.Context(), checkpointParams(req, transactionID), "direction:out", "type:http")
+	if !ok {
+		return
+	}
+	datastreams.InjectToBase64Carrier(ctx, tracer.HTTPHeadersCarrier(req.Header))
+}
+
+// setConsumeCheckpoint sets a Data Streams checkpoint for a request received, continuing the pathway
+// propagated in its headers, and returns the request with the resulting pathway in its context.
+func setConsumeCheckpoint(req *http.Request, transactionID func(*http.Request) string) *http.Request {
+	ctx := datastreams.ExtractFromBase64Carrier(req.Context(), tracer.HTTPHeadersCarrier(req.Header))
+	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(ctx, checkpointParams(req, transactionID), "direction:in", "type:http")
+	if !ok {
+		return req
+	}
+	return req.WithContext(ctx)
+}
+
+// checkpointParams returns the parameters of the checkpoint of a request, with its transaction ID if
+// transactionID is set.
+func checkpointParams(req *http.Request, transactionID func(*http.Request) string) options.CheckpointParams {
+	if transactionID == nil {
+		return options.CheckpointParams{}
+	}
+	return options.CheckpointParams{TransactionID: transactionID(req)}
+}
@@ -45,6 +45,9 @@ func (mux *ServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		mux.ServeMux.ServeHTTP(w, r)
 		return
 	}
+	if mux.cfg.DataStreams {
+		r = setConsumeCheckpoint(r, mux.cfg.DataStreamsTransactionID)
+	}
 	// get the resource associated to this request
 	_, pttrn := mux.Handler(r)
 	route := pattern.Route(pttrn)
@@ -84,6 +84,9 @@ func ObserveRoundTrip(cfg *config.RoundTripperConfig, req *http.Request) (*http.
 			fmt.Fprintf(os.Stderr, "contrib/net/http.Roundtrip: failed to inject http headers: %v\n", err)
 		}
 	}
+	if cfg.DataStreams {
+		setProduceCheckpoint(req, cfg.DataStreamsTransactionID)
+	}
 
 	// if RASP is enabled, check whether the request is supposed to be blocked.
 	if config.Instrumentation.AppSecRASPEnabled() {
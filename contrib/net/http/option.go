@@ -93,6 +93,26 @@ func WithSpanOptions(opts ...tracer.StartSpanOption) OptionFn {
 	}
 }
 
+// WithDataStreams enables the Data Streams Monitoring of the requests. The client propagates the
+// pathway of the context of the requests in their headers, and the server continues it in the
+// context of the requests, so that a synchronous call between two asynchronous stages doesn't
+// break the pathway.
+func WithDataStreams() OptionFn {
+	return func(cfg *internal.CommonConfig) {
+		cfg.DataStreams = true
+	}
+}
+
+// WithDataStreamsTransactionID enables the Data Streams Monitoring of the requests, like WithDataStreams,
+// and sets the function extracting their transaction ID, e.g. an order ID, to follow them across the
+// pathways. Requests without transaction ID return an empty string.
+func WithDataStreamsTransactionID(fn func(*http.Request) string) OptionFn {
+	return func(cfg *internal.CommonConfig) {
+		cfg.DataStreams = true
+		cfg.DataStreamsTransactionID = fn
+	}
+}
+
 // WithResourceNamer populates the name of a resource based on a custom function.
 func WithResourceNamer(namer func(req *http.Request) string) OptionFn {
 	return internal.WithResourceNamer(namer)
@@ -9,6 +9,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -16,6 +17,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -24,6 +26,7 @@ import (
 
 	internal "github.com/DataDog/dd-trace-go/contrib/net/http/v2/internal/config"
 	"github.com/DataDog/dd-trace-go/v2/appsec/events"
+	"github.com/DataDog/dd-trace-go/v2/datastreams"
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/baggage"
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/mocktracer"
@@ -806,3 +809,151 @@ func TestRoundTripperWithBaggage(t *testing.T) {
 
 	assert.NotEmpty(t, capturedHeaders.Get("baggage"), "should have baggage header")
 }
+
+func TestRoundTripperDataStreams(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	var (
+		header  string
+		pathway datastreams.Pathway
+	)
+	handler := WrapHandler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("dd-pathway-ctx-base64")
+		pathway, _ = datastreams.PathwayFromContext(r.Context())
+	}), "service", "resource", WithDataStreams())
+	s := httptest.NewServer(handler)
+	defer s.Close()
+
+	ctx, _ := tracer.SetDataStreamsCheckpoint(context.Background(), "direction:in", "type:kafka", "topic:orders")
+	client := &http.Client{Transport: WrapRoundTripper(http.DefaultTransport, WithDataStreams())}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// The pathway continues from the context of the request, with an edge out of the client and in the server.
+	assert.NotEmpty(t, header)
+	outCtx, _ := tracer.SetDataStreamsCheckpoint(ctx, "direction:out", "type:http")
+	carrier := tracer.HTTPHeadersCarrier(http.Header{})
+	datastreams.InjectToBase64Carrier(outCtx, carrier)
+	inCtx, _ := tracer.SetDataStreamsCheckpoint(datastreams.ExtractFromBase64Carrier(context.Background(), carrier), "direction:in", "type:http")
+	in, _ := datastreams.PathwayFromContext(inCtx)
+	require.NotNil(t, pathway)
+	assert.Equal(t, in.GetHash(), pathway.GetHash())
+
+	// Without the option, the pathway isn't propagated.
+	header = ""
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	require.NoError(t, err)
+	resp, err = (&http.Client{Transport: WrapRoundTripper(http.DefaultTransport)}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, header)
+}
+
+func TestRoundTripperDataStreamsFanIn(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	// The backends the gateway fans out to, and the sink it sends the merged result to.
+	backend := httptest.NewServer(WrapHandler(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}), "backend", "resource", WithDataStreams()))
+	defer backend.Close()
+	var sinkPathway datastreams.Pathway
+	sink := httptest.NewServer(WrapHandler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		sinkPathway, _ = datastreams.PathwayFromContext(r.Context())
+	}), "sink", "resource", WithDataStreams()))
+	defer sink.Close()
+
+	// The gateway calls the backends concurrently, each call continuing the pathway of its own branch,
+	// then merges the branches and sends the result downstream.
+	client := &http.Client{Transport: WrapRoundTripper(http.DefaultTransport, WithDataStreams())}
+	var merged context.Context
+	gateway := httptest.NewServer(WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		branches := []string{"topic:a", "topic:b", "topic:c"}
+		ctxs := make([]context.Context, len(branches))
+		var wg sync.WaitGroup
+		for i, branch := range branches {
+			ctxs[i], _ = tracer.SetDataStreamsCheckpoint(r.Context(), "direction:in", "type:kafka", branch)
+			wg.Add(1)
+			go func(ctx context.Context) {
+				defer wg.Done()
+				req, _ := http.NewRequestWithContext(ctx, http.MethodGet, backend.URL, nil)
+				if resp, err := client.Do(req); err == nil {
+					resp.Body.Close()
+				}
+			}(ctxs[i])
+		}
+		wg.Wait()
+
+		merged = datastreams.MergeContexts(ctxs...)
+		req, err := http.NewRequestWithContext(merged, http.MethodGet, sink.URL, nil)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		resp.Body.Close()
+	}), "gateway", "resource", WithDataStreams()))
+	defer gateway.Close()
+
+	resp, err := http.Get(gateway.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The edge out of the gateway to the sink has the merged pathway as parent.
+	require.NotNil(t, merged)
+	_, ok := datastreams.PathwayFromContext(merged)
+	require.True(t, ok)
+	outCtx, _ := tracer.SetDataStreamsCheckpoint(merged, "direction:out", "type:http")
+	carrier := tracer.HTTPHeadersCarrier(http.Header{})
+	datastreams.InjectToBase64Carrier(outCtx, carrier)
+	inCtx, _ := tracer.SetDataStreamsCheckpoint(datastreams.ExtractFromBase64Carrier(context.Background(), carrier), "direction:in", "type:http")
+	expected, _ := datastreams.PathwayFromContext(inCtx)
+	assert.Equal(t, expected.GetHash(), sinkPathway.GetHash())
+}
+
+func TestRoundTripperNewTracer(t *testing.T) {
+	// No global tracer is started: the headers are propagated by the tracer created with New, bound to the
+	// contexts of the client and of the server.
+	agent := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
+	defer agent.Close()
+	tr, err := tracer.New(tracer.WithAgentURL(agent.URL), tracer.WithService("tenant"))
+	require.NoError(t, err)
+	defer tr.Stop()
+
+	var (
+		header  http.Header
+		spanctx *tracer.SpanContext
+	)
+	mux := NewServeMux()
+	mux.HandleFunc("/", func(_ http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		if span, ok := tracer.SpanFromContext(r.Context()); ok {
+			spanctx = span.Context()
+		}
+	})
+	srv := httptest.NewUnstartedServer(mux)
+	srv.Config.BaseContext = func(net.Listener) context.Context {
+		return tracer.ContextWithTracer(context.Background(), tr)
+	}
+	srv.Start()
+	defer srv.Close()
+
+	parent, ctx := tracer.StartSpanFromContext(tracer.ContextWithTracer(context.Background(), tr), "parent")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	resp, err := WrapClient(&http.Client{}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	parent.Finish()
+
+	assert.Equal(t, strconv.FormatUint(parent.Context().TraceIDLower(), 10), header.Get(tracer.DefaultTraceIDHeader))
+	require.NotNil(t, spanctx)
+	assert.Equal(t, parent.Context().TraceID(), spanctx.TraceID())
+}
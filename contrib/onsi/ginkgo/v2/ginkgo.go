@@ -0,0 +1,449 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+// Package ginkgo provides the CI Visibility integration for test suites written with
+// Ginkgo v2 (https://github.com/onsi/ginkgo).
+//
+// Every spec is reported as a test of the test suite named after the Ginkgo suite
+// description, within the test module of the Go package running the suite. The name of
+// the test is the full text of the spec, i.e. the texts of its Describe/Context containers
+// followed by the text of the It node. To enable the integration, replace the call to
+// ginkgo.RunSpecs with RunSpecs:
+//
+//	func TestBooks(t *testing.T) {
+//		gomega.RegisterFailHandler(ginkgo.Fail)
+//		ddginkgo.RunSpecs(t, "Books Suite")
+//	}
+//
+// The additional features of CI Visibility are applied to the specs with the following
+// caveats, as Ginkgo decides how many times a spec runs when it builds the spec tree:
+//   - Automatic flaky test retries use the Ginkgo flake attempts mechanism, unless the
+//     suite is already configured with --flake-attempts or --must-pass-repeatedly.
+//     Every attempt is reported as a test execution.
+//   - Early flake detection runs the new specs with the Ginkgo must pass repeatedly mechanism,
+//     as many times as configured for the tests faster than 5 seconds since the duration of
+//     the specs isn't known yet. Only the specs declared within containers are retried, and
+//     neither the ones decorated with FlakeAttempts or MustPassRepeatedly nor the suites
+//     configured with --must-pass-repeatedly are. A failed execution stops the retries.
+//   - Disabled specs are skipped. Quarantined specs run, but their failures don't fail
+//     the suite. Attempts to fix run once.
+package ginkgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/dd-trace-go/v2/instrumentation/civisibility"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// frameworkName is the name of the test framework reported to CI Visibility.
+const frameworkName = "ginkgo"
+
+// RunSpecs runs the Ginkgo suite like ginkgo.RunSpecs does, reporting its specs to CI Visibility.
+// It must be called instead of ginkgo.RunSpecs, with the same arguments.
+func RunSpecs(t ginkgo.GinkgoTestingT, description string, args ...any) bool {
+	// CI Visibility must be enabled explicitly, the same way it is for the go testing integration.
+	if !civisibility.Enabled() {
+		return ginkgo.RunSpecs(t, description, args...)
+	}
+
+	pc, _, _, _ := runtime.Caller(1)
+	r := newSuiteReporter(pc, description)
+	args = r.configure(args)
+	unregister := r.register()
+
+	// Ginkgo fails t if any spec failed, which is not what we want when the failures come from
+	// quarantined specs, so the failure is only forwarded once we know where it comes from.
+	passed := ginkgo.RunSpecs(discardFailure{}, description, args...)
+	unregister()
+	passed = passed || r.onlyIgnoredFailures()
+	r.close(passed)
+	if !passed {
+		t.Fail()
+	}
+	return passed
+}
+
+// discardFailure is a ginkgo.GinkgoTestingT ignoring failures.
+type discardFailure struct{}
+
+// Fail does nothing, the result of the suite is returned by ginkgo.RunSpecs.
+func (discardFailure) Fail() {}
+
+// specInfo holds the CI Visibility data of a spec.
+type specInfo struct {
+	isNew          bool
+	isQuarantined  bool
+	isDisabled     bool
+	isAttemptToFix bool
+}
+
+// ignoreFailures returns whether the failures of the spec must not fail the suite.
+func (i specInfo) ignoreFailures() bool {
+	return i.isQuarantined || i.isDisabled
+}
+
+// suiteReporter reports the specs of a Ginkgo suite to CI Visibility.
+type suiteReporter struct {
+	moduleName string
+	suiteName  string
+
+	ownSession bool
+	session    civisibility.TestSession
+	module     civisibility.TestModule
+	suite      civisibility.TestSuite
+
+	// autoRetries indicates that the flake attempts were configured by the integration.
+	autoRetries bool
+	// efdExecutions is the number of times the new specs must run, 0 if they aren't retried.
+	efdExecutions int
+	// focused indicates that the suite runs a subset of its specs selected by focus or filters.
+	focused atomic.Bool
+
+	mu sync.Mutex
+	// current is the test of the spec attempt currently running.
+	current civisibility.Test
+	// executed holds the keys of the specs which ran at least once.
+	executed map[string]struct{}
+	// infos caches the CI Visibility data of the specs by name.
+	infos map[string]specInfo
+	// failures is the number of failed specs whose failure must fail the suite.
+	failures int
+	// ignoredFailures is the number of failed quarantined or disabled specs.
+	ignoredFailures int
+
+	closeOnce sync.Once
+}
+
+// newSuiteReporter creates the reporter of a Ginkgo suite run by the function at pc, creating the
+// test session if it wasn't already created by the go testing integration.
+func newSuiteReporter(pc uintptr, description string) *suiteReporter {
+	civisibility.EnsureCiVisibilityInitialization()
+
+	r := &suiteReporter{
+		suiteName: description,
+		executed:  map[string]struct{}{},
+		infos:     map[string]specInfo{},
+	}
+	r.moduleName, _ = civisibility.GetModuleAndSuiteName(pc)
+
+	session, ok := civisibility.GetActiveTestSession()
+	if !ok {
+		session = civisibility.CreateTestSession(civisibility.WithTestSessionFramework(frameworkName, types.VERSION))
+		r.ownSession = true
+		if civisibility.TestManagementEnabled() {
+			session.SetTag(civisibility.TestManagementEnabledTag, "true")
+		}
+	}
+	r.session = session
+	r.module = session.GetOrCreateModule(r.moduleName, civisibility.WithTestModuleFramework(frameworkName, types.VERSION))
+	r.suite = r.module.GetOrCreateSuite(r.suiteName)
+	return r
+}
+
+// configure returns the RunSpecs arguments, adjusting the suite configuration to retry the
+// failed specs when automatic flaky test retries are enabled. It also sets the number of
+// executions of the new specs when early flake detection is enabled.
+func (r *suiteReporter) configure(args []any) []any {
+	suiteConfig, _ := ginkgo.GinkgoConfiguration()
+	configIdx := -1
+	for i, arg := range args {
+		if c, ok := arg.(types.SuiteConfig); ok {
+			suiteConfig, configIdx = c, i
+		}
+	}
+	if len(suiteConfig.FocusStrings) > 0 || len(suiteConfig.FocusFiles) > 0 || suiteConfig.LabelFilter != "" {
+		r.focused.Store(true)
+	}
+
+	settings := civisibility.GetSettings()
+	if settings != nil && settings.EarlyFlakeDetection.Enabled && suiteConfig.MustPassRepeatedly == 0 {
+		if retries := settings.EarlyFlakeDetection.SlowTestRetries.FiveS; retries > 0 {
+			r.efdExecutions = retries + 1
+		}
+	}
+	if settings == nil || !settings.FlakyTestRetriesEnabled {
+		return args
+	}
+	retries := civisibility.GetFlakyRetriesSettings()
+	if retries == nil || retries.RetryCount <= 0 || suiteConfig.FlakeAttempts > 0 || suiteConfig.MustPassRepeatedly > 0 {
+		return args
+	}
+	suiteConfig.FlakeAttempts = int(retries.RetryCount) + 1
+	r.autoRetries = true
+
+	args = slices.Clone(args)
+	if configIdx >= 0 {
+		args[configIdx] = suiteConfig
+	} else {
+		args = append(args, suiteConfig)
+	}
+	return args
+}
+
+// register registers the Ginkgo nodes reporting the specs. They are top level nodes, so
+// they run around every attempt of every spec of the suite. It also registers the transformer
+// of the specs declared when Ginkgo builds the spec tree, and returns the function removing it.
+func (r *suiteReporter) register() func() {
+	ginkgo.ReportBeforeSuite(func(report types.Report) {
+		if report.SuiteHasProgrammaticFocus {
+			r.focused.Store(true)
+		}
+	})
+	ginkgo.BeforeEach(r.beforeEach)
+	ginkgo.AfterEach(r.afterEach)
+	ginkgo.ReportAfterEach(r.reportAfterEach)
+	// Ginkgo exits the process right after running a suite with programmatically focused specs,
+	// so the events are closed from the suite report as well.
+	ginkgo.ReportAfterSuite("Datadog CI Visibility", func(report types.Report) {
+		r.close(report.SuiteSucceeded || r.onlyIgnoredFailures())
+	})
+	return ginkgo.AddTreeConstructionNodeArgsTransformer(r.transformNodeArgs)
+}
+
+// transformNodeArgs decorates the new specs with MustPassRepeatedly, so that Ginkgo runs them
+// as many times as early flake detection requires. The specs keep their own retry decorators.
+func (r *suiteReporter) transformNodeArgs(nodeType types.NodeType, _ ginkgo.Offset, text string, args []any) (string, []any, []error) {
+	if r.efdExecutions == 0 || !nodeType.Is(types.NodeTypeIt) {
+		return text, args, nil
+	}
+	for _, arg := range args {
+		switch arg.(type) {
+		case ginkgo.FlakeAttempts, ginkgo.MustPassRepeatedly:
+			return text, args, nil
+		}
+	}
+
+	// The name of the spec is built the same way types.SpecReport.FullText builds it.
+	texts := append(slices.Clone(ginkgo.CurrentTreeConstructionNodeReport().ContainerHierarchyTexts), text)
+	texts = slices.DeleteFunc(texts, func(t string) bool { return t == "" })
+	if !r.specInfo(strings.Join(texts, " ")).isNew {
+		return text, args, nil
+	}
+	return text, append(args, ginkgo.MustPassRepeatedly(r.efdExecutions)), nil
+}
+
+// beforeEach starts the test of a spec attempt, and skips the spec if it's disabled.
+func (r *suiteReporter) beforeEach() {
+	report := ginkgo.CurrentSpecReport()
+	info := r.specInfo(report.FullText())
+	test := r.startTest(report, time.Now(), info)
+
+	if report.NumAttempts > 1 {
+		test.SetTag(civisibility.TestIsRetry, "true")
+		if info.isNew && r.efdExecutions > 0 && report.MaxMustPassRepeatedly == r.efdExecutions {
+			test.SetTag(civisibility.TestRetryReason, civisibility.EarlyFlakeDetectionRetryReason)
+		} else if r.autoRetries {
+			test.SetTag(civisibility.TestRetryReason, civisibility.AutoTestRetriesRetryReason)
+			atomic.AddInt64(&civisibility.GetFlakyRetriesSettings().RemainingTotalRetryCount, -1)
+		} else {
+			test.SetTag(civisibility.TestRetryReason, civisibility.ExternalRetryReason)
+		}
+	}
+
+	r.mu.Lock()
+	r.current = test
+	r.executed[specKey(report)] = struct{}{}
+	r.mu.Unlock()
+
+	if info.isDisabled && !info.isAttemptToFix {
+		ginkgo.Skip(civisibility.TestDisabledSkipReason)
+	}
+}
+
+// afterEach closes the test of a spec attempt with the state of the attempt.
+func (r *suiteReporter) afterEach() {
+	report := ginkgo.CurrentSpecReport()
+
+	r.mu.Lock()
+	test := r.current
+	r.current = nil
+	r.mu.Unlock()
+	if test == nil {
+		return
+	}
+
+	switch {
+	case report.State.Is(types.SpecStateFailureStates):
+		setFailure(test, report)
+		// Ginkgo only retries failed specs, so a failure in the last attempt means that all of them failed.
+		if report.NumAttempts > 1 && report.NumAttempts == report.MaxFlakeAttempts {
+			test.SetTag(civisibility.TestHasFailedAllRetries, "true")
+		}
+		test.Close(civisibility.ResultStatusFail)
+	case report.State.Is(types.SpecStateSkipped):
+		test.Close(civisibility.ResultStatusSkip, civisibility.WithTestSkipReason(report.Failure.Message))
+	default:
+		if r.specInfo(report.FullText()).isAttemptToFix {
+			test.SetTag(civisibility.TestAttemptToFixPassed, "true")
+		}
+		test.Close(civisibility.ResultStatusPass)
+	}
+}
+
+// reportAfterEach counts the failed specs, and reports the specs which didn't run: the pending
+// specs and the ones skipped by Ginkgo before running any node.
+func (r *suiteReporter) reportAfterEach(report types.SpecReport) {
+	info := r.specInfo(report.FullText())
+
+	r.mu.Lock()
+	_, executed := r.executed[specKey(report)]
+	if report.State.Is(types.SpecStateFailureStates) {
+		if info.ignoreFailures() {
+			r.ignoredFailures++
+		} else {
+			r.failures++
+		}
+	}
+	r.mu.Unlock()
+	if executed {
+		return
+	}
+
+	startTime := report.StartTime
+	if startTime.IsZero() {
+		startTime = time.Now()
+	}
+	test := r.startTest(report, startTime, info)
+	switch {
+	case report.State.Is(types.SpecStateFailureStates):
+		setFailure(test, report)
+		test.Close(civisibility.ResultStatusFail)
+	case report.State.Is(types.SpecStatePending):
+		test.Close(civisibility.ResultStatusSkip, civisibility.WithTestSkipReason(civisibility.GinkgoPendingSkipReason))
+	case report.Failure.Message != "":
+		test.Close(civisibility.ResultStatusSkip, civisibility.WithTestSkipReason(report.Failure.Message))
+	case r.focused.Load():
+		test.Close(civisibility.ResultStatusSkip, civisibility.WithTestSkipReason(civisibility.GinkgoNotFocusedSkipReason))
+	default:
+		test.Close(civisibility.ResultStatusSkip)
+	}
+}
+
+// startTest creates the test of a spec execution and sets the spec tags.
+func (r *suiteReporter) startTest(report types.SpecReport, startTime time.Time, info specInfo) civisibility.Test {
+	test := r.suite.CreateTest(report.FullText(), civisibility.WithTestStartTime(startTime))
+
+	if location := report.LeafNodeLocation; location.FileName != "" {
+		file := civisibility.GetRelativePathFromCITagsSourceRoot(location.FileName)
+		test.SetTag(civisibility.TestSourceFile, file)
+		test.SetTag(civisibility.TestSourceStartLine, location.LineNumber)
+		if codeOwners := civisibility.GetCodeOwners(); codeOwners != nil {
+			if match, found := codeOwners.Match("/" + file); found {
+				test.SetTag(civisibility.TestCodeOwners, match.GetOwnersString())
+			}
+		}
+	}
+	if labels := report.Labels(); len(labels) > 0 {
+		test.SetTag(civisibility.GinkgoLabels, jsonArray(labels))
+	}
+	if len(report.ContainerHierarchyTexts) > 0 {
+		test.SetTag(civisibility.GinkgoContainers, jsonArray(report.ContainerHierarchyTexts))
+	}
+	if r.focused.Load() && !report.State.Is(types.SpecStateSkipped|types.SpecStatePending) {
+		test.SetTag(civisibility.GinkgoFocused, "true")
+	}
+
+	if info.isNew {
+		test.SetTag(civisibility.TestIsNew, "true")
+	}
+	if info.isQuarantined {
+		test.SetTag(civisibility.TestIsQuarantined, "true")
+	}
+	if info.isDisabled {
+		test.SetTag(civisibility.TestIsDisabled, "true")
+	}
+	if info.isAttemptToFix {
+		test.SetTag(civisibility.TestIsAttempToFix, "true")
+	}
+	return test
+}
+
+// specInfo returns the CI Visibility data of the spec with the given name.
+func (r *suiteReporter) specInfo(name string) specInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if info, ok := r.infos[name]; ok {
+		return info
+	}
+
+	var info specInfo
+	settings := civisibility.GetSettings()
+	if settings != nil && settings.EarlyFlakeDetection.Enabled {
+		if knownTests := civisibility.GetKnownTests(); knownTests != nil && len(knownTests.Tests) > 0 {
+			info.isNew = !slices.Contains(knownTests.Tests[r.moduleName][r.suiteName], name)
+		}
+	}
+	if civisibility.TestManagementEnabled() {
+		if data := civisibility.GetTestManagementTestsData(); data != nil {
+			if test, ok := data.Modules[r.moduleName].Suites[r.suiteName].Tests[name]; ok {
+				info.isQuarantined = test.Properties.Quarantined
+				info.isDisabled = test.Properties.Disabled
+				info.isAttemptToFix = test.Properties.AttemptToFix
+			}
+		}
+	}
+	r.infos[name] = info
+	return info
+}
+
+// onlyIgnoredFailures returns whether the only failed specs are quarantined or disabled ones,
+// in which case the suite passes. It doesn't when it failed outside of any spec, e.g. in BeforeSuite.
+func (r *suiteReporter) onlyIgnoredFailures() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failures == 0 && r.ignoredFailures > 0
+}
+
+// close closes the test suite, and the test session if it was created by the integration.
+func (r *suiteReporter) close(passed bool) {
+	r.closeOnce.Do(func() {
+		r.suite.Close()
+		if !r.ownSession {
+			// The go testing integration closes the module and the session.
+			return
+		}
+		exitCode := 0
+		if !passed {
+			exitCode = 1
+		}
+		r.module.Close()
+		r.session.Close(exitCode)
+		civisibility.ExitCiVisibility()
+	})
+}
+
+// setFailure sets the error information of a failed spec on its test.
+func setFailure(test civisibility.Test, report types.SpecReport) {
+	failure := report.Failure
+	if failure.ForwardedPanic != "" {
+		test.SetError(civisibility.WithErrorInfo("panic", failure.ForwardedPanic, failure.Location.FullStackTrace))
+		return
+	}
+	test.SetError(civisibility.WithErrorInfo(report.State.String(), failure.Message, failure.Location.String()))
+}
+
+// specKey identifies a spec of the suite. The full text isn't enough, since specs can share it.
+func specKey(report types.SpecReport) string {
+	return fmt.Sprintf("%s:%d %s", report.LeafNodeLocation.FileName, report.LeafNodeLocation.LineNumber, report.FullText())
+}
+
+// jsonArray returns values as a JSON array, the format used by the CI Visibility array tags.
+func jsonArray(values []string) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
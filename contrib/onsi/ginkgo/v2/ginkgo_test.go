@@ -0,0 +1,254 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package ginkgo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/mocktracer"
+	"github.com/DataDog/dd-trace-go/v2/instrumentation/civisibility"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+const suiteDescription = "Ginkgo Integration Suite"
+
+var (
+	mTracer mocktracer.Tracer
+
+	// moduleName is the test module of the specs of this package.
+	moduleName = func() string {
+		pc, _, _, _ := runtime.Caller(0)
+		name, _ := civisibility.GetModuleAndSuiteName(pc)
+		return name
+	}()
+
+	flakyAttempts int
+	newExecutions int
+)
+
+var _ = ginkgo.Describe("Calculator", ginkgo.Label("math"), func() {
+	ginkgo.It("adds", func() {
+		gomega.Expect(1 + 1).To(gomega.Equal(2))
+	})
+
+	ginkgo.It("is new", func() {
+		newExecutions++
+		gomega.Expect(2 * 2).To(gomega.Equal(4))
+	})
+
+	ginkgo.Context("when flaky", ginkgo.Label("flaky"), func() {
+		ginkgo.It("passes on retry", func() {
+			flakyAttempts++
+			gomega.Expect(flakyAttempts).To(gomega.BeNumerically(">", 1))
+		})
+	})
+
+	ginkgo.It("is quarantined", func() {
+		gomega.Expect(1).To(gomega.Equal(2))
+	})
+
+	ginkgo.It("is disabled", func() {
+		gomega.Expect(1).To(gomega.Equal(2))
+	})
+
+	ginkgo.PIt("is pending", func() {})
+
+	ginkgo.It("is skipped", func() {
+		ginkgo.Skip("not today")
+	})
+
+	ginkgo.It("panics", ginkgo.Label("quarantined"), func() {
+		panic("boom")
+	})
+})
+
+func TestMain(m *testing.M) {
+	server := setUpHTTPServer()
+	os.Setenv("DD_CIVISIBILITY_ENABLED", "true")
+	os.Setenv("DD_CIVISIBILITY_FLAKY_RETRY_COUNT", "2")
+	os.Setenv("DD_GIT_REPOSITORY_URL", "https://github.com/DataDog/dd-trace-go.git")
+	mTracer = civisibility.InitializeCIVisibilityMock()
+
+	exitCode := m.Run()
+	server.Close()
+	os.Exit(exitCode)
+}
+
+func TestRunSpecs(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	if !RunSpecs(t, suiteDescription) {
+		t.Fatal("expected the failures of the quarantined specs to be ignored")
+	}
+
+	spans := mTracer.FinishedSpans()
+	tests := map[string][]*mocktracer.Span{}
+	sessions, modules, suites := 0, 0, 0
+	for _, span := range spans {
+		switch span.Tag(ext.SpanType) {
+		case civisibility.SpanTypeTestSession:
+			sessions++
+			if v := span.Tag(civisibility.TestStatus); v != civisibility.TestStatusPass {
+				t.Errorf("expected the session to pass, got %v", v)
+			}
+		case civisibility.SpanTypeTestModule:
+			modules++
+		case civisibility.SpanTypeTestSuite:
+			suites++
+			if v := span.Tag(civisibility.TestSuiteTag); v != suiteDescription {
+				t.Errorf("unexpected suite name %v", v)
+			}
+		case civisibility.SpanTypeTest:
+			name := span.Tag(civisibility.TestName).(string)
+			tests[name] = append(tests[name], span)
+		}
+	}
+	if sessions != 1 || modules != 1 || suites != 1 {
+		t.Fatalf("expected 1 session, module and suite, got %d, %d and %d", sessions, modules, suites)
+	}
+
+	check := func(name string, statuses ...string) []*mocktracer.Span {
+		t.Helper()
+		executions := tests[name]
+		if len(executions) != len(statuses) {
+			t.Fatalf("expected %d executions of %q, got %d", len(statuses), name, len(executions))
+		}
+		for i, status := range statuses {
+			if v := executions[i].Tag(civisibility.TestStatus); v != status {
+				t.Errorf("expected execution %d of %q to be %s, got %v", i, name, status, v)
+			}
+			if v := executions[i].Tag(civisibility.TestModuleTag); v != moduleName {
+				t.Errorf("unexpected module name %v", v)
+			}
+		}
+		return executions
+	}
+
+	adds := check("Calculator adds", civisibility.TestStatusPass)
+	assertTag(t, adds[0], civisibility.GinkgoLabels, `["math"]`)
+	assertTag(t, adds[0], civisibility.GinkgoContainers, `["Calculator"]`)
+	assertTag(t, adds[0], civisibility.TestIsNew, nil)
+	assertTag(t, adds[0], civisibility.TestSourceFile, "contrib/onsi/ginkgo/v2/ginkgo_test.go")
+
+	isNew := check("Calculator is new", civisibility.TestStatusPass, civisibility.TestStatusPass, civisibility.TestStatusPass)
+	if newExecutions != 3 {
+		t.Errorf("expected the new spec to run 3 times, got %d", newExecutions)
+	}
+	for _, span := range isNew {
+		assertTag(t, span, civisibility.TestIsNew, "true")
+	}
+	assertTag(t, isNew[0], civisibility.TestIsRetry, nil)
+	assertTag(t, isNew[1], civisibility.TestIsRetry, "true")
+	assertTag(t, isNew[2], civisibility.TestRetryReason, civisibility.EarlyFlakeDetectionRetryReason)
+
+	flaky := check("Calculator when flaky passes on retry", civisibility.TestStatusFail, civisibility.TestStatusPass)
+	assertTag(t, flaky[0], civisibility.GinkgoLabels, `["math","flaky"]`)
+	assertTag(t, flaky[0], civisibility.TestIsRetry, nil)
+	assertTag(t, flaky[1], civisibility.TestIsRetry, "true")
+	assertTag(t, flaky[1], civisibility.TestRetryReason, civisibility.AutoTestRetriesRetryReason)
+
+	quarantined := check("Calculator is quarantined", civisibility.TestStatusFail, civisibility.TestStatusFail, civisibility.TestStatusFail)
+	for _, span := range quarantined {
+		assertTag(t, span, civisibility.TestIsQuarantined, "true")
+	}
+	assertTag(t, quarantined[1], civisibility.TestHasFailedAllRetries, nil)
+	assertTag(t, quarantined[2], civisibility.TestHasFailedAllRetries, "true")
+
+	disabled := check("Calculator is disabled", civisibility.TestStatusSkip)
+	assertTag(t, disabled[0], civisibility.TestIsDisabled, "true")
+	assertTag(t, disabled[0], civisibility.TestSkipReason, civisibility.TestDisabledSkipReason)
+
+	pending := check("Calculator is pending", civisibility.TestStatusSkip)
+	assertTag(t, pending[0], civisibility.TestSkipReason, civisibility.GinkgoPendingSkipReason)
+
+	skipped := check("Calculator is skipped", civisibility.TestStatusSkip)
+	assertTag(t, skipped[0], civisibility.TestSkipReason, "not today")
+
+	panics := check("Calculator panics", civisibility.TestStatusFail, civisibility.TestStatusFail, civisibility.TestStatusFail)
+	assertTag(t, panics[0], ext.ErrorType, "panic")
+	assertTag(t, panics[0], ext.ErrorMsg, "boom")
+}
+
+func assertTag(t *testing.T, span *mocktracer.Span, tag string, expected any) {
+	t.Helper()
+	if v := span.Tag(tag); v != expected {
+		t.Errorf("expected tag %s of %q to be %v, got %v", tag, span.Tag(civisibility.TestName), expected, v)
+	}
+}
+
+func setUpHTTPServer() *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v2/libraries/tests/services/setting":
+			var response struct {
+				Data struct {
+					Attributes civisibility.Settings `json:"attributes"`
+				} `json:"data"`
+			}
+			response.Data.Attributes.FlakyTestRetriesEnabled = true
+			response.Data.Attributes.KnownTestsEnabled = true
+			response.Data.Attributes.EarlyFlakeDetection.Enabled = true
+			response.Data.Attributes.EarlyFlakeDetection.SlowTestRetries.FiveS = 2
+			response.Data.Attributes.TestManagement.Enabled = true
+			json.NewEncoder(w).Encode(&response)
+		case "/api/v2/ci/libraries/tests":
+			known := map[string]any{
+				"tests": map[string]any{
+					moduleName: map[string]any{
+						suiteDescription: []string{
+							"Calculator adds",
+							"Calculator when flaky passes on retry",
+							"Calculator is quarantined",
+							"Calculator is disabled",
+							"Calculator is pending",
+							"Calculator is skipped",
+							"Calculator panics",
+						},
+					},
+				},
+			}
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"attributes": known}})
+		case "/api/v2/test/libraries/test-management/tests":
+			quarantined := map[string]any{"properties": map[string]any{"quarantined": true}}
+			disabled := map[string]any{"properties": map[string]any{"disabled": true}}
+			managed := map[string]any{
+				"modules": map[string]any{
+					moduleName: map[string]any{
+						"suites": map[string]any{
+							suiteDescription: map[string]any{
+								"tests": map[string]any{
+									"Calculator is quarantined": quarantined,
+									"Calculator is disabled":    disabled,
+									"Calculator panics":         quarantined,
+								},
+							},
+						},
+					},
+				},
+			}
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"attributes": managed}})
+		case "/api/v2/git/repository/search_commits":
+			w.Write([]byte("{}"))
+		case "/api/v2/git/repository/packfile":
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	os.Setenv("DD_CIVISIBILITY_AGENTLESS_ENABLED", "1")
+	os.Setenv("DD_CIVISIBILITY_AGENTLESS_URL", server.URL)
+	os.Setenv("DD_API_KEY", "12345")
+	return server
+}
@@ -24,7 +24,7 @@ func (tr *Tracer) SetConsumeDSMCheckpoint(msg Message) {
 	carrier := NewMessageCarrier(msg)
 	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(
 		datastreams.ExtractFromBase64Carrier(context.Background(), carrier),
-		options.CheckpointParams{PayloadSize: getConsumerMsgSize(msg)},
+		options.CheckpointParams{PayloadSize: getConsumerMsgSize(msg), TransactionID: tr.transactionID(msg)},
 		edges...,
 	)
 	if !ok {
@@ -54,7 +54,7 @@ func (tr *Tracer) SetProduceDSMCheckpoint(msg Message, writer Writer) {
 	carrier := MessageCarrier{msg}
 	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(
 		datastreams.ExtractFromBase64Carrier(context.Background(), carrier),
-		options.CheckpointParams{PayloadSize: getProducerMsgSize(msg)},
+		options.CheckpointParams{PayloadSize: getProducerMsgSize(msg), TransactionID: tr.transactionID(msg)},
 		edges...,
 	)
 	if !ok {
@@ -65,6 +65,13 @@ func (tr *Tracer) SetProduceDSMCheckpoint(msg Message, writer Writer) {
 	datastreams.InjectToBase64Carrier(ctx, carrier)
 }
 
+func (tr *Tracer) transactionID(msg Message) string {
+	if tr.transactionIDFn == nil {
+		return ""
+	}
+	return tr.transactionIDFn(msg)
+}
+
 func getProducerMsgSize(msg Message) (size int64) {
 	for _, header := range msg.GetHeaders() {
 		size += int64(len(header.GetKey()) + len(header.GetValue()))
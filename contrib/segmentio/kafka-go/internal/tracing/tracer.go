@@ -24,6 +24,7 @@ type Tracer struct {
 	producerSpanName    string
 	analyticsRate       float64
 	dataStreamsEnabled  bool
+	transactionIDFn     func(msg Message) string
 	kafkaCfg            KafkaConfig
 }
 
@@ -93,6 +94,14 @@ func WithDataStreams() Option {
 	})
 }
 
+// WithDataStreamsTransactionID sets the function extracting the transaction ID of the messages, e.g. an order ID,
+// to follow them across the Data Streams pathways. Messages without transaction ID return an empty string.
+func WithDataStreamsTransactionID(fn func(msg Message) string) Option {
+	return OptionFn(func(tr *Tracer) {
+		tr.transactionIDFn = fn
+	})
+}
+
 func Logger() instrumentation.Logger {
 	return instr.Logger()
 }
@@ -6,12 +6,17 @@
 package tracing
 
 import (
+	"context"
 	"math"
 	"testing"
 
+	"github.com/DataDog/dd-trace-go/v2/datastreams"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/mocktracer"
 	"github.com/DataDog/dd-trace-go/v2/instrumentation/testutils"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTracerAnalyticsSettings(t *testing.T) {
@@ -53,3 +58,50 @@ func TestTracerAnalyticsSettings(t *testing.T) {
 		assert.True(t, tr.dataStreamsEnabled)
 	})
 }
+
+type testMessage struct {
+	topic   string
+	headers []Header
+}
+
+func (m *testMessage) GetValue() []byte            { return nil }
+func (m *testMessage) GetKey() []byte              { return nil }
+func (m *testMessage) GetHeaders() []Header        { return m.headers }
+func (m *testMessage) SetHeaders(headers []Header) { m.headers = headers }
+func (m *testMessage) GetTopic() string            { return m.topic }
+func (m *testMessage) GetPartition() int           { return 0 }
+func (m *testMessage) GetOffset() int64            { return 0 }
+
+type testWriter struct{}
+
+func (testWriter) GetTopic() string { return "" }
+
+func TestDataStreamsSchema(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	schema, err := datastreams.NewJSONSchema(`{"title": "Order", "type": "object"}`)
+	require.NoError(t, err)
+	datastreams.RegisterSchema("orders", schema)
+	defer datastreams.RegisterSchema("orders", datastreams.Schema{})
+
+	tr := NewTracer(KafkaConfig{}, WithDataStreams())
+	produce := tr.StartProduceSpan(context.Background(), testWriter{}, &testMessage{topic: "orders"})
+	produce.Finish()
+	consume := tr.StartConsumeSpan(context.Background(), &testMessage{topic: "orders"})
+	consume.Finish()
+	other := tr.StartConsumeSpan(context.Background(), &testMessage{topic: "other"})
+	other.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 3)
+	assert.Equal(t, schema.ID, spans[0].Tag(ext.SchemaID))
+	assert.Equal(t, "Order", spans[0].Tag(ext.SchemaName))
+	assert.Equal(t, datastreams.SchemaTypeJSON, spans[0].Tag(ext.SchemaType))
+	assert.Equal(t, ext.SchemaOperationSerialization, spans[0].Tag(ext.SchemaOperation))
+	assert.Equal(t, schema.Definition, spans[0].Tag(ext.SchemaDefinition))
+	assert.Equal(t, float64(1), spans[0].Tag(ext.SchemaWeight))
+	assert.Equal(t, ext.SchemaOperationDeserialization, spans[1].Tag(ext.SchemaOperation))
+	assert.Equal(t, schema.Definition, spans[1].Tag(ext.SchemaDefinition))
+	assert.Nil(t, spans[2].Tag(ext.SchemaID))
+}
@@ -47,6 +47,9 @@ func (tr *Tracer) StartConsumeSpan(ctx context.Context, msg Message) *tracer.Spa
 		opts = append(opts, tracer.ChildOf(spanctx))
 	}
 	span, _ := tracer.StartSpanFromContext(ctx, tr.consumerSpanName, opts...)
+	if tr.dataStreamsEnabled {
+		tracer.TagDataStreamsSchema(span, msg.GetTopic(), ext.SchemaOperationDeserialization)
+	}
 	// reinject the span context so consumers can pick it up
 	if err := tracer.Inject(span.Context(), carrier); err != nil {
 		instr.Logger().Debug("contrib/segmentio/kafka-go: Failed to inject span context into carrier in reader, %v", err)
@@ -77,6 +80,9 @@ func (tr *Tracer) StartProduceSpan(ctx context.Context, writer Writer, msg Messa
 	opts = append(opts, spanOpts...)
 	carrier := NewMessageCarrier(msg)
 	span, _ := tracer.StartSpanFromContext(ctx, tr.producerSpanName, opts...)
+	if tr.dataStreamsEnabled {
+		tracer.TagDataStreamsSchema(span, topic, ext.SchemaOperationSerialization)
+	}
 	if err := tracer.Inject(span.Context(), carrier); err != nil {
 		instr.Logger().Debug("contrib/segmentio/kafka-go: Failed to inject span context into carrier in writer, %v", err)
 	}
@@ -5,7 +5,11 @@
 
 package kafka
 
-import "github.com/DataDog/dd-trace-go/contrib/segmentio/kafka-go/v2/internal/tracing"
+import (
+	"github.com/segmentio/kafka-go"
+
+	"github.com/DataDog/dd-trace-go/contrib/segmentio/kafka-go/v2/internal/tracing"
+)
 
 // Option describes options for the Kafka integration.
 type Option = tracing.Option
@@ -33,3 +37,14 @@ func WithAnalyticsRate(rate float64) Option {
 func WithDataStreams() Option {
 	return tracing.WithDataStreams()
 }
+
+// WithDataStreamsTransactionID sets the function extracting the transaction ID of the messages, e.g. an order ID,
+// to follow them across the Data Streams pathways. Messages without transaction ID return an empty string.
+func WithDataStreamsTransactionID(fn func(msg *kafka.Message) string) Option {
+	return tracing.WithDataStreamsTransactionID(func(msg tracing.Message) string {
+		if m, ok := msg.(*wMessage); ok {
+			return fn(m.Message)
+		}
+		return ""
+	})
+}
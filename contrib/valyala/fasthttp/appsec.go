@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package fasthttp
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+
+	"github.com/DataDog/dd-trace-go/v2/instrumentation/appsec/emitter/httpsec"
+	"github.com/DataDog/dd-trace-go/v2/instrumentation/appsec/trace"
+)
+
+// userContextKey is the key of the user value holding the context of the
+// request, see UserContext.
+type userContextKey struct{}
+
+// UserContext returns the context of a request handled by WrapHandler. When
+// AppSec is enabled, it holds the AppSec operation of the request, and must be
+// passed to the functions of the appsec package, such as appsec.SetUser, so
+// that they can monitor and block the request.
+func UserContext(fctx *fasthttp.RequestCtx) context.Context {
+	if ctx, ok := fctx.UserValue(userContextKey{}).(context.Context); ok {
+		return ctx
+	}
+	return fctx
+}
+
+// withAppSec wraps the given request handler with the AppSec HTTP handler
+// operation. The request is monitored before calling h, and the response
+// after. When a blocking action is returned by the WAF, the blocking response
+// is written to the fasthttp response and h is not called.
+func withAppSec(h fasthttp.RequestHandler, span trace.TagSetter) fasthttp.RequestHandler {
+	return func(fctx *fasthttp.RequestCtx) {
+		op, blockAtomic, ctx := httpsec.StartOperation(fctx, httpsec.HandlerOperationArgs{
+			Framework:   "github.com/valyala/fasthttp",
+			Method:      string(fctx.Method()),
+			RequestURI:  string(fctx.Request.Header.RequestURI()),
+			Host:        string(fctx.Host()),
+			RemoteAddr:  fctx.RemoteAddr().String(),
+			Headers:     requestHeaders(&fctx.Request.Header),
+			Cookies:     requestCookies(&fctx.Request.Header),
+			QueryParams: argsToMap(fctx.QueryArgs()),
+		}, span)
+		fctx.SetUserValue(userContextKey{}, ctx)
+		defer func() {
+			op.Finish(httpsec.HandlerOperationRes{
+				Headers:    responseHeaders(&fctx.Response.Header),
+				StatusCode: fctx.Response.StatusCode(),
+			})
+			if blockPtr := blockAtomic.Load(); blockPtr != nil && blockPtr.Handler != nil {
+				writeBlockingResponse(fctx, blockPtr.Handler)
+			}
+		}()
+
+		if body := parsedBody(fctx); body != nil && blockAtomic.Load() == nil {
+			// A blocking decision is reported through blockAtomic, which is
+			// checked right below, so the returned error can be ignored.
+			_ = httpsec.MonitorParsedBody(ctx, body)
+		}
+		if blockPtr := blockAtomic.Load(); blockPtr != nil && blockPtr.Handler != nil {
+			writeBlockingResponse(fctx, blockPtr.Handler)
+			blockPtr.Handler = nil
+			return
+		}
+		h(fctx)
+	}
+}
+
+// writeBlockingResponse discards the current fasthttp response and replaces it
+// with the response written by the given blocking handler.
+func writeBlockingResponse(fctx *fasthttp.RequestCtx, h http.Handler) {
+	fctx.Response.Reset()
+	fasthttpadaptor.NewFastHTTPHandler(h)(fctx)
+}
+
+// requestHeaders returns the request headers with their canonical names, as
+// expected by the AppSec HTTP operation.
+func requestHeaders(h *fasthttp.RequestHeader) map[string][]string {
+	headers := make(map[string][]string)
+	h.VisitAll(func(k, v []byte) {
+		key := http.CanonicalHeaderKey(string(k))
+		headers[key] = append(headers[key], string(v))
+	})
+	return headers
+}
+
+func responseHeaders(h *fasthttp.ResponseHeader) map[string][]string {
+	headers := make(map[string][]string)
+	h.VisitAll(func(k, v []byte) {
+		key := http.CanonicalHeaderKey(string(k))
+		headers[key] = append(headers[key], string(v))
+	})
+	return headers
+}
+
+// requestCookies returns the map of parsed cookies if any, following the
+// specification of the rule address `server.request.cookies`.
+func requestCookies(h *fasthttp.RequestHeader) map[string][]string {
+	var cookies map[string][]string
+	h.VisitAllCookie(func(k, v []byte) {
+		if cookies == nil {
+			cookies = make(map[string][]string)
+		}
+		cookies[string(k)] = append(cookies[string(k)], string(v))
+	})
+	return cookies
+}
+
+func argsToMap(args *fasthttp.Args) map[string][]string {
+	if args.Len() == 0 {
+		return nil
+	}
+	m := make(map[string][]string, args.Len())
+	args.VisitAll(func(k, v []byte) {
+		m[string(k)] = append(m[string(k)], string(v))
+	})
+	return m
+}
+
+// parsedBody parses the request body according to its content type. It
+// returns nil when the body is empty or its content type is not supported.
+func parsedBody(fctx *fasthttp.RequestCtx) any {
+	if len(fctx.Request.Body()) == 0 {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(string(fctx.Request.Header.ContentType()))
+	switch mediaType {
+	case "application/json":
+		var body any
+		if err := json.Unmarshal(fctx.Request.Body(), &body); err != nil {
+			return nil
+		}
+		return body
+	case "application/x-www-form-urlencoded":
+		if form := argsToMap(fctx.PostArgs()); form != nil {
+			return form
+		}
+	case "multipart/form-data":
+		form, err := fctx.MultipartForm()
+		if err != nil || len(form.Value) == 0 {
+			return nil
+		}
+		return form.Value
+	}
+	return nil
+}
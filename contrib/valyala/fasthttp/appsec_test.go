@@ -0,0 +1,166 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package fasthttp
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	pappsec "github.com/DataDog/dd-trace-go/v2/appsec"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/mocktracer"
+	"github.com/DataDog/dd-trace-go/v2/instrumentation/testutils"
+)
+
+func serveRequest(h fasthttp.RequestHandler, req *fasthttp.Request) *fasthttp.RequestCtx {
+	var fctx fasthttp.RequestCtx
+	fctx.Init(req, &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 4242}, nil)
+	h(&fctx)
+	return &fctx
+}
+
+func TestAppSec(t *testing.T) {
+	t.Setenv("DD_APPSEC_WAF_TIMEOUT", "1h") // Functionally unlimited
+	testutils.StartAppSec(t)
+	if !instr.AppSecEnabled() {
+		t.Skip("appsec disabled")
+	}
+
+	h := WrapHandler(func(fctx *fasthttp.RequestCtx) {
+		fctx.SetStatusCode(fasthttp.StatusOK)
+		fctx.SetBodyString("Hello World!\n")
+	})
+
+	t.Run("request-uri", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		// Send an LFI attack (according to appsec rule id crs-930-110)
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		req.Header.SetMethod("POST")
+		req.SetRequestURI("/../../../secret.txt")
+		fctx := serveRequest(h, req)
+		require.Equal(t, fasthttp.StatusOK, fctx.Response.StatusCode())
+
+		finished := mt.FinishedSpans()
+		require.Len(t, finished, 1)
+		event, ok := finished[0].Tag("_dd.appsec.json").(string)
+		require.True(t, ok)
+		require.Contains(t, event, "crs-930-110")
+		require.Contains(t, event, "server.request.uri.raw")
+	})
+
+	t.Run("body", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		// Send a PHP injection attack (according to appsec rule id crs-933-130)
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		req.Header.SetMethod("POST")
+		req.Header.SetContentType("application/json")
+		req.SetRequestURI("/body")
+		req.SetBodyString(`{"name":"$globals"}`)
+		serveRequest(h, req)
+
+		finished := mt.FinishedSpans()
+		require.Len(t, finished, 1)
+		event, ok := finished[0].Tag("_dd.appsec.json").(string)
+		require.True(t, ok)
+		require.Contains(t, event, "crs-933-130")
+		require.Contains(t, event, "server.request.body")
+	})
+}
+
+// Test that blocking works by using custom rules/rules data
+func TestBlocking(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "../../../internal/appsec/testdata/blocking.json")
+	t.Setenv("DD_APPSEC_WAF_TIMEOUT", "1h") // Functionally unlimited
+
+	testutils.StartAppSec(t)
+	if !instr.AppSecEnabled() {
+		t.Skip("appsec disabled")
+	}
+
+	h := WrapHandler(func(fctx *fasthttp.RequestCtx) {
+		if userID := string(fctx.Request.Header.Peek("user-id")); userID != "" {
+			if err := pappsec.SetUser(UserContext(fctx), userID); err != nil {
+				return
+			}
+		}
+		fctx.SetStatusCode(fasthttp.StatusOK)
+		fctx.SetBodyString("Hello World!\n")
+	})
+
+	for _, tc := range []struct {
+		name        string
+		headers     map[string]string
+		contentType string
+		body        string
+		shouldBlock bool
+	}{
+		{
+			name:        "ip/block",
+			headers:     map[string]string{"x-forwarded-for": "1.2.3.4"},
+			shouldBlock: true,
+		},
+		{
+			name:    "ip/no-block",
+			headers: map[string]string{"x-forwarded-for": "1.2.3.5"},
+		},
+		{
+			name:        "user/block",
+			headers:     map[string]string{"user-id": "blocked-user-1"},
+			shouldBlock: true,
+		},
+		{
+			name:    "user/no-block",
+			headers: map[string]string{"user-id": "legit-user-1"},
+		},
+		{
+			name:        "body/block",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=$globals",
+			shouldBlock: true,
+		},
+		{
+			name:        "body/no-block",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=datadog",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			req := fasthttp.AcquireRequest()
+			defer fasthttp.ReleaseRequest(req)
+			req.Header.SetMethod("POST")
+			req.SetRequestURI("/ip")
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			if tc.body != "" {
+				req.Header.SetContentType(tc.contentType)
+				req.SetBodyString(tc.body)
+			}
+			fctx := serveRequest(h, req)
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			if tc.shouldBlock {
+				require.Equal(t, fasthttp.StatusForbidden, fctx.Response.StatusCode())
+				require.Equal(t, "true", spans[0].Tag("appsec.blocked"))
+				require.False(t, strings.Contains(string(fctx.Response.Body()), "Hello World!"))
+			} else {
+				require.Equal(t, fasthttp.StatusOK, fctx.Response.StatusCode())
+				require.NotContains(t, spans[0].Tags(), "appsec.blocked")
+			}
+		})
+	}
+}
@@ -51,7 +51,11 @@ func WrapHandler(h fasthttp.RequestHandler, opts ...Option) fasthttp.RequestHand
 		}
 		span := StartSpanFromContext(fctx, "http.request", spanOpts...)
 		defer span.Finish()
-		h(fctx)
+		next := h
+		if instr.AppSecEnabled() {
+			next = withAppSec(next, span)
+		}
+		next(fctx)
 		span.SetTag(ext.ResourceName, cfg.resourceNamer(fctx))
 		status := fctx.Response.StatusCode()
 		if cfg.isStatusError(status) {
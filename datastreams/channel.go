@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datastreams
+
+import (
+	"context"
+	"errors"
+
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
+	"github.com/DataDog/dd-trace-go/v2/internal/datastreams"
+)
+
+// ErrChannelClosed is returned by Receive when the channel is closed.
+var ErrChannelClosed = errors.New("datastreams: channel closed")
+
+// Envelope carries a value between the stages of an in-process pipeline, e.g. through a Go channel
+// feeding a worker pool, along with its Data Streams pathway. This allows the time spent by the value
+// waiting between stages to be part of the pathway, as an edge of type internal.
+type Envelope[T any] struct {
+	// Value is the value carried.
+	Value T
+
+	pathway    datastreams.Pathway
+	hasPathway bool
+}
+
+// NewEnvelope sets a produce checkpoint towards the stage named name for the pathway of ctx, and returns
+// an envelope carrying value and the resulting pathway. The envelope should be opened with Open by the
+// stage receiving it.
+func NewEnvelope[T any](ctx context.Context, name string, value T) Envelope[T] {
+	e := Envelope[T]{Value: value}
+	outCtx, ok := tracer.SetDataStreamsCheckpoint(ctx, "direction:out", "topic:"+name, "type:internal")
+	if ok {
+		e.pathway, e.hasPathway = datastreams.PathwayFromContext(outCtx)
+	}
+	return e
+}
+
+// Open sets a consume checkpoint from the stage named name for the pathway carried by the envelope, and
+// returns ctx with the resulting pathway, to be used by the stage processing the value.
+func (e Envelope[T]) Open(ctx context.Context, name string) context.Context {
+	if e.hasPathway {
+		ctx = datastreams.ContextWithPathway(ctx, e.pathway)
+	}
+	outCtx, _ := tracer.SetDataStreamsCheckpoint(ctx, "direction:in", "topic:"+name, "type:internal")
+	return outCtx
+}
+
+// Send sends value on ch in an envelope created by NewEnvelope. It returns the error of ctx if ctx is
+// done before the value is sent.
+func Send[T any](ctx context.Context, ch chan<- Envelope[T], name string, value T) error {
+	e := NewEnvelope(ctx, name, value)
+	select {
+	case ch <- e:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Receive receives a value sent on ch with Send, and returns it along with ctx carrying its pathway, as
+// returned by Envelope.Open. It returns ErrChannelClosed if ch is closed, or the error of ctx if ctx is
+// done before a value is received.
+func Receive[T any](ctx context.Context, ch <-chan Envelope[T], name string) (context.Context, T, error) {
+	var zero T
+	select {
+	case e, ok := <-ch:
+		if !ok {
+			return ctx, zero, ErrChannelClosed
+		}
+		return e.Open(ctx, name), e.Value, nil
+	case <-ctx.Done():
+		return ctx, zero, ctx.Err()
+	}
+}
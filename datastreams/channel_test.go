@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datastreams
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/mocktracer"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
+	"github.com/DataDog/dd-trace-go/v2/internal/datastreams"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannel(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	ctx, _ := tracer.SetDataStreamsCheckpoint(context.Background(), "direction:in", "type:kafka", "topic:orders")
+	upstream, _ := datastreams.PathwayFromContext(ctx)
+
+	ch := make(chan Envelope[string], 1)
+	require.NoError(t, Send(ctx, ch, "enrich", "order"))
+	outCtx, value, err := Receive(context.Background(), ch, "enrich")
+	require.NoError(t, err)
+	assert.Equal(t, "order", value)
+
+	// The pathway continues from the upstream pathway with an edge out and in of the stage.
+	p, ok := datastreams.PathwayFromContext(outCtx)
+	require.True(t, ok)
+	assert.NotEqual(t, upstream.GetHash(), p.GetHash())
+	processor := mt.(interface {
+		GetDataStreamsProcessor() *datastreams.Processor
+	}).GetDataStreamsProcessor()
+	out, _ := datastreams.PathwayFromContext(processor.SetCheckpoint(ctx, "direction:out", "topic:enrich", "type:internal"))
+	in, _ := datastreams.PathwayFromContext(processor.SetCheckpoint(datastreams.ContextWithPathway(context.Background(), out), "direction:in", "topic:enrich", "type:internal"))
+	assert.Equal(t, in.GetHash(), p.GetHash())
+
+	close(ch)
+	_, _, err = Receive(context.Background(), ch, "enrich")
+	assert.ErrorIs(t, err, ErrChannelClosed)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, Send(cancelled, make(chan Envelope[string]), "enrich", "order"), context.Canceled)
+	_, _, err = Receive(cancelled, make(chan Envelope[string]), "enrich")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestEnvelopeWithoutDataStreams(t *testing.T) {
+	e := NewEnvelope(context.Background(), "enrich", 1)
+	assert.Equal(t, 1, e.Value)
+	ctx := e.Open(context.Background(), "enrich")
+	_, ok := datastreams.PathwayFromContext(ctx)
+	assert.False(t, ok)
+}
@@ -8,4 +8,7 @@ package options
 type CheckpointParams struct {
 	PayloadSize     int64
 	ServiceOverride string
+	// TransactionID identifies the message of the checkpoint, e.g. an order ID, to follow it across the
+	// pathways. The checkpoint of the transaction is named after the edge tags of the checkpoint.
+	TransactionID string
 }
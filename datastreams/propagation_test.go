@@ -7,6 +7,7 @@ package datastreams
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/mocktracer"
@@ -43,3 +44,15 @@ func TestBase64Propagation(t *testing.T) {
 	assert.Equal(t, expected.GetHash(), got.GetHash())
 	assert.NotEqual(t, 0, expected.GetHash())
 }
+
+func TestBase64PropagationHTTPHeaders(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	ctx, _ := tracer.SetDataStreamsCheckpoint(context.Background(), "direction:out", "type:http")
+	c := tracer.HTTPHeadersCarrier(http.Header{})
+	InjectToBase64Carrier(ctx, c)
+	got, ok := datastreams.PathwayFromContext(ExtractFromBase64Carrier(context.Background(), c))
+	assert.True(t, ok)
+	expected, _ := datastreams.PathwayFromContext(ctx)
+	assert.Equal(t, expected.GetHash(), got.GetHash())
+}
@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datastreams
+
+import (
+	"github.com/DataDog/dd-trace-go/v2/internal/datastreams"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Types of schemas.
+const (
+	SchemaTypeProtobuf = datastreams.SchemaTypeProtobuf
+	SchemaTypeAvro     = datastreams.SchemaTypeAvro
+	SchemaTypeJSON     = datastreams.SchemaTypeJSON
+)
+
+// Schema is the schema of the messages of a topic. Its ID is computed from its definition, so that
+// producers and consumers using the same definition report the same ID.
+type Schema = datastreams.Schema
+
+// NewProtobufSchema returns the schema of a Protobuf message, e.g. NewProtobufSchema((&pb.Order{}).ProtoReflect().Descriptor()).
+func NewProtobufSchema(desc protoreflect.MessageDescriptor) Schema {
+	return datastreams.NewProtobufSchema(desc)
+}
+
+// NewAvroSchema returns the schema of an Avro schema definition in JSON.
+func NewAvroSchema(definition string) (Schema, error) {
+	return datastreams.NewAvroSchema(definition)
+}
+
+// NewJSONSchema returns the schema of a JSON Schema definition.
+func NewJSONSchema(definition string) (Schema, error) {
+	return datastreams.NewJSONSchema(definition)
+}
+
+// RegisterSchema registers the schema of the messages of a topic. The integrations producing or consuming
+// the topic with Data Streams Monitoring enabled tag their spans with the schema, and sample its definition
+// periodically, so that schema changes can be correlated with errors.
+// Registering the zero Schema unregisters the schema of the topic.
+func RegisterSchema(topic string, schema Schema) {
+	datastreams.RegisterSchema(topic, schema)
+}
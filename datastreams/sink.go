@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datastreams
+
+import (
+	"github.com/DataDog/dd-trace-go/v2/internal/datastreams"
+)
+
+type (
+	// Sink receives the Data Streams Monitoring stats flushed by the tracer every 10 seconds, in addition
+	// to the agent. Sinks are added with tracer.WithDataStreamsSink.
+	Sink = datastreams.Sink
+
+	// StatsPayload holds the stats flushed for a service. Its distributions are serialized sketches,
+	// Summarize returns them as percentiles.
+	StatsPayload = datastreams.StatsPayload
+
+	// PayloadSummary is a readable form of a StatsPayload, with the distributions summarized.
+	PayloadSummary = datastreams.PayloadSummary
+
+	// TransactionSummary is a checkpoint of a transaction, tracked with tracer.TrackDataStreamsTransaction.
+	TransactionSummary = datastreams.TransactionSummary
+
+	// FileSink is a Sink writing the stats to a file in the JSON lines format, one PayloadSummary by line.
+	FileSink = datastreams.FileSink
+
+	// Inspector is a Sink keeping the stats flushed in the last 5 minutes in memory, and an http.Handler
+	// rendering them as text, or as JSON with the format=json query parameter. It allows verifying the
+	// Data Streams instrumentation of a service locally, e.g.:
+	//
+	//	inspector := datastreams.NewInspector()
+	//	tracer.Start(tracer.WithDataStreamsSink(inspector))
+	//	http.Handle("/debug/datastreams", inspector)
+	Inspector = datastreams.Inspector
+)
+
+// NewFileSink returns a FileSink appending to the file at path, which is created if needed.
+func NewFileSink(path string) (*FileSink, error) {
+	return datastreams.NewFileSink(path)
+}
+
+// NewInspector returns a new Inspector.
+func NewInspector() *Inspector {
+	return datastreams.NewInspector()
+}
+
+// Summarize returns the readable form of a payload.
+func Summarize(p *StatsPayload) PayloadSummary {
+	return datastreams.Summarize(p)
+}
@@ -0,0 +1,30 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package ext
+
+// Data Streams Monitoring schema tags.
+const (
+	// SchemaID identifies the definition of the schema of a message.
+	SchemaID = "schema.id"
+	// SchemaName holds the name of the schema of a message.
+	SchemaName = "schema.name"
+	// SchemaType holds the type of the schema of a message: protobuf, avro or json.
+	SchemaType = "schema.type"
+	// SchemaTopic holds the topic the schema is registered for.
+	SchemaTopic = "schema.topic"
+	// SchemaOperation holds whether the message is serialized or deserialized.
+	SchemaOperation = "schema.operation"
+	// SchemaDefinition holds the definition of the schema, sampled periodically.
+	SchemaDefinition = "schema.definition"
+	// SchemaWeight holds the number of messages the sampled schema definition represents.
+	SchemaWeight = "schema.weight"
+)
+
+// Available values for schema.operation.
+const (
+	SchemaOperationSerialization   = "serialization"
+	SchemaOperationDeserialization = "deserialization"
+)
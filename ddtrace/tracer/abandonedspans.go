@@ -106,7 +106,12 @@ func newAbandonedSpanCandidate(s *Span, finished bool) *abandonedSpanCandidate {
 
 // String takes a span and returns a human-readable string representing that span.
 func (s *abandonedSpanCandidate) String() string {
-	age := now() - s.Start
+	return s.format(now())
+}
+
+// format returns a human-readable string representing the span at the UNIX time curTime, in nanoseconds.
+func (s *abandonedSpanCandidate) format(curTime int64) string {
+	age := curTime - s.Start
 	a := fmt.Sprintf("%d sec", age/1e9)
 	return fmt.Sprintf("[name: %s, integration: %s, span_id: %d, trace_id: %d, age: %s],", s.Name, s.Integration, s.SpanID, s.TraceID, a)
 }
@@ -130,13 +135,18 @@ type abandonedSpansDebugger struct {
 	// addedSpans and removedSpans are internal counters, mainly for testing
 	// purposes
 	addedSpans, removedSpans uint32
+
+	// now returns the current UNIX time in nanoseconds.
+	now func() int64
 }
 
-// newAbandonedSpansDebugger creates a new abandonedSpansDebugger debugger
-func newAbandonedSpansDebugger() *abandonedSpansDebugger {
+// newAbandonedSpansDebugger creates a new abandonedSpansDebugger debugger, using now
+// to get the current UNIX time in nanoseconds.
+func newAbandonedSpansDebugger(now func() int64) *abandonedSpansDebugger {
 	d := &abandonedSpansDebugger{
 		buckets: make(map[int64]*bucket[uint64, *abandonedSpanCandidate]),
 		In:      make(chan *abandonedSpanCandidate, 10000),
+		now:     now,
 	}
 	atomic.SwapUint32(&d.stopped, 1)
 	return d
@@ -235,7 +245,7 @@ func (d *abandonedSpansDebugger) log(interval *time.Duration) {
 		sb        strings.Builder
 		spanCount = 0
 		truncated = false
-		curTime   = now()
+		curTime   = d.now()
 	)
 
 	if len(d.buckets) == 0 {
@@ -307,7 +317,7 @@ func formatAbandonedSpans(b *bucket[uint64, *abandonedSpanCandidate], interval *
 			t.statsd.Incr("datadog.tracer.abandoned_spans", []string{"name:" + s.Name, "integration:" + s.Integration}, 1)
 		}
 		spanCount++
-		msg := s.String()
+		msg := s.format(curTime)
 		sb.WriteString(msg)
 	}
 	return sb.String(), spanCount
@@ -20,6 +20,7 @@ import (
 	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
 	"github.com/DataDog/dd-trace-go/v2/internal"
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/offline"
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/telemetry"
 	"github.com/DataDog/dd-trace-go/v2/internal/log"
 	"github.com/DataDog/dd-trace-go/v2/internal/version"
@@ -42,6 +43,7 @@ type ciVisibilityTransport struct {
 	testCycleURLPath string            // URL path for the test cycle endpoint.
 	headers          map[string]string // HTTP headers to be included in the requests.
 	agentless        bool              // Gets if the transport is configured in agentless mode (eg: Gzip support)
+	offline          bool              // Gets if the transport writes the payloads to the offline directory instead of sending them
 }
 
 // newCiVisibilityTransport creates and initializes a new civisibilityTransport
@@ -74,6 +76,18 @@ func newCiVisibilityTransport(config *config) *ciVisibilityTransport {
 	// Determine if agentless mode is enabled through an environment variable.
 	agentlessEnabled := internal.BoolEnv(constants.CIVisibilityAgentlessEnabledEnvironmentVariable, false)
 
+	// In offline mode the payloads are written to a directory and uploaded later, so
+	// neither the agent nor the agentless intake are required.
+	if dir := offline.Dir(); dir != "" {
+		log.Debug("ciVisibilityTransport: creating transport instance [offline: true, dir: %v]", dir)
+		return &ciVisibilityTransport{
+			config:           config,
+			testCycleURLPath: dir,
+			headers:          defaultHeaders,
+			offline:          true,
+		}
+	}
+
 	testCycleURL := ""
 	if agentlessEnabled {
 		// Agentless mode is enabled.
@@ -109,8 +123,6 @@ func newCiVisibilityTransport(config *config) *ciVisibilityTransport {
 	}
 	log.Debug("ciVisibilityTransport: creating transport instance [agentless: %v, testcycleurl: %v]", agentlessEnabled, testCycleURL)
 
-	log.Debug("ciVisibilityTransport: creating transport instance [agentless: %v, testcycleurl: %v]", agentlessEnabled, testCycleURL)
-
 	return &ciVisibilityTransport{
 		config:           config,
 		testCycleURLPath: testCycleURL,
@@ -136,6 +148,16 @@ func (t *ciVisibilityTransport) send(p *payload) (body io.ReadCloser, err error)
 		return nil, fmt.Errorf("cannot create buffer payload: %v", bufferErr)
 	}
 
+	if t.offline {
+		// Store the uncompressed payload as is, it's compressed when uploaded if needed.
+		path, err := offline.WritePayload(offline.KindTestCycle, buffer)
+		if err != nil {
+			return nil, fmt.Errorf("cannot write offline payload: %v", err)
+		}
+		log.Debug("ciVisibilityTransport: payload written to %v", path)
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+
 	if t.agentless {
 		// Compress payload
 		var gzipBuffer bytes.Buffer
@@ -162,8 +184,6 @@ func (t *ciVisibilityTransport) send(p *payload) (body io.ReadCloser, err error)
 	if t.agentless {
 		req.Header.Set("Content-Encoding", "gzip")
 	}
-	log.Debug("ciVisibilityTransport: sending transport request: %v bytes", buffer.Len())
-
 	log.Debug("ciVisibilityTransport: sending transport request: %v bytes", buffer.Len())
 	startTime := time.Now()
 	response, err := t.config.httpClient.Do(req)
@@ -11,11 +11,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/offline"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tinylib/msgp/msgp"
 )
 
@@ -110,3 +113,45 @@ func runTransportTest(t *testing.T, agentless, shouldSetAPIKey bool) {
 	assert.Equal(hits, len(testCases))
 	assert.Equal(remainingEvents, 0)
 }
+
+func TestCiVisibilityTransportOffline(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(constants.CIVisibilityOfflineDirEnvironmentVariable, dir)
+	// The agentless settings are ignored in offline mode.
+	t.Setenv(constants.CIVisibilityAgentlessEnabledEnvironmentVariable, "1")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("unexpected request in offline mode")
+	}))
+	defer srv.Close()
+	parsedURL, _ := url.Parse(srv.URL)
+	c := config{
+		ciVisibilityEnabled: true,
+		httpClient:          defaultHTTPClient(0),
+		agentURL:            parsedURL,
+	}
+
+	transport := newCiVisibilityTransport(&c)
+	assert.True(t, transport.offline)
+	assert.False(t, transport.agentless)
+
+	p := newCiVisibilityPayload()
+	for _, span := range getTestTrace(1, 10)[0] {
+		require.NoError(t, p.push(getCiVisibilityEvent(span)))
+	}
+	_, err := transport.send(p.payload)
+	require.NoError(t, err)
+
+	payloads, err := offline.Payloads(dir)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, offline.KindTestCycle, payloads[0].Kind)
+
+	data, err := os.ReadFile(payloads[0].Path)
+	require.NoError(t, err)
+	var testCyclePayload ciTestCyclePayload
+	require.NoError(t, msgp.Decode(bytes.NewReader(data), &testCyclePayload))
+	var events ciVisibilityEvents
+	require.NoError(t, msgp.Decode(bytes.NewReader(testCyclePayload.Events), &events))
+	assert.Len(t, events, 10)
+}
@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import "time"
+
+// Clock provides the current time to the tracer, e.g. to produce deterministic timestamps in golden
+// file tests or when replaying traces. It's set with WithClock, and must be safe for concurrent use.
+//
+// The clock times the spans, and drives the rate limiter of the sampling rules, the buckets of the
+// client-side stats and the abandoned spans debugger.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+// now returns the current UNIX time in nanoseconds of the clock set with WithClock, or of the system.
+func (c *config) now() int64 {
+	if c.clock == nil {
+		return now()
+	}
+	return c.clock.Now().UnixNano()
+}
+
+// nowTime returns the current time of the clock set with WithClock, or of the system.
+func (c *config) nowTime() time.Time {
+	return clockTime(c.clock)
+}
+
+// clockTime returns the current time of clock, or of the system if clock is nil.
+func clockTime(clock Clock) time.Time {
+	if clock == nil {
+		return nowTime()
+	}
+	return clock.Now()
+}
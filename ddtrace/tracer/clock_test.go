@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a clock which only moves when advanced.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// sequentialIDGenerator generates increasing IDs.
+type sequentialIDGenerator struct {
+	mu   sync.Mutex
+	next uint64
+}
+
+func (g *sequentialIDGenerator) SpanID() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.next++
+	return g.next
+}
+
+func (g *sequentialIDGenerator) TraceIDUpper(start time.Time) uint64 {
+	return uint64(start.Unix())
+}
+
+func TestClockAndIDGenerator(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	tracer, transport, flush, stop, err := startTestTracer(t, WithClock(clock), WithIDGenerator(&sequentialIDGenerator{}),
+		WithSamplingRules(TraceSamplingRules(Rule{Rate: 1})))
+	require.NoError(t, err)
+	defer stop()
+
+	clock.advance(time.Second)
+	root := tracer.StartSpan("root")
+	clock.advance(time.Millisecond)
+	child := root.StartChild("child")
+	clock.advance(time.Millisecond)
+	child.Finish()
+	root.Finish()
+	flush(1)
+
+	traces := transport.Traces()
+	require.Len(t, traces, 1)
+	require.Len(t, traces[0], 2)
+	spans := map[string]*Span{}
+	for _, s := range traces[0] {
+		spans[s.name] = s
+	}
+	assert.Equal(t, uint64(1), spans["root"].spanID)
+	assert.Equal(t, uint64(1), spans["root"].traceID)
+	assert.Equal(t, time.Second.Nanoseconds(), spans["root"].start)
+	assert.Equal(t, (2 * time.Millisecond).Nanoseconds(), spans["root"].duration)
+	assert.Equal(t, uint64(2), spans["child"].spanID)
+	assert.Equal(t, uint64(1), spans["child"].parentID)
+	assert.Equal(t, (time.Second + time.Millisecond).Nanoseconds(), spans["child"].start)
+	assert.Equal(t, time.Millisecond.Nanoseconds(), spans["child"].duration)
+	// The upper 64 bits of the trace ID come from the generator.
+	assert.Equal(t, "00000000000000010000000000000001", root.Context().TraceID())
+
+	// The rate limiter of the sampling rules is driven by the clock, the trace being sampled as the root starts.
+	assert.Equal(t, time.Unix(1, 0), tracer.rulesSampling.traces.limiter.prevTime)
+}
+
+func TestAbandonedSpansDebuggerClock(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	c := &config{clock: clock}
+	d := newAbandonedSpansDebugger(c.now)
+	s := &abandonedSpanCandidate{Name: "span", Start: 0}
+	clock.advance(time.Minute)
+	assert.Contains(t, s.format(d.now()), "age: 60 sec")
+}
+
+func TestClockSpanEvent(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1, 0)}
+	tracer, _, _, stop, err := startTestTracer(t, WithClock(clock))
+	require.NoError(t, err)
+	defer stop()
+
+	s := tracer.StartSpan("root")
+	clock.advance(time.Second)
+	s.AddEvent("event")
+	require.Len(t, s.spanEvents, 1)
+	assert.Equal(t, uint64(time.Unix(2, 0).UnixNano()), s.spanEvents[0].TimeUnixNano)
+}
+
+func TestClockRateLimiters(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1, 0)}
+	spanRules := SpanSamplingRules(Rule{NameGlob: "root", Rate: 1, MaxPerSecond: 10})
+	rs := newRulesSampler(nil, spanRules, 1, defaultRateLimit, clock)
+	assert.Equal(t, time.Unix(1, 0), rs.traces.limiter.prevTime)
+	require.Len(t, rs.spans.rules, 1)
+	assert.Equal(t, time.Unix(1, 0), rs.spans.rules[0].limiter.prevTime)
+	// The rules given aren't modified.
+	assert.NotEqual(t, time.Unix(1, 0), spanRules[0].limiter.prevTime)
+}
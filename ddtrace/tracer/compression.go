@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/log"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content encodings of the payloads sent to the agent.
+const (
+	encodingZstd = "zstd"
+	encodingGzip = "gzip"
+)
+
+// negotiateContentEncoding returns the content encoding of the payloads sent to the agent, given the
+// value of DD_TRACE_AGENT_COMPRESSION and the encodings the agent accepts. The setting is one of "auto",
+// the default, which picks the best encoding accepted by the agent, "zstd", "gzip" or "none".
+func negotiateContentEncoding(setting string, accepted []string) string {
+	setting = strings.ToLower(strings.TrimSpace(setting))
+	switch setting {
+	case "", "auto":
+		for _, encoding := range []string{encodingZstd, encodingGzip} {
+			if slices.Contains(accepted, encoding) {
+				return encoding
+			}
+		}
+		return ""
+	case "none":
+		return ""
+	case encodingZstd, encodingGzip:
+		if !slices.Contains(accepted, setting) {
+			log.Warn("DD_TRACE_AGENT_COMPRESSION=%s is not supported by the agent, payloads won't be compressed", setting)
+			return ""
+		}
+		return setting
+	default:
+		log.Warn("DD_TRACE_AGENT_COMPRESSION=%s is not a valid value, expected auto, zstd, gzip or none", setting)
+		return negotiateContentEncoding("auto", accepted)
+	}
+}
+
+// compressionStats holds the metrics of the compression of the payloads, reported as health metrics.
+type compressionStats struct {
+	// rawBytes and compressedBytes hold the size of the payloads before and after compression.
+	rawBytes        atomic.Int64
+	compressedBytes atomic.Int64
+	// duration holds the CPU time spent compressing the payloads, in nanoseconds.
+	duration atomic.Int64
+}
+
+var (
+	gzipWriters = sync.Pool{
+		New: func() any {
+			w, _ := gzip.NewWriterLevel(nil, gzip.BestSpeed)
+			return w
+		},
+	}
+	zstdEncoder     *zstd.Encoder
+	zstdEncoderOnce sync.Once
+)
+
+// getZstdEncoder returns the zstd encoder shared by the transports. It's safe for concurrent use with EncodeAll.
+func getZstdEncoder() *zstd.Encoder {
+	zstdEncoderOnce.Do(func() {
+		zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest), zstd.WithEncoderConcurrency(1))
+	})
+	return zstdEncoder
+}
+
+// compress returns the content of r compressed with encoding, and records the compression in stats.
+func compress(encoding string, r io.Reader, stats *compressionStats) (*bytes.Buffer, error) {
+	var raw bytes.Buffer
+	if _, err := raw.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	var compressed bytes.Buffer
+	switch encoding {
+	case encodingZstd:
+		compressed.Write(getZstdEncoder().EncodeAll(raw.Bytes(), make([]byte, 0, raw.Len()/4)))
+	default:
+		w := gzipWriters.Get().(*gzip.Writer)
+		defer gzipWriters.Put(w)
+		w.Reset(&compressed)
+		if _, err := w.Write(raw.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	}
+	stats.duration.Add(int64(time.Since(start)))
+	stats.rawBytes.Add(int64(raw.Len()))
+	stats.compressedBytes.Add(int64(compressed.Len()))
+	return &compressed, nil
+}
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+)
+
+func TestNegotiateContentEncoding(t *testing.T) {
+	for _, tt := range []struct {
+		setting  string
+		accepted []string
+		want     string
+	}{
+		{"", nil, ""},
+		{"", []string{"gzip"}, encodingGzip},
+		{"auto", []string{"gzip", "zstd"}, encodingZstd},
+		{"none", []string{"gzip", "zstd"}, ""},
+		{"GZIP", []string{"gzip", "zstd"}, encodingGzip},
+		{"zstd", []string{"gzip"}, ""},
+		{"brotli", []string{"gzip"}, encodingGzip},
+	} {
+		assert.Equal(t, tt.want, negotiateContentEncoding(tt.setting, tt.accepted), "%s %v", tt.setting, tt.accepted)
+	}
+}
+
+func TestTransportCompression(t *testing.T) {
+	for _, encoding := range []string{encodingGzip, encodingZstd} {
+		t.Run(encoding, func(t *testing.T) {
+			bodies := map[string][]byte{}
+			srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, encoding, r.Header.Get("Content-Encoding"))
+				var body io.Reader
+				if encoding == encodingGzip {
+					zr, err := gzip.NewReader(r.Body)
+					require.NoError(t, err)
+					body = zr
+				} else {
+					zr, err := zstd.NewReader(r.Body)
+					require.NoError(t, err)
+					defer zr.Close()
+					body = zr
+				}
+				data, err := io.ReadAll(body)
+				assert.NoError(t, err)
+				bodies[r.URL.Path] = data
+			}))
+			defer srv.Close()
+
+			transport := newHTTPTransport(srv.URL, defaultHTTPClient(0))
+			transport.contentEncoding = encoding
+			p, err := encode(getTestTrace(10, 10))
+			require.NoError(t, err)
+			size := p.size()
+			_, err = transport.send(p)
+			require.NoError(t, err)
+			require.NoError(t, transport.sendStats(&pb.ClientStatsPayload{Hostname: "host"}, 0))
+
+			require.Contains(t, bodies, "/v0.4/traces")
+			assert.Len(t, bodies["/v0.4/traces"], size)
+			var stats pb.ClientStatsPayload
+			require.NoError(t, msgp.Decode(bytes.NewReader(bodies["/v0.6/stats"]), &stats))
+			assert.Equal(t, "host", stats.Hostname)
+			assert.Greater(t, transport.compression.rawBytes.Load(), transport.compression.compressedBytes.Load())
+			assert.Greater(t, transport.compression.duration.Load(), int64(0))
+		})
+	}
+}
+
+func TestLoadAgentFeaturesContentEncodings(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"endpoints":["/v0.4/traces"],"content_encodings":["gzip","zstd"]}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DD_TRACE_AGENT_URL", srv.URL)
+	c, err := newConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gzip", "zstd"}, c.agent.contentEncodings)
+	assert.Equal(t, encodingZstd, c.transport.(*httpTransport).contentEncoding)
+}
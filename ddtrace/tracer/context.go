@@ -35,21 +35,55 @@ func SpanFromContext(ctx context.Context) (*Span, bool) {
 	return nil, false
 }
 
+// tracerContextKey is the key of the tracer bound to a context with ContextWithTracer.
+type tracerContextKey struct{}
+
+// ContextWithTracer returns a copy of the given context bound to the tracer t, usually created
+// with New. The spans started with StartSpanFromContext from the context, and from the contexts
+// derived from it, are started by t rather than by the global tracer.
+func ContextWithTracer(ctx context.Context, t Tracer) context.Context {
+	return context.WithValue(ctx, tracerContextKey{}, t)
+}
+
+// TracerFromContext returns the tracer starting the spans from the given context: the tracer
+// bound to it with ContextWithTracer, the tracer of the span it contains, or the global tracer.
+func TracerFromContext(ctx context.Context) Tracer {
+	if ctx == nil {
+		return GetGlobalTracer()
+	}
+	s, _ := SpanFromContext(ctx)
+	return contextTracer(ctx, s)
+}
+
+// contextTracer returns the tracer bound to ctx, or the tracer of its span s if not nil.
+func contextTracer(ctx context.Context, s *Span) Tracer {
+	if t, ok := ctx.Value(tracerContextKey{}).(Tracer); ok && t != nil {
+		return t
+	}
+	if s != nil {
+		return s.spanTracer()
+	}
+	return GetGlobalTracer()
+}
+
 // StartSpanFromContext returns a new span with the given operation name and options. If a span
 // is found in the context, it will be used as the parent of the resulting span. If the ChildOf
 // option is passed, it will only be used as the parent if there is no span found in `ctx`.
+// The span is started by the tracer returned by TracerFromContext.
 func StartSpanFromContext(ctx context.Context, operationName string, opts ...StartSpanOption) (*Span, context.Context) {
 	// copy opts in case the caller reuses the slice in parallel
 	// we will add at least 1, at most 2 items
 	optsLocal := options.Expand(opts, 0, 2)
+	var parent *Span
 	if ctx == nil {
 		// default to context.Background() to avoid panics on Go >= 1.15
 		ctx = context.Background()
 	} else if s, ok := SpanFromContext(ctx); ok {
+		parent = s
 		optsLocal = append(optsLocal, ChildOf(s.Context()))
 	}
 	optsLocal = append(optsLocal, withContext(ctx))
-	s := StartSpan(operationName, optsLocal...)
+	s := contextTracer(ctx, parent).StartSpan(operationName, optsLocal...)
 	if s != nil && s.pprofCtxActive != nil {
 		ctx = s.pprofCtxActive
 	}
@@ -9,6 +9,7 @@ import (
 	"context"
 
 	"github.com/DataDog/dd-trace-go/v2/datastreams/options"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
 	idatastreams "github.com/DataDog/dd-trace-go/v2/internal/datastreams"
 )
 
@@ -71,3 +72,68 @@ func TrackKafkaHighWatermarkOffset(cluster string, topic string, partition int32
 		}
 	}
 }
+
+// TrackProduceOffset should be used in the producer of a system other than Kafka, e.g. Kinesis or NATS, to track
+// the offset of the last message produced to a partition of a topic. Used together with TrackCommitOffset, it
+// generates a backlog metric for the consumer groups. The system prefixes the type of the backlog, e.g. "kinesis",
+// and partition may be empty for systems without partitions.
+func TrackProduceOffset(system, topic, partition string, offset int64) {
+	if t, ok := GetGlobalTracer().(dataStreamsContainer); ok {
+		if p := t.GetDataStreamsProcessor(); p != nil {
+			p.TrackProduceOffset(system, topic, partition, offset)
+		}
+	}
+}
+
+// TrackCommitOffset should be used in the consumer of a system other than Kafka, to track the offset of the last
+// message it acknowledged on a partition of a topic. See TrackProduceOffset.
+func TrackCommitOffset(system, group, topic, partition string, offset int64) {
+	if t, ok := GetGlobalTracer().(dataStreamsContainer); ok {
+		if p := t.GetDataStreamsProcessor(); p != nil {
+			p.TrackCommitOffset(system, group, topic, partition, offset)
+		}
+	}
+}
+
+// TrackDataStreamsTransaction tracks a checkpoint of a transaction, e.g. the processing of an order identified
+// by its ID, so that it can be followed across the pathways of the services tracking the same ID. Checkpoints
+// set with a CheckpointParams.TransactionID are tracked as well. The transactions are sampled by ID with the
+// rate set by DD_DATA_STREAMS_TRANSACTION_SAMPLE_RATE, which defaults to 1.
+func TrackDataStreamsTransaction(transactionID, checkpointName string) {
+	if t, ok := GetGlobalTracer().(dataStreamsContainer); ok {
+		if p := t.GetDataStreamsProcessor(); p != nil {
+			p.TrackTransaction(transactionID, checkpointName)
+		}
+	}
+}
+
+// TagDataStreamsSchema tags span with the schema registered for topic with datastreams.RegisterSchema, if any.
+// The operation is ext.SchemaOperationSerialization when producing a message and ext.SchemaOperationDeserialization
+// when consuming it. The definition of the schema is sampled at most every 30 seconds for a topic and operation,
+// along with the number of messages the sample represents.
+func TagDataStreamsSchema(span *Span, topic string, operation string) {
+	if span == nil {
+		return
+	}
+	schema, ok := idatastreams.GetSchema(topic)
+	if !ok {
+		return
+	}
+	t, ok := GetGlobalTracer().(dataStreamsContainer)
+	if !ok {
+		return
+	}
+	p := t.GetDataStreamsProcessor()
+	if p == nil {
+		return
+	}
+	span.SetTag(ext.SchemaID, schema.ID)
+	span.SetTag(ext.SchemaName, schema.Name)
+	span.SetTag(ext.SchemaType, schema.Type)
+	span.SetTag(ext.SchemaTopic, topic)
+	span.SetTag(ext.SchemaOperation, operation)
+	if sample, weight := p.TrySampleSchema(topic, operation); sample {
+		span.SetTag(ext.SchemaDefinition, schema.Definition)
+		span.SetTag(ext.SchemaWeight, weight)
+	}
+}
@@ -111,6 +111,25 @@ func (t *tracer) reportHealthMetricsAtInterval(interval time.Duration) {
 			}
 
 			t.statsd.Count("datadog.tracer.traces_dropped", int64(tracerstats.Count(tracerstats.TracesDropped)), []string{"reason:trace_too_large"}, 1)
+
+			if ht, ok := t.config.transport.(*httpTransport); ok && ht.contentEncoding != "" {
+				tags := []string{"encoding:" + ht.contentEncoding}
+				raw, compressed := ht.compression.rawBytes.Swap(0), ht.compression.compressedBytes.Swap(0)
+				if compressed > 0 {
+					t.statsd.Gauge("datadog.tracer.payload.compression_ratio", float64(raw)/float64(compressed), tags, 1)
+				}
+				t.statsd.Count("datadog.tracer.payload.compression_time_ns", ht.compression.duration.Swap(0), tags, 1)
+			}
+
+			for k, v := range t.redactions.GetAndReset() {
+				t.statsd.Count("datadog.tracer.redactions", v, []string{"rule:" + k}, 1)
+			}
+
+			if len(t.config.spanProcessors) > 0 {
+				t.statsd.Count("datadog.tracer.span_processor.duration_ns", t.spanProcessorStats.duration.Swap(0), nil, 1)
+				t.statsd.Count("datadog.tracer.span_processor.panics", t.spanProcessorStats.panics.Swap(0), nil, 1)
+				t.statsd.Count("datadog.tracer.span_processor.dropped_spans", t.spanProcessorStats.dropped.Swap(0), nil, 1)
+			}
 		case <-t.stop:
 			return
 		}
@@ -30,6 +30,7 @@ import (
 	"github.com/DataDog/dd-trace-go/v2/internal"
 	appsecconfig "github.com/DataDog/dd-trace-go/v2/internal/appsec/config"
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+	"github.com/DataDog/dd-trace-go/v2/internal/datastreams"
 	"github.com/DataDog/dd-trace-go/v2/internal/globalconfig"
 	"github.com/DataDog/dd-trace-go/v2/internal/log"
 	"github.com/DataDog/dd-trace-go/v2/internal/namingschema"
@@ -128,6 +129,14 @@ type config struct {
 	// of the behaviour of the tracer.
 	agent agentFeatures
 
+	// instance reports whether the configuration is the one of a tracer created with New, which
+	// leaves the process-wide configuration alone.
+	instance bool
+
+	// traceProtocol is the version of the protocol used to send traces to the agent,
+	// traceProtocolV04 or traceProtocolV05.
+	traceProtocol string
+
 	// integrations reports if the user has instrumented a Datadog integration and
 	// if they have a version of the library available to integrate.
 	integrations map[string]integrationConfig
@@ -273,6 +282,22 @@ type config struct {
 	// dataStreamsMonitoringEnabled specifies whether the tracer should enable monitoring of data streams
 	dataStreamsMonitoringEnabled bool
 
+	// dataStreamsSinks holds the sinks receiving the data streams stats, in addition to the agent.
+	dataStreamsSinks []datastreams.Sink
+
+	// spanProcessors holds the span processors called as spans start and finish.
+	spanProcessors []SpanProcessor
+
+	// redactionRules holds the rules redacting sensitive data from the spans, set with
+	// DD_TRACE_REDACTION_RULES and WithRedactionRules.
+	redactionRules []RedactionRule
+
+	// idGenerator generates the IDs of the spans and traces, if set with WithIDGenerator.
+	idGenerator IDGenerator
+
+	// clock provides the current time, if set with WithClock.
+	clock Clock
+
 	// orchestrionCfg holds Orchestrion (aka auto-instrumentation) configuration.
 	// Only used for telemetry currently.
 	orchestrionCfg orchestrionConfig
@@ -342,7 +367,18 @@ const partialFlushMinSpansDefault = 1000
 // newConfig renders the tracer configuration based on defaults, environment variables
 // and passed user opts.
 func newConfig(opts ...StartOption) (*config, error) {
-	c := new(config)
+	return loadConfig(new(config), opts...)
+}
+
+// newInstanceConfig renders the configuration of a tracer created with New, which leaves the
+// process-wide configuration, such as the globalconfig package and the logger, alone.
+func newInstanceConfig(opts ...StartOption) (*config, error) {
+	return loadConfig(&config{instance: true}, opts...)
+}
+
+// loadConfig renders the tracer configuration into c based on defaults, environment variables
+// and passed user opts.
+func loadConfig(c *config, opts ...StartOption) (*config, error) {
 	c.sampler = NewAllSampler()
 	sampleRate := math.NaN()
 	if r := getDDorOtelConfig("sampleRate"); r != "" {
@@ -378,7 +414,7 @@ func newConfig(opts ...StartOption) (*config, error) {
 	if v := os.Getenv("OTEL_LOGS_EXPORTER"); v != "" {
 		log.Warn("OTEL_LOGS_EXPORTER is not supported")
 	}
-	if internal.BoolEnv("DD_TRACE_ANALYTICS_ENABLED", false) {
+	if internal.BoolEnv("DD_TRACE_ANALYTICS_ENABLED", false) && !c.instance {
 		globalconfig.SetAnalyticsRate(1.0)
 	}
 	if os.Getenv("DD_TRACE_REPORT_HOSTNAME") == "true" {
@@ -402,7 +438,9 @@ func newConfig(opts ...StartOption) (*config, error) {
 	}
 	if v := getDDorOtelConfig("service"); v != "" {
 		c.serviceName = v
-		globalconfig.SetServiceName(v)
+		if !c.instance {
+			globalconfig.SetServiceName(v)
+		}
 	}
 	if ver := os.Getenv("DD_VERSION"); ver != "" {
 		c.version = ver
@@ -410,7 +448,7 @@ func newConfig(opts ...StartOption) (*config, error) {
 	if v := os.Getenv("DD_SERVICE_MAPPING"); v != "" {
 		internal.ForEachStringTag(v, internal.DDTagsDelimiter, func(key, val string) { WithServiceMapping(key, val)(c) })
 	}
-	c.headerAsTags = newDynamicConfig("trace_header_tags", nil, setHeaderTags, equalSlice[string])
+	c.headerAsTags = newDynamicConfig("trace_header_tags", nil, c.headerTagsSetter(), equalSlice[string])
 	if v := os.Getenv("DD_TRACE_HEADER_TAGS"); v != "" {
 		c.headerAsTags.update(strings.Split(v, ","), telemetry.OriginEnvVar)
 		// Required to ensure that the startup header tags are set on reset.
@@ -491,6 +529,7 @@ func newConfig(opts ...StartOption) (*config, error) {
 	if v := os.Getenv("DD_TRACE_PEER_SERVICE_MAPPING"); v != "" {
 		internal.ForEachStringTag(v, internal.DDTagsDelimiter, func(key, val string) { c.peerServiceMappings[key] = val })
 	}
+	c.redactionRules = redactionRulesFromEnv()
 	c.retryInterval = time.Millisecond
 	for _, fn := range opts {
 		if fn == nil {
@@ -535,7 +574,9 @@ func newConfig(opts ...StartOption) (*config, error) {
 		if v, ok := globalTags["service"]; ok {
 			if s, ok := v.(string); ok {
 				c.serviceName = s
-				globalconfig.SetServiceName(s)
+				if !c.instance {
+					globalconfig.SetServiceName(s)
+				}
 			}
 		} else {
 			// There is not an explicit service set, default to binary name.
@@ -561,10 +602,10 @@ func newConfig(opts ...StartOption) (*config, error) {
 			MaxTagsHeaderLen: max,
 		})
 	}
-	if c.logger != nil {
+	if c.logger != nil && !c.instance {
 		log.UseLogger(c.logger)
 	}
-	if c.debug {
+	if c.debug && !c.instance {
 		log.SetLevel(log.LevelDebug)
 	}
 
@@ -575,12 +616,18 @@ func newConfig(opts ...StartOption) (*config, error) {
 		c.logStartup = false                       // If we are in CI Visibility mode we don't want to log the startup to stdout to avoid polluting the output
 		ciTransport := newCiVisibilityTransport(c) // Create a default CI Visibility Transport
 		c.transport = ciTransport                  // Replace the default transport with the CI Visibility transport
-		c.ciVisibilityAgentless = ciTransport.agentless
+		// The offline mode doesn't use the agent either.
+		c.ciVisibilityAgentless = ciTransport.agentless || ciTransport.offline
 	}
 
 	// if using stdout or traces are disabled or we are in ci visibility agentless mode, agent is disabled
 	agentDisabled := c.logToStdout || !c.enabled.current || c.ciVisibilityAgentless
 	c.agent = loadAgentFeatures(agentDisabled, c.agentURL, c.httpClient)
+	c.traceProtocol = traceProtocolV04
+	if t, ok := c.transport.(*httpTransport); ok {
+		t.contentEncoding = negotiateContentEncoding(os.Getenv("DD_TRACE_AGENT_COMPRESSION"), c.agent.contentEncodings)
+		c.useTraceProtocol(t)
+	}
 	info, ok := debug.ReadBuildInfo()
 	if !ok {
 		c.loadContribIntegrations([]*debug.Module{})
@@ -590,7 +637,9 @@ func newConfig(opts ...StartOption) (*config, error) {
 	if c.statsdClient == nil {
 		// configure statsd client
 		addr := resolveDogstatsdAddr(c)
-		globalconfig.SetDogstatsdAddr(addr)
+		if !c.instance {
+			globalconfig.SetDogstatsdAddr(addr)
+		}
 		c.dogstatsdAddr = addr
 	}
 	// Re-initialize the globalTags config with the value constructed from the environment and start options
@@ -743,6 +792,12 @@ type agentFeatures struct {
 
 	// spanEvents reports whether the trace-agent can receive spans with the `span_events` field.
 	spanEventsAvailable bool
+
+	// contentEncodings holds the encodings the trace-agent accepts to compress the payloads.
+	contentEncodings []string
+
+	// v05Available reports whether the trace-agent can receive traces on the /v0.5/traces endpoint.
+	v05Available bool
 }
 
 // HasFlag reports whether the agent has set the feat feature flag.
@@ -776,6 +831,7 @@ func loadAgentFeatures(agentDisabled bool, agentURL *url.URL, httpClient *http.C
 		SpanMetaStruct     bool     `json:"span_meta_structs"`
 		ObfuscationVersion int      `json:"obfuscation_version"`
 		SpanEvents         bool     `json:"span_events"`
+		ContentEncodings   []string `json:"content_encodings"`
 		Config             struct {
 			StatsdPort int `json:"statsd_port"`
 		} `json:"config"`
@@ -793,10 +849,13 @@ func loadAgentFeatures(agentDisabled bool, agentURL *url.URL, httpClient *http.C
 	features.peerTags = info.PeerTags
 	features.obfuscationVersion = info.ObfuscationVersion
 	features.spanEventsAvailable = info.SpanEvents
+	features.contentEncodings = info.ContentEncodings
 	for _, endpoint := range info.Endpoints {
 		switch endpoint {
 		case "/v0.6/stats":
 			features.Stats = true
+		case "/v0.5/traces":
+			features.v05Available = true
 		}
 	}
 	features.featureFlags = make(map[string]struct{}, len(info.FeatureFlags))
@@ -862,7 +921,9 @@ func statsTags(c *config) []string {
 			tags = append(tags, k+":"+vstr)
 		}
 	}
-	globalconfig.SetStatsTags(tags)
+	if !c.instance {
+		globalconfig.SetStatsTags(tags)
+	}
 	tags = append(tags, "tracer_version:"+version.Tag)
 	if c.serviceName != "" {
 		tags = append(tags, "service:"+c.serviceName)
@@ -973,7 +1034,9 @@ func WithPropagator(p Propagator) StartOption {
 func WithService(name string) StartOption {
 	return func(c *config) {
 		c.serviceName = name
-		globalconfig.SetServiceName(c.serviceName)
+		if !c.instance {
+			globalconfig.SetServiceName(c.serviceName)
+		}
 	}
 }
 
@@ -1121,7 +1184,10 @@ func WithUDS(socketPath string) StartOption {
 // WithAnalytics allows specifying whether Trace Search & Analytics should be enabled
 // for integrations.
 func WithAnalytics(on bool) StartOption {
-	return func(_ *config) {
+	return func(c *config) {
+		if c.instance {
+			return
+		}
 		if on {
 			globalconfig.SetAnalyticsRate(1.0)
 		} else {
@@ -1132,7 +1198,10 @@ func WithAnalytics(on bool) StartOption {
 
 // WithAnalyticsRate sets the global sampling rate for sampling APM events.
 func WithAnalyticsRate(rate float64) StartOption {
-	return func(_ *config) {
+	return func(c *config) {
+		if c.instance {
+			return
+		}
 		if rate >= 0.0 && rate <= 1.0 {
 			globalconfig.SetAnalyticsRate(rate)
 		} else {
@@ -1159,7 +1228,9 @@ func WithRuntimeMetrics() StartOption {
 func WithDogstatsdAddr(addr string) StartOption {
 	return func(cfg *config) {
 		cfg.dogstatsdAddr = addr
-		globalconfig.SetDogstatsdAddr(addr)
+		if !cfg.instance {
+			globalconfig.SetDogstatsdAddr(addr)
+		}
 	}
 }
 
@@ -1283,6 +1354,48 @@ func WithStatsComputation(enabled bool) StartOption {
 	}
 }
 
+// WithDataStreamsSink adds a sink receiving the Data Streams Monitoring stats flushed by the tracer, in
+// addition to the agent, e.g. a datastreams.FileSink or a datastreams.Inspector to verify the instrumentation
+// of a service locally. Data Streams Monitoring must be enabled by setting DD_DATA_STREAMS_ENABLED to true.
+func WithDataStreamsSink(sink datastreams.Sink) StartOption {
+	return func(c *config) {
+		c.dataStreamsSinks = append(c.dataStreamsSinks, sink)
+	}
+}
+
+// WithSpanProcessor adds a span processor called as spans start and finish, to enrich, redact or drop them
+// before they are exported. Span processors are called in the order they were added.
+func WithSpanProcessor(p SpanProcessor) StartOption {
+	return func(c *config) {
+		c.spanProcessors = append(c.spanProcessors, p)
+	}
+}
+
+// WithRedactionRules adds rules redacting sensitive data from the tags, the resource name, and the attributes
+// of the span events and span links of the spans before they are sent, in addition to the rules set with
+// DD_TRACE_REDACTION_RULES. See RedactionRule.
+func WithRedactionRules(rules ...RedactionRule) StartOption {
+	return func(c *config) {
+		c.redactionRules = append(c.redactionRules, rules...)
+	}
+}
+
+// WithIDGenerator sets the generator of the IDs of the spans and traces, including the upper 64 bits of
+// the 128-bit trace IDs. By default, the IDs are random. See IDGenerator.
+func WithIDGenerator(g IDGenerator) StartOption {
+	return func(c *config) {
+		c.idGenerator = g
+	}
+}
+
+// WithClock sets the clock providing the current time to the tracer. By default, the system clock is
+// used. See Clock.
+func WithClock(clock Clock) StartOption {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
+
 // Tag sets the given key/value pair as a tag on the started Span.
 func Tag(k string, v interface{}) StartSpanOption {
 	return func(cfg *StartSpanConfig) {
@@ -1407,8 +1520,8 @@ func WithStartSpanConfig(cfg *StartSpanConfig) StartSpanOption {
 // Special headers can not be sub-selected. E.g., an entire Cookie header would be transmitted, without the ability to choose specific Cookies.
 func WithHeaderTags(headerAsTags []string) StartOption {
 	return func(c *config) {
-		c.headerAsTags = newDynamicConfig("trace_header_tags", headerAsTags, setHeaderTags, equalSlice[string])
-		setHeaderTags(headerAsTags)
+		c.headerAsTags = newDynamicConfig("trace_header_tags", headerAsTags, c.headerTagsSetter(), equalSlice[string])
+		c.headerTagsSetter()(headerAsTags)
 	}
 }
 
@@ -1525,6 +1638,15 @@ func setHeaderTags(headerAsTags []string) bool {
 	return true
 }
 
+// headerTagsSetter returns the function applying the header tags of c: setHeaderTags, or a no-op for
+// the tracers created with New, which leave the global header tags alone.
+func (c *config) headerTagsSetter() func([]string) bool {
+	if c.instance {
+		return func([]string) bool { return true }
+	}
+	return setHeaderTags
+}
+
 // UserMonitoringConfig is used to configure what is used to identify a user.
 // This configuration can be set by combining one or several UserMonitoringOption with a call to SetUser().
 type UserMonitoringConfig struct {
@@ -57,6 +57,13 @@ type payload struct {
 
 	// reader is used for reading the contents of buf.
 	reader *bytes.Reader
+
+	// strings holds the string table of the payloads encoded with the v0.5
+	// protocol, see newPayloadV05. It's nil for the v0.4 protocol.
+	strings *stringTable
+
+	// scratch is reused to encode the traces of v0.5 payloads.
+	scratch []byte
 }
 
 var _ io.Reader = (*payload)(nil)
@@ -72,6 +79,10 @@ func newPayload() *payload {
 
 // push pushes a new item into the stream.
 func (p *payload) push(t spanList) error {
+	if p.strings != nil {
+		p.pushV05(t)
+		return nil
+	}
 	p.buf.Grow(t.Msgsize())
 	if err := msgp.Encode(&p.buf, t); err != nil {
 		return err
@@ -89,6 +100,9 @@ func (p *payload) itemCount() int {
 // size returns the payload size in bytes. After the first read the value becomes
 // inaccurate by up to 8 bytes.
 func (p *payload) size() int {
+	if p.strings != nil {
+		return p.sizeV05()
+	}
 	return p.buf.Len() + len(p.header) - p.off
 }
 
@@ -106,6 +120,10 @@ func (p *payload) reset() {
 func (p *payload) clear() {
 	p.buf = bytes.Buffer{}
 	p.reader = nil
+	if p.strings != nil {
+		p.strings = newStringTable()
+		p.header, p.scratch = nil, nil
+	}
 }
 
 // https://github.com/msgpack/msgpack/blob/master/spec.md#array-format-family
@@ -118,6 +136,10 @@ const (
 // updateHeader updates the payload header based on the number of items currently
 // present in the stream.
 func (p *payload) updateHeader() {
+	if p.strings != nil {
+		p.updateHeaderV05()
+		return
+	}
 	n := uint64(atomic.LoadUint32(&p.count))
 	switch {
 	case n <= 15:
@@ -141,6 +163,9 @@ func (p *payload) Close() error {
 
 // Read implements io.Reader. It reads from the msgpack-encoded stream.
 func (p *payload) Read(b []byte) (n int, err error) {
+	if p.strings != nil && len(p.header) == 0 {
+		p.updateHeaderV05()
+	}
 	if p.off < len(p.header) {
 		// reading header
 		n = copy(b, p.header[p.off:])
@@ -0,0 +1,193 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/log"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// Versions of the protocol used to send traces to the agent.
+const (
+	traceProtocolV04 = "v0.4"
+	traceProtocolV05 = "v0.5"
+)
+
+// negotiateTraceProtocol returns the version of the protocol used to send traces to the agent, given the
+// value of DD_TRACE_API_VERSION and the features of the agent. By default, the v0.5 protocol is used when the
+// agent supports it. DD_TRACE_API_VERSION=v0.4 opts out of it.
+func negotiateTraceProtocol(setting string, features agentFeatures) string {
+	switch strings.ToLower(strings.TrimSpace(setting)) {
+	case "":
+		if features.v05Available {
+			return traceProtocolV05
+		}
+		return traceProtocolV04
+	case traceProtocolV04:
+		return traceProtocolV04
+	case traceProtocolV05:
+		if !features.v05Available {
+			log.Warn("DD_TRACE_API_VERSION=v0.5 is not supported by the agent, using v0.4")
+			return traceProtocolV04
+		}
+		return traceProtocolV05
+	default:
+		log.Warn("DD_TRACE_API_VERSION=%s is not a valid value, expected v0.4 or v0.5", setting)
+		return negotiateTraceProtocol("", features)
+	}
+}
+
+// useTraceProtocol configures c to send traces with the v0.5 protocol when it was negotiated with the agent,
+// see negotiateTraceProtocol. The v0.5 protocol has neither the meta_struct nor the span_events fields, so the
+// features relying on them fall back to the meta of the spans. The traces which still hold a meta_struct, e.g.
+// once AppSec is enabled by remote configuration, are sent with the v0.4 protocol, see agentTraceWriter.add.
+func (c *config) useTraceProtocol(t *httpTransport) {
+	c.traceProtocol = negotiateTraceProtocol(os.Getenv("DD_TRACE_API_VERSION"), c.agent)
+	if c.traceProtocol != traceProtocolV05 {
+		return
+	}
+	c.agent.metaStructAvailable = false
+	c.agent.spanEventsAvailable = false
+	t.traceV05URL = strings.TrimSuffix(t.traceURL, "/v0.4/traces") + "/v0.5/traces"
+}
+
+// hasMetaStruct returns whether a span of the trace holds a meta_struct, which the v0.5 protocol can't encode.
+func hasMetaStruct(trace []*Span) bool {
+	for _, s := range trace {
+		if len(s.metaStruct) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// stringTable holds the strings of a v0.5 payload, referenced by the spans by their index.
+type stringTable struct {
+	indexes map[string]uint32
+	strings []string
+	// size holds the size of the encoded strings, in bytes.
+	size int
+}
+
+// newStringTable returns a string table holding the empty string at index 0, as required by the agent.
+func newStringTable() *stringTable {
+	t := &stringTable{indexes: make(map[string]uint32)}
+	t.index("")
+	return t
+}
+
+// index returns the index of s, adding it to the table if needed.
+func (t *stringTable) index(s string) uint32 {
+	if i, ok := t.indexes[s]; ok {
+		return i
+	}
+	i := uint32(len(t.strings))
+	t.indexes[s] = i
+	t.strings = append(t.strings, s)
+	t.size += stringPrefixSize(len(s)) + len(s)
+	return i
+}
+
+// stringPrefixSize returns the size of the msgpack header of a string of n bytes.
+func stringPrefixSize(n int) int {
+	switch {
+	case n < 32:
+		return 1
+	case n < 1<<8:
+		return 2
+	case n < 1<<16:
+		return 3
+	default:
+		return 5
+	}
+}
+
+// arrayHeaderSize returns the size of the msgpack header of an array of n items.
+func arrayHeaderSize(n int) int {
+	switch {
+	case n <= 15:
+		return 1
+	case n <= 1<<16-1:
+		return 3
+	default:
+		return 5
+	}
+}
+
+// newPayloadV05 returns a ready to use payload encoding the traces with the v0.5 protocol. The payload is an
+// array holding the string table followed by the traces, in which the strings of the spans are replaced by
+// their index in the string table:
+//
+//	[
+//		[string1, string2, ...],
+//		[
+//			[
+//				[service, name, resource, trace_id, span_id, parent_id, start, duration, error, meta, metrics, type],
+//				...
+//			],
+//			...
+//		]
+//	]
+//
+// The header of the payload holds the string table, and is only encoded once the payload is read.
+func newPayloadV05() *payload {
+	return &payload{strings: newStringTable()}
+}
+
+// pushV05 pushes a new trace into the stream of a v0.5 payload.
+func (p *payload) pushV05(t spanList) {
+	b := msgp.AppendArrayHeader(p.scratch[:0], uint32(len(t)))
+	for _, s := range t {
+		b = msgp.AppendArrayHeader(b, 12)
+		b = msgp.AppendUint32(b, p.strings.index(s.service))
+		b = msgp.AppendUint32(b, p.strings.index(s.name))
+		b = msgp.AppendUint32(b, p.strings.index(s.resource))
+		b = msgp.AppendUint64(b, s.traceID)
+		b = msgp.AppendUint64(b, s.spanID)
+		b = msgp.AppendUint64(b, s.parentID)
+		b = msgp.AppendInt64(b, s.start)
+		b = msgp.AppendInt64(b, s.duration)
+		b = msgp.AppendInt32(b, s.error)
+		b = msgp.AppendMapHeader(b, uint32(len(s.meta)))
+		for k, v := range s.meta {
+			b = msgp.AppendUint32(b, p.strings.index(k))
+			b = msgp.AppendUint32(b, p.strings.index(v))
+		}
+		b = msgp.AppendMapHeader(b, uint32(len(s.metrics)))
+		for k, v := range s.metrics {
+			b = msgp.AppendUint32(b, p.strings.index(k))
+			b = msgp.AppendFloat64(b, v)
+		}
+		b = msgp.AppendUint32(b, p.strings.index(s.spanType))
+	}
+	p.buf.Write(b)
+	p.scratch = b
+	atomic.AddUint32(&p.count, 1)
+	// The header is encoded again once the payload is read.
+	p.header = p.header[:0]
+}
+
+// sizeV05 returns the size in bytes of a v0.5 payload.
+func (p *payload) sizeV05() int {
+	return 1 + arrayHeaderSize(len(p.strings.strings)) + p.strings.size + arrayHeaderSize(p.itemCount()) + p.buf.Len()
+}
+
+// updateHeaderV05 encodes the header of a v0.5 payload, holding the string table and the header of the array
+// of traces.
+func (p *payload) updateHeaderV05() {
+	h := append(p.header[:0], msgpackArrayFix+2)
+	h = msgp.AppendArrayHeader(h, uint32(len(p.strings.strings)))
+	for _, s := range p.strings.strings {
+		h = msgp.AppendString(h, s)
+	}
+	p.header = msgp.AppendArrayHeader(h, uint32(p.itemCount()))
+	p.off = 0
+}
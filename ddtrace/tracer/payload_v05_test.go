@@ -0,0 +1,217 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/statsdtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// decodeV05 decodes a v0.5 payload the way the agent does.
+func decodeV05(r io.Reader) (spanLists, error) {
+	mr := msgp.NewReader(r)
+	if _, err := mr.ReadArrayHeader(); err != nil {
+		return nil, err
+	}
+	n, err := mr.ReadArrayHeader()
+	if err != nil {
+		return nil, err
+	}
+	dict := make([]string, n)
+	for i := range dict {
+		if dict[i], err = mr.ReadString(); err != nil {
+			return nil, err
+		}
+	}
+	str := func() string {
+		i, _ := mr.ReadUint32()
+		return dict[i]
+	}
+	ntraces, err := mr.ReadArrayHeader()
+	if err != nil {
+		return nil, err
+	}
+	traces := make(spanLists, ntraces)
+	for i := range traces {
+		nspans, err := mr.ReadArrayHeader()
+		if err != nil {
+			return nil, err
+		}
+		for j := uint32(0); j < nspans; j++ {
+			if _, err := mr.ReadArrayHeader(); err != nil {
+				return nil, err
+			}
+			s := &Span{meta: map[string]string{}, metrics: map[string]float64{}}
+			s.service, s.name, s.resource = str(), str(), str()
+			s.traceID, _ = mr.ReadUint64()
+			s.spanID, _ = mr.ReadUint64()
+			s.parentID, _ = mr.ReadUint64()
+			s.start, _ = mr.ReadInt64()
+			s.duration, _ = mr.ReadInt64()
+			s.error, _ = mr.ReadInt32()
+			nmeta, _ := mr.ReadMapHeader()
+			for k := uint32(0); k < nmeta; k++ {
+				key := str()
+				s.meta[key] = str()
+			}
+			nmetrics, _ := mr.ReadMapHeader()
+			for k := uint32(0); k < nmetrics; k++ {
+				key := str()
+				s.metrics[key], _ = mr.ReadFloat64()
+			}
+			s.spanType = str()
+			traces[i] = append(traces[i], s)
+		}
+	}
+	return traces, nil
+}
+
+func TestPayloadV05(t *testing.T) {
+	traces := getTestTrace(20, 10)
+	traces[0][0].meta["http.url"] = "https://example.com/path"
+	traces[0][0].error = 1
+	p := newPayloadV05()
+	for _, trace := range traces {
+		require.NoError(t, p.push(trace))
+	}
+	assert.Equal(t, 20, p.itemCount())
+
+	data, err := io.ReadAll(p)
+	require.NoError(t, err)
+	assert.Len(t, data, p.size())
+	got, err := decodeV05(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, got, len(traces))
+	for i, trace := range traces {
+		require.Len(t, got[i], len(trace))
+		for j, want := range trace {
+			s := got[i][j]
+			assert.Equal(t, want.service, s.service)
+			assert.Equal(t, want.name, s.name)
+			assert.Equal(t, want.resource, s.resource)
+			assert.Equal(t, want.spanType, s.spanType)
+			assert.Equal(t, want.traceID, s.traceID)
+			assert.Equal(t, want.spanID, s.spanID)
+			assert.Equal(t, want.parentID, s.parentID)
+			assert.Equal(t, want.start, s.start)
+			assert.Equal(t, want.duration, s.duration)
+			assert.Equal(t, want.error, s.error)
+			assert.Equal(t, want.meta, s.meta)
+			assert.Equal(t, want.metrics, s.metrics)
+		}
+	}
+
+	t.Run("reset", func(t *testing.T) {
+		p.reset()
+		again, err := io.ReadAll(p)
+		require.NoError(t, err)
+		assert.Equal(t, data, again)
+	})
+}
+
+func TestPayloadV05Size(t *testing.T) {
+	// The strings repeated across the spans are only encoded once.
+	traces := getTestTrace(10, 500)
+	v04, err := encode(traces)
+	require.NoError(t, err)
+	v05 := newPayloadV05()
+	for _, trace := range traces {
+		require.NoError(t, v05.push(trace))
+	}
+	assert.Less(t, v05.size(), v04.size()/2)
+}
+
+func TestNegotiateTraceProtocol(t *testing.T) {
+	v05 := agentFeatures{v05Available: true}
+	for _, tt := range []struct {
+		setting  string
+		features agentFeatures
+		want     string
+	}{
+		{"", agentFeatures{}, traceProtocolV04},
+		{"", v05, traceProtocolV05},
+		{"v0.4", v05, traceProtocolV04},
+		{"V0.5", v05, traceProtocolV05},
+		{"v0.5", agentFeatures{}, traceProtocolV04},
+		{"v1", v05, traceProtocolV05},
+	} {
+		assert.Equal(t, tt.want, negotiateTraceProtocol(tt.setting, tt.features), "%s %v", tt.setting, tt.features.v05Available)
+	}
+}
+
+func TestTraceProtocolV05(t *testing.T) {
+	var traces, v04Traces spanLists
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/info":
+			w.Write([]byte(`{"endpoints":["/v0.4/traces","/v0.5/traces"],"span_meta_structs":true,"span_events":true}`))
+		case "/v0.4/traces":
+			var got spanLists
+			assert.NoError(t, msgp.Decode(r.Body, &got))
+			v04Traces = append(v04Traces, got...)
+		case "/v0.5/traces":
+			got, err := decodeV05(r.Body)
+			assert.NoError(t, err)
+			traces = append(traces, got...)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("DD_TRACE_AGENT_URL", srv.URL)
+
+	t.Run("opt-out", func(t *testing.T) {
+		t.Setenv("DD_TRACE_API_VERSION", "v0.4")
+		c, err := newConfig()
+		require.NoError(t, err)
+		assert.Equal(t, traceProtocolV04, c.traceProtocol)
+		assert.Equal(t, srv.URL+"/v0.4/traces", c.transport.endpoint())
+		assert.True(t, c.agent.metaStructAvailable)
+	})
+
+	c, err := newConfig()
+	require.NoError(t, err)
+	assert.Equal(t, traceProtocolV05, c.traceProtocol)
+	assert.Equal(t, srv.URL+"/v0.5/traces", c.transport.endpoint())
+	// The fields v0.5 lacks aren't used.
+	assert.False(t, c.agent.metaStructAvailable)
+	assert.False(t, c.agent.spanEventsAvailable)
+
+	w := newAgentTraceWriter(c, newPrioritySampler(), &statsdtest.TestStatsdClient{})
+	s := newBasicSpan("request")
+	s.SetTag("key", "value")
+	w.add([]*Span{s})
+	w.stop()
+	require.Len(t, traces, 1)
+	assert.Equal(t, "request", traces[0][0].name)
+	assert.Equal(t, "value", traces[0][0].meta["key"])
+
+	t.Run("meta_struct", func(t *testing.T) {
+		// The v0.5 protocol lacks the meta_struct field, so the writer falls back to v0.4 instead of losing it.
+		traces, v04Traces = nil, nil
+		w := newAgentTraceWriter(c, newPrioritySampler(), &statsdtest.TestStatsdClient{})
+		w.add([]*Span{newBasicSpan("before")})
+		s := newBasicSpan("stack")
+		s.setMetaStruct("_dd.stack", map[string]any{"language": "go"})
+		w.add([]*Span{s})
+		w.add([]*Span{newBasicSpan("after")})
+		w.stop()
+		require.Len(t, traces, 1)
+		assert.Equal(t, "before", traces[0][0].name)
+		require.Len(t, v04Traces, 2)
+		assert.Equal(t, "stack", v04Traces[0][0].name)
+		assert.Equal(t, map[string]any{"language": "go"}, v04Traces[0][0].metaStruct["_dd.stack"])
+		assert.Equal(t, "after", v04Traces[1][0].name)
+		assert.Equal(t, srv.URL+"/v0.4/traces", c.transport.endpoint())
+	})
+}
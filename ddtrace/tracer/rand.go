@@ -8,8 +8,21 @@ package tracer
 import (
 	"math"
 	"math/rand/v2"
+	"time"
 )
 
+// IDGenerator generates the IDs of the spans and traces, e.g. to produce deterministic IDs in golden
+// file tests or when replaying traces. It's set with WithIDGenerator, and must be safe for concurrent use.
+type IDGenerator interface {
+	// SpanID returns the ID of a new span. The ID of a root span is also the lower 64 bits of the
+	// ID of its trace.
+	SpanID() uint64
+
+	// TraceIDUpper returns the upper 64 bits of the 128-bit ID of a new trace, of which the root span
+	// starts at start. It isn't called when DD_TRACE_128_BIT_TRACEID_GENERATION_ENABLED is false.
+	TraceIDUpper(start time.Time) uint64
+}
+
 func randUint64() uint64 {
 	return rand.Uint64()
 }
@@ -17,3 +30,12 @@ func randUint64() uint64 {
 func generateSpanID(_ int64) uint64 {
 	return rand.Uint64() & math.MaxInt64
 }
+
+// newSpanID returns the ID of a new span starting at start, from the ID generator set with
+// WithIDGenerator if any.
+func (c *config) newSpanID(start int64) uint64 {
+	if c.idGenerator == nil {
+		return generateSpanID(start)
+	}
+	return c.idGenerator.SpanID()
+}
@@ -0,0 +1,269 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
+	"github.com/DataDog/dd-trace-go/v2/internal/log"
+)
+
+// Built-in detectors of sensitive data, usable as RedactionRule.Detector.
+const (
+	// DetectorEmail detects email addresses.
+	DetectorEmail = "email"
+	// DetectorCreditCard detects credit card numbers, validated with the Luhn algorithm.
+	DetectorCreditCard = "credit_card"
+	// DetectorBearerToken detects the tokens of bearer authorization headers.
+	DetectorBearerToken = "bearer_token"
+	// DetectorJWT detects JSON Web Tokens.
+	DetectorJWT = "jwt"
+)
+
+// defaultRedactionReplacement replaces the sensitive data redacted.
+const defaultRedactionReplacement = "?"
+
+// RedactionRule is a rule redacting sensitive data from the tags, the resource name, and the attributes of the
+// span events and span links of the spans, before they are sent. Tags of which the key starts with "_dd." are
+// left as is. The rules are set with WithRedactionRules or with DD_TRACE_REDACTION_RULES as a JSON array, e.g.:
+//
+//	DD_TRACE_REDACTION_RULES='[{"name":"emails","detector":"email"},{"name":"session","key":"http.request.headers.cookie"}]'
+type RedactionRule struct {
+	// Name identifies the rule in the metrics counting the redactions.
+	Name string `json:"name"`
+	// Key is a glob matching the keys of the tags the rule applies to, e.g. "http.request.headers.*", as
+	// supported by path.Match. The resource name is matched as "resource.name". A rule without Pattern nor
+	// Detector redacts the whole value of the tags matching Key. An empty Key matches all the tags.
+	Key string `json:"key,omitempty"`
+	// Pattern is a regular expression matching the sensitive data in the values of the tags.
+	Pattern string `json:"pattern,omitempty"`
+	// Detector is a built-in detector of sensitive data, e.g. DetectorEmail.
+	Detector string `json:"detector,omitempty"`
+	// Replacement replaces the sensitive data, "?" by default.
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// redactionRule is a compiled RedactionRule.
+type redactionRule struct {
+	name string
+	key  string
+	// redact returns value with the sensitive data replaced, and whether it found any.
+	redact func(value string) (string, bool)
+}
+
+var (
+	emailRegexp       = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	creditCardRegexp  = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	bearerTokenRegexp = regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9\-._~+/]+=*`)
+	jwtRegexp         = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+)
+
+// newRedactionRule compiles r.
+func newRedactionRule(r RedactionRule) (redactionRule, error) {
+	replacement := r.Replacement
+	if replacement == "" {
+		replacement = defaultRedactionReplacement
+	}
+	if r.Key != "" {
+		if _, err := path.Match(r.Key, ""); err != nil {
+			return redactionRule{}, fmt.Errorf("invalid key %q: %w", r.Key, err)
+		}
+	}
+	rule := redactionRule{name: r.Name, key: r.Key}
+	switch {
+	case r.Pattern != "" && r.Detector != "":
+		return redactionRule{}, fmt.Errorf("rule %q has both a pattern and a detector", r.Name)
+	case r.Pattern != "":
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return redactionRule{}, fmt.Errorf("invalid pattern %q: %w", r.Pattern, err)
+		}
+		rule.redact = regexpRedacter(re, replacement)
+	case r.Detector == DetectorEmail:
+		rule.redact = regexpRedacter(emailRegexp, replacement)
+	case r.Detector == DetectorJWT:
+		rule.redact = regexpRedacter(jwtRegexp, replacement)
+	case r.Detector == DetectorBearerToken:
+		rule.redact = regexpRedacter(bearerTokenRegexp, "${1}"+strings.ReplaceAll(replacement, "$", "$$"))
+	case r.Detector == DetectorCreditCard:
+		rule.redact = func(value string) (string, bool) {
+			found := false
+			value = creditCardRegexp.ReplaceAllStringFunc(value, func(match string) string {
+				if !luhnValid(match) {
+					return match
+				}
+				found = true
+				return replacement
+			})
+			return value, found
+		}
+	case r.Detector != "":
+		return redactionRule{}, fmt.Errorf("unknown detector %q", r.Detector)
+	case r.Key == "":
+		return redactionRule{}, fmt.Errorf("rule %q has no key, pattern nor detector", r.Name)
+	default:
+		rule.redact = func(value string) (string, bool) {
+			return replacement, value != replacement
+		}
+	}
+	if rule.name == "" {
+		rule.name = r.Detector
+	}
+	return rule, nil
+}
+
+// regexpRedacter returns a function replacing the matches of re with replacement, which may reference the
+// submatches of re.
+func regexpRedacter(re *regexp.Regexp, replacement string) func(string) (string, bool) {
+	return func(value string) (string, bool) {
+		if !re.MatchString(value) {
+			return value, false
+		}
+		return re.ReplaceAllString(value, replacement), true
+	}
+}
+
+// luhnValid returns whether the digits of number pass the Luhn checksum.
+func luhnValid(number string) bool {
+	sum, n := 0, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+// redactor applies the redaction rules to the spans.
+type redactor struct {
+	rules []redactionRule
+	// count is called with the name of a rule each time it redacts a value.
+	count func(rule string)
+}
+
+// newRedactor compiles rules, ignoring the invalid ones. It returns nil if there's no valid rule.
+func newRedactor(rules []RedactionRule, count func(rule string)) *redactor {
+	r := &redactor{count: count}
+	for _, rule := range rules {
+		compiled, err := newRedactionRule(rule)
+		if err != nil {
+			log.Warn("Ignoring the redaction rule %q: %v", rule.Name, err)
+			continue
+		}
+		r.rules = append(r.rules, compiled)
+	}
+	if len(r.rules) == 0 {
+		return nil
+	}
+	return r
+}
+
+// redactionRulesFromEnv returns the redaction rules set with DD_TRACE_REDACTION_RULES.
+func redactionRulesFromEnv() []RedactionRule {
+	v := os.Getenv("DD_TRACE_REDACTION_RULES")
+	if v == "" {
+		return nil
+	}
+	var rules []RedactionRule
+	if err := json.Unmarshal([]byte(v), &rules); err != nil {
+		log.Warn("Ignoring DD_TRACE_REDACTION_RULES, it isn't a valid JSON array of rules: %v", err)
+		return nil
+	}
+	return rules
+}
+
+// redactValue applies the rules matching key to value.
+func (r *redactor) redactValue(key, value string) string {
+	if value == "" || strings.HasPrefix(key, "_dd.") {
+		return value
+	}
+	for _, rule := range r.rules {
+		if rule.key != "" {
+			if ok, _ := path.Match(rule.key, key); !ok {
+				continue
+			}
+		}
+		if redacted, ok := rule.redact(value); ok {
+			value = redacted
+			if r.count != nil {
+				r.count(rule.name)
+			}
+		}
+	}
+	return value
+}
+
+// redact applies the rules to the tags, the resource name, and the attributes of the span events and span
+// links of s. The span must be locked, and its events and links not serialized yet.
+func (r *redactor) redact(s *Span) {
+	for k, v := range s.meta {
+		if redacted := r.redactValue(k, v); redacted != v {
+			s.meta[k] = redacted
+		}
+	}
+	s.resource = r.redactValue(ext.ResourceName, s.resource)
+	for i, e := range s.spanEvents {
+		for k, attr := range e.Attributes {
+			if attr == nil {
+				continue
+			}
+			attr.StringValue = r.redactValue(k, attr.StringValue)
+			if attr.ArrayValue != nil {
+				for _, v := range attr.ArrayValue.Values {
+					if v != nil {
+						v.StringValue = r.redactValue(k, v.StringValue)
+					}
+				}
+			}
+		}
+		if len(e.RawAttributes) > 0 {
+			// The attributes are the map passed to AddEvent, which is left as is.
+			attrs := make(map[string]any, len(e.RawAttributes))
+			for k, v := range e.RawAttributes {
+				switch v := v.(type) {
+				case string:
+					attrs[k] = r.redactValue(k, v)
+				case []string:
+					redacted := make([]string, len(v))
+					for i, s := range v {
+						redacted[i] = r.redactValue(k, s)
+					}
+					attrs[k] = redacted
+				default:
+					attrs[k] = v
+				}
+			}
+			s.spanEvents[i].RawAttributes = attrs
+		}
+	}
+	for i, l := range s.spanLinks {
+		if len(l.Attributes) == 0 {
+			continue
+		}
+		// The attributes are the map passed with the link, which is left as is.
+		attrs := make(map[string]string, len(l.Attributes))
+		for k, v := range l.Attributes {
+			attrs[k] = r.redactValue(k, v)
+		}
+		s.spanLinks[i].Attributes = attrs
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"math"
 	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -37,10 +38,11 @@ type rulesSampler struct {
 // Rules are split between trace and single span sampling rules according to their type.
 // Such rules are user-defined through environment variable or WithSamplingRules option.
 // Invalid rules or environment variable values are tolerated, by logging warnings and then ignoring them.
-func newRulesSampler(traceRules, spanRules []SamplingRule, traceSampleRate, rateLimitPerSecond float64) *rulesSampler {
+// The rate limiters of the samplers are driven by clock, the system clock if nil.
+func newRulesSampler(traceRules, spanRules []SamplingRule, traceSampleRate, rateLimitPerSecond float64, clock Clock) *rulesSampler {
 	return &rulesSampler{
-		traces: newTraceRulesSampler(traceRules, traceSampleRate, rateLimitPerSecond),
-		spans:  newSingleSpanRulesSampler(spanRules),
+		traces: newTraceRulesSampler(traceRules, traceSampleRate, rateLimitPerSecond, clock),
+		spans:  newSingleSpanRulesSampler(spanRules, clock),
 	}
 }
 
@@ -308,7 +310,7 @@ func SpanSamplingRules(rules ...Rule) []SamplingRule {
 			Rate:         r.Rate,
 			ruleType:     SamplingRuleSpan,
 			MaxPerSecond: r.MaxPerSecond,
-			limiter:      newSingleSpanRateLimiter(r.MaxPerSecond),
+			limiter:      newSingleSpanRateLimiter(r.MaxPerSecond, nil),
 			globRule: &jsonRule{
 				Service:      r.ServiceGlob,
 				Name:         r.NameGlob,
@@ -351,15 +353,17 @@ type traceRulesSampler struct {
 	rules      []SamplingRule // the rules to match spans with
 	globalRate float64        // a rate to apply when no rules match a span
 	limiter    *rateLimiter   // used to limit the volume of spans sampled
+	clock      Clock          // drives the rate limiter, the system clock if nil
 }
 
 // newTraceRulesSampler configures a *traceRulesSampler instance using the given set of rules.
 // Invalid rules or environment variable values are tolerated, by logging warnings and then ignoring them.
-func newTraceRulesSampler(rules []SamplingRule, traceSampleRate, rateLimitPerSecond float64) *traceRulesSampler {
+func newTraceRulesSampler(rules []SamplingRule, traceSampleRate, rateLimitPerSecond float64, clock Clock) *traceRulesSampler {
 	return &traceRulesSampler{
 		rules:      rules,
 		globalRate: traceSampleRate,
-		limiter:    newRateLimiter(rateLimitPerSecond),
+		limiter:    newRateLimiter(rateLimitPerSecond, clock),
+		clock:      clock,
 	}
 }
 
@@ -440,7 +444,7 @@ func (rs *traceRulesSampler) sampleGlobalRate(span *Span) bool {
 	// being deprecated in favor of sampling rules.
 	// Note that this just preserves an existing behavior even though it is not correct.
 	sampler := samplernames.RuleRate
-	rs.applyRate(span, rate, time.Now(), sampler)
+	rs.applyRate(span, rate, clockTime(rs.clock), sampler)
 	return true
 }
 
@@ -476,7 +480,7 @@ func (rs *traceRulesSampler) sampleRules(span *Span) bool {
 		return false
 	}
 
-	rs.applyRate(span, rate, time.Now(), sampler)
+	rs.applyRate(span, rate, clockTime(rs.clock), sampler)
 	return true
 }
 
@@ -511,10 +515,10 @@ func (rs *traceRulesSampler) limit() (float64, bool) {
 
 // newRateLimiter returns a rate limiter which restricts the number of traces sampled per second.
 // The limit is DD_TRACE_RATE_LIMIT if set, `defaultRateLimit` otherwise.
-func newRateLimiter(ratePerSecond float64) *rateLimiter {
+func newRateLimiter(ratePerSecond float64, clock Clock) *rateLimiter {
 	return &rateLimiter{
 		limiter:  rate.NewLimiter(rate.Limit(ratePerSecond), int(math.Ceil(ratePerSecond))),
-		prevTime: time.Now(),
+		prevTime: clockTime(clock),
 	}
 }
 
@@ -533,13 +537,24 @@ func newRateLimiter(ratePerSecond float64) *rateLimiter {
 // Spans that matched the rules but exceeded the rate limit are not sampled.
 type singleSpanRulesSampler struct {
 	rules []SamplingRule // the rules to match spans with
+	clock Clock          // drives the rate limiters of the rules, the system clock if nil
 }
 
 // newSingleSpanRulesSampler configures a *singleSpanRulesSampler instance using the given set of rules.
 // Invalid rules or environment variable values are tolerated, by logging warnings and then ignoring them.
-func newSingleSpanRulesSampler(rules []SamplingRule) *singleSpanRulesSampler {
+func newSingleSpanRulesSampler(rules []SamplingRule, clock Clock) *singleSpanRulesSampler {
+	if clock != nil {
+		// The limiters of the rules are created with the rules, before the clock is known.
+		rules = slices.Clone(rules)
+		for i := range rules {
+			if rules[i].limiter != nil {
+				rules[i].limiter = newSingleSpanRateLimiter(rules[i].MaxPerSecond, clock)
+			}
+		}
+	}
 	return &singleSpanRulesSampler{
 		rules: rules,
+		clock: clock,
 	}
 }
 
@@ -560,7 +575,7 @@ func (rs *singleSpanRulesSampler) apply(span *Span) bool {
 			}
 			var sampled bool
 			if rule.limiter != nil {
-				sampled, rate = rule.limiter.allowOne(nowTime())
+				sampled, rate = rule.limiter.allowOne(clockTime(rs.clock))
 				if !sampled {
 					return false
 				}
@@ -624,14 +639,14 @@ func (r *rateLimiter) allowOne(now time.Time) (bool, float64) {
 
 // newSingleSpanRateLimiter returns a rate limiter which restricts the number of single spans sampled per second.
 // This defaults to infinite, allow all behaviour. The MaxPerSecond value of the rule may override the default.
-func newSingleSpanRateLimiter(mps float64) *rateLimiter {
+func newSingleSpanRateLimiter(mps float64, clock Clock) *rateLimiter {
 	limit := math.MaxFloat64
 	if mps > 0 {
 		limit = mps
 	}
 	return &rateLimiter{
 		limiter:  rate.NewLimiter(rate.Limit(limit), int(math.Ceil(limit))),
-		prevTime: time.Now(),
+		prevTime: clockTime(clock),
 	}
 }
 
@@ -806,7 +821,7 @@ func validateRules(jsonRules []jsonRule, spanType SamplingRuleType) ([]SamplingR
 			Tags:         tagGlobs,
 			Provenance:   v.Provenance,
 			ruleType:     spanType,
-			limiter:      newSingleSpanRateLimiter(v.MaxPerSecond),
+			limiter:      newSingleSpanRateLimiter(v.MaxPerSecond, nil),
 			globRule:     &jsonRules[i],
 		})
 	}
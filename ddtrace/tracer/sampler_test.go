@@ -261,7 +261,7 @@ func TestRuleEnvVars(t *testing.T) {
 			t.Setenv("DD_TRACE_RATE_LIMIT", tt.in)
 			c, err := newConfig()
 			assert.NoError(err)
-			res := newRateLimiter(c.traceRateLimitPerSecond)
+			res := newRateLimiter(c.traceRateLimitPerSecond, nil)
 			assert.Equal(tt.out, res.limiter)
 		}
 	})
@@ -494,7 +494,7 @@ func TestRulesSampler(t *testing.T) {
 		assert := assert.New(t)
 		c, err := newConfig()
 		assert.NoError(err)
-		rs := newRulesSampler(nil, nil, c.globalSampleRate, c.traceRateLimitPerSecond)
+		rs := newRulesSampler(nil, nil, c.globalSampleRate, c.traceRateLimitPerSecond, nil)
 
 		span := makeSpan("http.request", "test-service")
 		result := rs.SampleTrace(span)
@@ -561,7 +561,7 @@ func TestRulesSampler(t *testing.T) {
 				assert := assert.New(t)
 				c, err := newConfig()
 				assert.NoError(err)
-				rs := newRulesSampler(rules, nil, c.globalSampleRate, c.traceRateLimitPerSecond)
+				rs := newRulesSampler(rules, nil, c.globalSampleRate, c.traceRateLimitPerSecond, nil)
 
 				span := makeFinishedSpan(tt.spanName, tt.spanSrv, tt.spanRsc, tt.spanTags)
 
@@ -587,7 +587,7 @@ func TestRulesSampler(t *testing.T) {
 				assert := assert.New(t)
 				c, err := newConfig()
 				assert.NoError(err)
-				rs := newRulesSampler(v, nil, c.globalSampleRate, c.traceRateLimitPerSecond)
+				rs := newRulesSampler(v, nil, c.globalSampleRate, c.traceRateLimitPerSecond, nil)
 
 				span := makeSpan("http.request", "test-service")
 				result := rs.SampleTrace(span)
@@ -615,7 +615,7 @@ func TestRulesSampler(t *testing.T) {
 				assert := assert.New(t)
 				c, err := newConfig()
 				assert.NoError(err)
-				rs := newRulesSampler(v, nil, c.globalSampleRate, c.traceRateLimitPerSecond)
+				rs := newRulesSampler(v, nil, c.globalSampleRate, c.traceRateLimitPerSecond, nil)
 
 				span := makeSpan("http.request", "test-service")
 				result := rs.SampleTrace(span)
@@ -663,7 +663,7 @@ func TestRulesSampler(t *testing.T) {
 				assert := assert.New(t)
 				c, err := newConfig()
 				assert.NoError(err)
-				rs := newRulesSampler(nil, rules, c.globalSampleRate, c.traceRateLimitPerSecond)
+				rs := newRulesSampler(nil, rules, c.globalSampleRate, c.traceRateLimitPerSecond, nil)
 
 				span := makeFinishedSpan(tt.spanName, tt.spanSrv, "res-10", map[string]interface{}{"hostname": "hn-30"})
 
@@ -787,7 +787,7 @@ func TestRulesSampler(t *testing.T) {
 				assert := assert.New(t)
 				c, err := newConfig(WithSamplingRules(tt.rules))
 				assert.NoError(err)
-				rs := newRulesSampler(nil, c.spanRules, c.globalSampleRate, c.traceRateLimitPerSecond)
+				rs := newRulesSampler(nil, c.spanRules, c.globalSampleRate, c.traceRateLimitPerSecond, nil)
 
 				span := makeFinishedSpan(tt.spanName, tt.spanSrv, "res-10", map[string]interface{}{"hostname": "hn-30",
 					"tag":        20.1,
@@ -857,7 +857,7 @@ func TestRulesSampler(t *testing.T) {
 				assert := assert.New(t)
 				c, err := newConfig()
 				assert.NoError(err)
-				rs := newRulesSampler(nil, rules, c.globalSampleRate, c.traceRateLimitPerSecond)
+				rs := newRulesSampler(nil, rules, c.globalSampleRate, c.traceRateLimitPerSecond, nil)
 
 				span := makeFinishedSpan(tt.spanName, tt.spanSrv, tt.resName, map[string]interface{}{"hostname": "hn-30"})
 				result := rs.SampleSpan(span)
@@ -966,7 +966,7 @@ func TestRulesSampler(t *testing.T) {
 				assert := assert.New(t)
 				c, err := newConfig(WithSamplingRules(tt.rules))
 				assert.NoError(err)
-				rs := newRulesSampler(nil, c.spanRules, c.globalSampleRate, c.traceRateLimitPerSecond)
+				rs := newRulesSampler(nil, c.spanRules, c.globalSampleRate, c.traceRateLimitPerSecond, nil)
 
 				span := makeFinishedSpan(tt.spanName, tt.spanSrv, "res-10", map[string]interface{}{"hostname": "hn-30",
 					"tag": 20.1,
@@ -997,7 +997,7 @@ func TestRulesSampler(t *testing.T) {
 					t.Setenv("DD_TRACE_SAMPLE_RATE", fmt.Sprint(rate))
 					c, err := newConfig()
 					assert.NoError(err)
-					rs := newRulesSampler(nil, rules, c.globalSampleRate, c.traceRateLimitPerSecond)
+					rs := newRulesSampler(nil, rules, c.globalSampleRate, c.traceRateLimitPerSecond, nil)
 
 					span := makeSpan("http.request", "test-service")
 					result := rs.SampleTrace(span)
@@ -1285,7 +1285,7 @@ func TestRulesSamplerInternals(t *testing.T) {
 		now := time.Now()
 		c, err := newConfig()
 		assert.NoError(err)
-		rs := newRulesSampler(nil, nil, c.globalSampleRate, c.traceRateLimitPerSecond)
+		rs := newRulesSampler(nil, nil, c.globalSampleRate, c.traceRateLimitPerSecond, nil)
 		// set samplingLimiter to specific state
 		rs.traces.limiter.prevTime = now.Add(-1 * time.Second)
 		rs.traces.limiter.allowed = 1
@@ -1302,7 +1302,7 @@ func TestRulesSamplerInternals(t *testing.T) {
 		now := time.Now()
 		c, err := newConfig()
 		assert.NoError(err)
-		rs := newRulesSampler(nil, nil, c.globalSampleRate, c.traceRateLimitPerSecond)
+		rs := newRulesSampler(nil, nil, c.globalSampleRate, c.traceRateLimitPerSecond, nil)
 		// force sampling limiter to 1.0 spans/sec
 		rs.traces.limiter.limiter = rate.NewLimiter(rate.Limit(1.0), 1)
 		rs.traces.limiter.prevTime = now.Add(-1 * time.Second)
@@ -1325,7 +1325,7 @@ func TestRulesSamplerInternals(t *testing.T) {
 func TestSamplingLimiter(t *testing.T) {
 	t.Run("resets-every-second", func(t *testing.T) {
 		assert := assert.New(t)
-		sl := newRateLimiter(defaultRateLimit)
+		sl := newRateLimiter(defaultRateLimit, nil)
 		sl.prevSeen = 100
 		sl.prevAllowed = 99
 		sl.allowed = 42
@@ -1344,7 +1344,7 @@ func TestSamplingLimiter(t *testing.T) {
 
 	t.Run("averages-rates", func(t *testing.T) {
 		assert := assert.New(t)
-		sl := newRateLimiter(defaultRateLimit)
+		sl := newRateLimiter(defaultRateLimit, nil)
 		sl.prevSeen = 100
 		sl.prevAllowed = 42
 		sl.allowed = 41
@@ -1362,7 +1362,7 @@ func TestSamplingLimiter(t *testing.T) {
 
 	t.Run("discards-rate", func(t *testing.T) {
 		assert := assert.New(t)
-		sl := newRateLimiter(defaultRateLimit)
+		sl := newRateLimiter(defaultRateLimit, nil)
 		sl.prevSeen = 100
 		sl.prevAllowed = 42
 		sl.allowed = 42
@@ -1624,7 +1624,7 @@ func BenchmarkGlobMatchSpan(b *testing.B) {
 		b.Setenv("DD_SPAN_SAMPLING_RULES", `[{"service": "srv.name.ops.date", "name": "name.ops.date?", "sample_rate": 0.234}]`)
 		_, rules, err := samplingRulesFromEnv()
 		assert.Nil(b, err)
-		rs := newSingleSpanRulesSampler(rules)
+		rs := newSingleSpanRulesSampler(rules, nil)
 		b.ResetTimer()
 		for n := 0; n < b.N; n++ {
 			for _, span := range spans {
@@ -1637,7 +1637,7 @@ func BenchmarkGlobMatchSpan(b *testing.B) {
 		b.Setenv("DD_SPAN_SAMPLING_RULES", `[{"service": "srv?name?ops?date", "name": "name*ops*date*", "sample_rate": 0.234}]`)
 		_, rules, err := samplingRulesFromEnv()
 		assert.Nil(b, err)
-		rs := newSingleSpanRulesSampler(rules)
+		rs := newSingleSpanRulesSampler(rules, nil)
 		b.ResetTimer()
 		for n := 0; n < b.N; n++ {
 			for _, span := range spans {
@@ -1651,7 +1651,7 @@ func BenchmarkGlobMatchSpan(b *testing.B) {
 
 		_, rules, err := samplingRulesFromEnv()
 		assert.Nil(b, err)
-		rs := newSingleSpanRulesSampler(rules)
+		rs := newSingleSpanRulesSampler(rules, nil)
 
 		b.ResetTimer()
 		for n := 0; n < b.N; n++ {
@@ -1663,7 +1663,7 @@ func BenchmarkGlobMatchSpan(b *testing.B) {
 }
 
 func TestSetGlobalSampleRate(t *testing.T) {
-	rs := newTraceRulesSampler(nil, math.NaN(), defaultRateLimit)
+	rs := newTraceRulesSampler(nil, math.NaN(), defaultRateLimit, nil)
 	assert.True(t, math.IsNaN(rs.globalRate))
 
 	// Comparing NaN values
@@ -136,6 +136,8 @@ type Span struct {
 	context        *SpanContext `msg:"-"` // span propagation context
 	integration    string       `msg:"-"` // where the span was started from, such as a specific contrib or "manual"
 	supportsEvents bool         `msg:"-"` // whether the span supports native span events or not
+	dropped        bool         `msg:"-"` // true if the span was dropped by a span processor
+	tracer         *tracer      `msg:"-"` // the tracer created with New which started the span, nil for the global tracer
 
 	pprofCtxActive  context.Context `msg:"-"` // contains pprof.WithLabel labels to tell the profiler more about this span
 	pprofCtxRestore context.Context `msg:"-"` // contains pprof.WithLabel labels of the parent span (if any) that need to be restored when this span finishes
@@ -286,6 +288,24 @@ func (s *Span) SetTag(key string, value interface{}) {
 	s.setMeta(key, fmt.Sprint(value))
 }
 
+// RemoveTag removes the tag with the given key from the span.
+func (s *Span) RemoveTag(key string) {
+	if s == nil {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	// We don't lock spans when flushing, so we could have a data race when
+	// modifying a span as it's being flushed. This protects us against that
+	// race, since spans are marked `finished` before we flush them.
+	if s.finished {
+		return
+	}
+	delete(s.meta, key)
+	delete(s.metrics, key)
+	delete(s.metaStruct, key)
+}
+
 // setSamplingPriority locks the span, then updates the sampling priority.
 // It also updates the trace's sampling priority.
 func (s *Span) setSamplingPriority(priority int, sampler samplernames.SamplerName) {
@@ -375,7 +395,16 @@ func (s *Span) StartChild(operationName string, opts ...StartSpanOption) *Span {
 		return nil
 	}
 	opts = append(opts, ChildOf(s.Context()))
-	return GetGlobalTracer().StartSpan(operationName, opts...)
+	return s.spanTracer().StartSpan(operationName, opts...)
+}
+
+// spanTracer returns the tracer which the span belongs to: the tracer created with New
+// which started it, or the global tracer.
+func (s *Span) spanTracer() Tracer {
+	if s.tracer != nil {
+		return s.tracer
+	}
+	return GetGlobalTracer()
 }
 
 // setSamplingPriorityLocked updates the sampling priority.
@@ -612,7 +641,12 @@ func (s *Span) Finish(opts ...FinishOption) {
 	if s == nil {
 		return
 	}
-	t := now()
+	var t int64
+	if tr, ok := s.spanTracer().(*tracer); ok {
+		t = tr.config.now()
+	} else {
+		t = now()
+	}
 	if len(opts) > 0 {
 		cfg := FinishConfig{
 			NoDebugStack: s.noDebugStack,
@@ -656,8 +690,12 @@ func (s *Span) Finish(opts ...FinishOption) {
 		s.SetTag("go_execution_traced", "partial")
 	}
 
+	if tr, ok := s.spanTracer().(*tracer); ok && len(tr.config.spanProcessors) > 0 {
+		tr.processFinishedSpan(s)
+	}
+
 	if s.Root() == s {
-		if tr, ok := GetGlobalTracer().(*tracer); ok && tr.rulesSampling.traces.enabled() {
+		if tr, ok := s.spanTracer().(*tracer); ok && tr.rulesSampling.traces.enabled() {
 			if !s.context.trace.isLocked() && s.context.trace.propagatingTag(keyDecisionMaker) != "-4" {
 				tr.rulesSampling.SampleTrace(s)
 			}
@@ -696,6 +734,9 @@ func (s *Span) finish(finishTime int64) {
 		return
 	}
 
+	if t, ok := s.spanTracer().(*tracer); ok && t.redactor != nil {
+		t.redactor.redact(s)
+	}
 	s.serializeSpanLinksInMeta()
 	s.serializeSpanEvents()
 
@@ -708,13 +749,24 @@ func (s *Span) finish(finishTime int64) {
 	if s.taskEnd != nil {
 		s.taskEnd()
 	}
+	if hook := traceprof.SpanFinishHook(); hook != nil {
+		hook(traceprof.FinishedSpan{
+			Service:  s.service,
+			Resource: s.resource,
+			TraceID:  s.context.TraceID(),
+			SpanID:   s.spanID,
+			Duration: time.Duration(s.duration),
+		})
+	}
 
 	keep := true
-	if t, ok := GetGlobalTracer().(*tracer); ok {
+	if t, ok := s.spanTracer().(*tracer); ok {
 		if !t.config.enabled.current {
 			return
 		}
-		t.Submit(s)
+		if !s.dropped {
+			t.Submit(s)
+		}
 		if t.config.canDropP0s() {
 			// the agent supports dropping p0's in the client
 			keep = shouldKeep(s)
@@ -725,7 +777,7 @@ func (s *Span) finish(finishTime int64) {
 		}
 		t.spansFinished.Inc(s.integration)
 	}
-	if keep {
+	if keep && !s.dropped {
 		// a single kept span keeps the whole trace.
 		s.context.trace.keep()
 	}
@@ -837,10 +889,15 @@ func (s *Span) Format(f fmt.State, c rune) {
 	case 's':
 		fmt.Fprint(f, s.String())
 	case 'v':
-		if svc := globalconfig.ServiceName(); svc != "" {
+		svc := globalconfig.ServiceName()
+		if s.tracer != nil {
+			// The spans of the tracers created with New are correlated with their own service.
+			svc = s.tracer.config.serviceName
+		}
+		if svc != "" {
 			fmt.Fprintf(f, "dd.service=%s ", svc)
 		}
-		if tr := GetGlobalTracer(); tr != nil {
+		if tr := s.spanTracer(); tr != nil {
 			tc := tr.TracerConf()
 			if tc.EnvTag != "" {
 				fmt.Fprintf(f, "dd.env=%s ", tc.EnvTag)
@@ -879,7 +936,11 @@ func (s *Span) AddEvent(name string, opts ...SpanEventOption) {
 		opt(&cfg)
 	}
 	if cfg.Time.IsZero() {
-		cfg.Time = time.Now()
+		if tr, ok := s.spanTracer().(*tracer); ok {
+			cfg.Time = tr.config.nowTime()
+		} else {
+			cfg.Time = nowTime()
+		}
 	}
 	event := spanEvent{
 		Name:         name,
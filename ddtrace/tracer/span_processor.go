@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/log"
+)
+
+// SpanProcessor is called by the tracer as spans start and finish, to enrich, redact or drop them before they
+// are exported. Span processors are added with WithSpanProcessor, and are called in the order they were added.
+// A panic in a span processor is recovered and logged, and the span or trace is then left as is.
+type SpanProcessor interface {
+	// OnStart is called when a span is started, once the tags set by the tracer were added.
+	OnStart(s *Span)
+
+	// OnFinish is called when a span is finished, before the stats of the span are computed, so it can
+	// still be modified, e.g. to add or remove tags or to rename its resource. Returning false drops the
+	// span: it isn't counted in the stats computed by the tracer nor exported.
+	OnFinish(s *Span) bool
+
+	// OnTraceComplete is called with the spans of a trace chunk once they're all finished, i.e. the spans
+	// of the whole local trace, or of a part of it if partial flushing is enabled. It returns the spans
+	// to export. The spans can't be modified anymore, and the spans dropped are still counted in the
+	// stats computed by the tracer.
+	OnTraceComplete(spans []*Span) []*Span
+}
+
+// spanProcessorStats holds the metrics of the span processors, reported as health metrics.
+type spanProcessorStats struct {
+	// duration holds the time spent in the span processors, in nanoseconds.
+	duration atomic.Int64
+	panics   atomic.Int64
+	dropped  atomic.Int64
+}
+
+// processStartedSpan calls the span processors when s starts.
+func (t *tracer) processStartedSpan(s *Span) {
+	start := time.Now()
+	defer func() { t.spanProcessorStats.duration.Add(int64(time.Since(start))) }()
+	for _, p := range t.config.spanProcessors {
+		t.safeProcess(func() { p.OnStart(s) })
+	}
+}
+
+// processFinishedSpan calls the span processors when s finishes, and marks s as dropped if any of them
+// drops it.
+func (t *tracer) processFinishedSpan(s *Span) {
+	s.RLock()
+	finished := s.finished
+	s.RUnlock()
+	if finished {
+		return
+	}
+	start := time.Now()
+	defer func() { t.spanProcessorStats.duration.Add(int64(time.Since(start))) }()
+	for _, p := range t.config.spanProcessors {
+		keep := true
+		t.safeProcess(func() { keep = p.OnFinish(s) })
+		if !keep {
+			s.Lock()
+			s.dropped = true
+			s.Unlock()
+			return
+		}
+	}
+}
+
+// processChunk removes the spans dropped when they finished from c, and calls the span processors with the
+// spans left.
+func (t *tracer) processChunk(c *Chunk) {
+	if len(t.config.spanProcessors) == 0 || len(c.spans) == 0 {
+		return
+	}
+	start := time.Now()
+	defer func() { t.spanProcessorStats.duration.Add(int64(time.Since(start))) }()
+	first := c.spans[0]
+	spans := make([]*Span, 0, len(c.spans))
+	for _, s := range c.spans {
+		if !s.dropped {
+			spans = append(spans, s)
+		}
+	}
+	for _, p := range t.config.spanProcessors {
+		if len(spans) == 0 {
+			break
+		}
+		processed := spans
+		t.safeProcess(func() { processed = p.OnTraceComplete(spans) })
+		spans = processed
+	}
+	t.spanProcessorStats.dropped.Add(int64(len(c.spans) - len(spans)))
+	if len(spans) > 0 && spans[0] != first {
+		// The trace-level tags are set on the first span of a chunk.
+		moveTraceTags(first, spans[0])
+	}
+	c.spans = spans
+}
+
+// moveTraceTags sets the trace-level tags of the chunk held by from on to.
+func moveTraceTags(from, to *Span) {
+	if priority, ok := from.metrics[keySamplingPriority]; ok {
+		to.setMetric(keySamplingPriority, priority)
+	}
+	trace := to.context.trace
+	trace.mu.RLock()
+	defer trace.mu.RUnlock()
+	trace.setTraceTags(to)
+}
+
+// safeProcess calls fn, recovering from its panics.
+func (t *tracer) safeProcess(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.spanProcessorStats.panics.Add(1)
+			log.Error("Span processor panicked: %v", r)
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"testing"
+
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSpanProcessor struct {
+	onStart         func(*Span)
+	onFinish        func(*Span) bool
+	onTraceComplete func([]*Span) []*Span
+}
+
+func (p *testSpanProcessor) OnStart(s *Span) {
+	if p.onStart != nil {
+		p.onStart(s)
+	}
+}
+
+func (p *testSpanProcessor) OnFinish(s *Span) bool {
+	if p.onFinish != nil {
+		return p.onFinish(s)
+	}
+	return true
+}
+
+func (p *testSpanProcessor) OnTraceComplete(spans []*Span) []*Span {
+	if p.onTraceComplete != nil {
+		return p.onTraceComplete(spans)
+	}
+	return spans
+}
+
+func TestSpanProcessor(t *testing.T) {
+	t.Run("modify", func(t *testing.T) {
+		tracer, transport, flush, stop, err := startTestTracer(t, WithSpanProcessor(&testSpanProcessor{
+			onStart: func(s *Span) {
+				s.SetTag("team", "payments")
+			},
+			onFinish: func(s *Span) bool {
+				s.RemoveTag("user.email")
+				s.SetTag(ext.ResourceName, "GET /users/?")
+				return true
+			},
+		}))
+		require.NoError(t, err)
+		defer stop()
+
+		span := tracer.StartSpan("http.request", ResourceName("GET /users/1"), Tag("user.email", "user@example.com"))
+		span.Finish()
+		flush(1)
+
+		traces := transport.Traces()
+		require.Len(t, traces, 1)
+		require.Len(t, traces[0], 1)
+		got := traces[0][0]
+		assert.Equal(t, "payments", got.meta["team"])
+		assert.NotContains(t, got.meta, "user.email")
+		assert.Equal(t, "GET /users/?", got.resource)
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		tracer, transport, flush, stop, err := startTestTracer(t,
+			WithSpanProcessor(&testSpanProcessor{
+				onFinish: func(s *Span) bool {
+					return s.name != "cache.get"
+				},
+			}),
+			WithSpanProcessor(&testSpanProcessor{
+				onTraceComplete: func(spans []*Span) []*Span {
+					if spans[0].resource == "GET /health" {
+						return nil
+					}
+					return spans
+				},
+			}))
+		require.NoError(t, err)
+		defer stop()
+
+		tracer.StartSpan("http.request", ResourceName("GET /health")).Finish()
+		root := tracer.StartSpan("http.request", ResourceName("GET /users"))
+		tracer.StartSpan("cache.get", ChildOf(root.Context())).Finish()
+		tracer.StartSpan("db.query", ChildOf(root.Context())).Finish()
+		root.Finish()
+		flush(1)
+
+		traces := transport.Traces()
+		require.Len(t, traces, 1)
+		require.Len(t, traces[0], 2)
+		assert.Equal(t, "http.request", traces[0][0].name)
+		assert.Equal(t, "db.query", traces[0][1].name)
+		assert.Equal(t, int64(2), tracer.spanProcessorStats.dropped.Load())
+	})
+
+	t.Run("drop-first", func(t *testing.T) {
+		tracer, transport, flush, stop, err := startTestTracer(t, WithSpanProcessor(&testSpanProcessor{
+			onFinish: func(s *Span) bool {
+				return s.name != "http.request"
+			},
+		}))
+		require.NoError(t, err)
+		defer stop()
+
+		root := tracer.StartSpan("http.request")
+		tracer.StartSpan("db.query", ChildOf(root.Context())).Finish()
+		root.Finish()
+		flush(1)
+
+		traces := transport.Traces()
+		require.Len(t, traces, 1)
+		require.Len(t, traces[0], 1)
+		assert.Equal(t, "db.query", traces[0][0].name)
+		assert.Contains(t, traces[0][0].metrics, keySamplingPriority)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		tracer, transport, flush, stop, err := startTestTracer(t, WithSpanProcessor(&testSpanProcessor{
+			onStart:         func(*Span) { panic("start") },
+			onFinish:        func(*Span) bool { panic("finish") },
+			onTraceComplete: func([]*Span) []*Span { panic("trace complete") },
+		}))
+		require.NoError(t, err)
+		defer stop()
+
+		tracer.StartSpan("http.request").Finish()
+		flush(1)
+
+		traces := transport.Traces()
+		require.Len(t, traces, 1)
+		require.Len(t, traces[0], 1)
+		assert.Equal(t, int64(3), tracer.spanProcessorStats.panics.Load())
+	})
+}
@@ -149,6 +149,11 @@ func FromGenericCtx(c ddtrace.SpanContext) *SpanContext {
 	return &sc
 }
 
+// traceID128Enabled reports whether the IDs of the new traces are 128-bit.
+func traceID128Enabled() bool {
+	return sharedinternal.BoolEnv("DD_TRACE_128_BIT_TRACEID_GENERATION_ENABLED", true)
+}
+
 // newSpanContext creates a new SpanContext to serve as context for the given
 // span. If the provided parent is not nil, the context will inherit the trace,
 // baggage and other values from it. This method also pushes the span into the
@@ -170,7 +175,7 @@ func newSpanContext(span *Span, parent *SpanContext) *SpanContext {
 			context.setBaggageItem(k, v)
 			return true
 		})
-	} else if sharedinternal.BoolEnv("DD_TRACE_128_BIT_TRACEID_GENERATION_ENABLED", true) {
+	} else if traceID128Enabled() {
 		// add 128 bit trace id, if enabled, formatted as big-endian:
 		// <32-bit unix seconds> <32 bits of zero> <64 random bits>
 		id128 := time.Duration(span.start) / time.Second
@@ -453,7 +458,7 @@ func (t *trace) push(sp *Span) {
 	if t.full {
 		return
 	}
-	tr := GetGlobalTracer()
+	tr := sp.spanTracer()
 	if len(t.spans) >= traceMaxSize {
 		// capacity is reached, we will not be able to complete this trace.
 		t.full = true
@@ -509,7 +514,7 @@ func (t *trace) finishedOne(s *Span) {
 		return
 	}
 	t.finished++
-	tr := GetGlobalTracer()
+	tr := s.spanTracer()
 	if tr == nil {
 		return
 	}
@@ -66,7 +66,17 @@ type SQLCommentCarrier struct {
 
 // Inject injects a span context in the carrier's Query field as a comment.
 func (c *SQLCommentCarrier) Inject(ctx *SpanContext) error {
-	c.SpanID = generateSpanID(now())
+	// The span ID is generated by the tracer starting the span: the tracer of the parent span, or the
+	// global tracer for the contexts without a span, such as the extracted ones.
+	tr := GetGlobalTracer()
+	if ctx != nil && ctx.span != nil {
+		tr = ctx.span.spanTracer()
+	}
+	if t, ok := tr.(*tracer); ok {
+		c.SpanID = t.config.newSpanID(now())
+	} else {
+		c.SpanID = generateSpanID(now())
+	}
 	tags := make(map[string]string)
 	switch c.Mode {
 	case DBMPropagationModeUndefined:
@@ -240,6 +240,29 @@ func TestSQLCommentCarrierInjectNilSpan(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestSQLCommentCarrierInjectIDGenerator(t *testing.T) {
+	tracer, _, _, stop, err := startTestTracer(t, WithIDGenerator(&sequentialIDGenerator{next: 41}))
+	require.NoError(t, err)
+	defer stop()
+
+	span := tracer.StartSpan("parent")
+	defer span.Finish()
+	carrier := SQLCommentCarrier{Query: "SELECT * from FOO", Mode: DBMPropagationModeFull}
+	require.NoError(t, carrier.Inject(span.Context()))
+	assert.Equal(t, uint64(43), carrier.SpanID)
+
+	// The contexts without a span, such as the extracted ones, use the same generator.
+	spanCtx, err := tracer.Extract(TextMapCarrier(map[string]string{
+		DefaultTraceIDHeader:  "4",
+		DefaultParentIDHeader: "1",
+	}))
+	require.NoError(t, err)
+	carrier = SQLCommentCarrier{Query: "SELECT * from FOO", Mode: DBMPropagationModeFull}
+	require.NoError(t, carrier.Inject(spanCtx))
+	assert.Equal(t, uint64(44), carrier.SpanID)
+	assert.Contains(t, carrier.Query, "traceparent='00-00000000000000000000000000000004-000000000000002c-")
+}
+
 func TestExtractOpenTelemetryTraceInformation(t *testing.T) {
 	// open-telemetry supports 128 bit trace ids
 	traceID := "5bd66ef5095369c7b0d1f8f4bd33716a"
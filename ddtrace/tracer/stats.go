@@ -87,7 +87,7 @@ func newConcentrator(c *config, bucketSize int64, statsdClient internal.StatsdCl
 		GitCommitSha: gitCommitSha,
 		ImageTag:     "",
 	}
-	spanConcentrator := stats.NewSpanConcentrator(sCfg, time.Now())
+	spanConcentrator := stats.NewSpanConcentrator(sCfg, c.nowTime())
 	return &concentrator{
 		In:               make(chan *tracerStatSpan, 10000),
 		bucketSize:       bucketSize,
@@ -130,8 +130,8 @@ func (c *concentrator) Start() {
 func (c *concentrator) runFlusher(tick <-chan time.Time) {
 	for {
 		select {
-		case now := <-tick:
-			c.flushAndSend(now, withoutCurrentBucket)
+		case <-tick:
+			c.flushAndSend(c.cfg.nowTime(), withoutCurrentBucket)
 		case <-c.stop:
 			return
 		}
@@ -204,7 +204,7 @@ drain:
 			break drain
 		}
 	}
-	c.flushAndSend(time.Now(), withCurrentBucket)
+	c.flushAndSend(c.cfg.nowTime(), withCurrentBucket)
 }
 
 const (
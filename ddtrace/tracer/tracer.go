@@ -128,6 +128,14 @@ type tracer struct {
 	// each component, including contribs and "manual" spans.
 	spansStarted, spansFinished globalinternal.XSyncMapCounterMap
 
+	// spanProcessorStats holds the metrics of the span processors.
+	spanProcessorStats spanProcessorStats
+
+	// redactor redacts the sensitive data from the spans. It's nil if no redaction rule is set.
+	redactor *redactor
+	// redactions counts the values redacted, by rule.
+	redactions globalinternal.XSyncMapCounterMap
+
 	// Keeps track of the total number of traces dropped for accurate logging.
 	totalTracesDropped uint32
 
@@ -163,6 +171,10 @@ type tracer struct {
 	// logFile is closed when tracer stops
 	// by default, tracer logs to stderr and this setting is unused
 	logFile *log.ManagedFile
+
+	// instance reports whether the tracer was created with New. Its spans are bound to it
+	// rather than to the global tracer, and it leaves the process-wide features alone.
+	instance bool
 }
 
 const (
@@ -286,22 +298,51 @@ func Stop() {
 }
 
 // StartSpan starts a new span with the given operation name and set of options.
+// The span is started by the tracer of its parent, if any, or by the global tracer.
 // If the tracer is not started, calling this function is a no-op.
 func StartSpan(operationName string, opts ...StartSpanOption) *Span {
-	return GetGlobalTracer().StartSpan(operationName, opts...)
+	return parentTracer(opts).StartSpan(operationName, opts...)
+}
+
+// parentTracer returns the tracer of the local parent set by the start options, such as
+// a tracer created with New, or the global tracer.
+func parentTracer(opts []StartSpanOption) Tracer {
+	var cfg StartSpanConfig
+	for _, fn := range opts {
+		fn(&cfg)
+	}
+	if cfg.Parent != nil && cfg.Parent.span != nil {
+		return cfg.Parent.span.spanTracer()
+	}
+	return GetGlobalTracer()
 }
 
 // Extract extracts a SpanContext from the carrier. The carrier is expected
 // to implement TextMapReader, otherwise an error is returned.
 // If the tracer is not started, calling this function is a no-op.
+// The SpanContext is extracted by the global tracer, see ExtractFromContext
+// for the tracers created with New.
 func Extract(carrier interface{}) (*SpanContext, error) {
 	return GetGlobalTracer().Extract(carrier)
 }
 
+// ExtractFromContext extracts a SpanContext from the carrier with the tracer
+// returned by TracerFromContext for ctx, e.g. the context of an incoming
+// request. The carrier is expected to implement TextMapReader, otherwise an
+// error is returned.
+func ExtractFromContext(ctx gocontext.Context, carrier interface{}) (*SpanContext, error) {
+	return TracerFromContext(ctx).Extract(carrier)
+}
+
 // Inject injects the given SpanContext into the carrier. The carrier is
 // expected to implement TextMapWriter, otherwise an error is returned.
-// If the tracer is not started, calling this function is a no-op.
+// The SpanContext is injected by the tracer which started its span, or by
+// the global tracer. If the tracer is not started, calling this function is
+// a no-op.
 func Inject(ctx *SpanContext, carrier interface{}) error {
+	if ctx != nil && ctx.span != nil {
+		return ctx.span.spanTracer().Inject(ctx, carrier)
+	}
 	return GetGlobalTracer().Inject(ctx, carrier)
 }
 
@@ -330,6 +371,11 @@ func newUnstartedTracer(opts ...StartOption) (*tracer, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newUnstartedTracerWithConfig(c)
+}
+
+// newUnstartedTracerWithConfig returns a new tracer with the configuration c, without starting it.
+func newUnstartedTracerWithConfig(c *config) (*tracer, error) {
 	sampler := newPrioritySampler()
 	statsd, err := newStatsdClient(c)
 	if err != nil {
@@ -356,7 +402,7 @@ func newUnstartedTracer(opts ...StartOption) (*tracer, error) {
 		c.spanRules = spans
 	}
 
-	rulesSampler := newRulesSampler(c.traceRules, c.spanRules, c.globalSampleRate, c.traceRateLimitPerSecond)
+	rulesSampler := newRulesSampler(c.traceRules, c.spanRules, c.globalSampleRate, c.traceRateLimitPerSecond, c.clock)
 	c.traceSampleRate = newDynamicConfig("trace_sample_rate", c.globalSampleRate, rulesSampler.traces.setGlobalSampleRate, equal[float64])
 	// If globalSampleRate returns NaN, it means the environment variable was not set or valid.
 	// We could always set the origin to "env_var" inconditionally, but then it wouldn't be possible
@@ -370,9 +416,12 @@ func newUnstartedTracer(opts ...StartOption) (*tracer, error) {
 	var dataStreamsProcessor *datastreams.Processor
 	if c.dataStreamsMonitoringEnabled {
 		dataStreamsProcessor = datastreams.NewProcessor(statsd, c.env, c.serviceName, c.version, c.agentURL, c.httpClient)
+		for _, sink := range c.dataStreamsSinks {
+			dataStreamsProcessor.AddSink(sink)
+		}
 	}
 	var logFile *log.ManagedFile
-	if v := c.logDirectory; v != "" {
+	if v := c.logDirectory; v != "" && !c.instance {
 		logFile, err = log.OpenFileAtPath(v)
 		if err != nil {
 			log.Warn("%v", err)
@@ -392,6 +441,7 @@ func newUnstartedTracer(opts ...StartOption) (*tracer, error) {
 		stats:            newConcentrator(c, defaultStatsBucketSize, statsd),
 		spansStarted:     *globalinternal.NewXSyncMapCounterMap(),
 		spansFinished:    *globalinternal.NewXSyncMapCounterMap(),
+		redactions:       *globalinternal.NewXSyncMapCounterMap(),
 		obfuscator: obfuscate.NewObfuscator(obfuscate.Config{
 			SQL: obfuscate.SQLConfig{
 				TableNames:       c.agent.HasFlag("table_names"),
@@ -404,6 +454,40 @@ func newUnstartedTracer(opts ...StartOption) (*tracer, error) {
 		dataStreams: dataStreamsProcessor,
 		logFile:     logFile,
 	}
+	t.redactor = newRedactor(c.redactionRules, t.redactions.Inc)
+	return t, nil
+}
+
+// New returns a new started tracer, independent from the global tracer and from the other
+// tracers created with New. It owns its configuration, writer, samplers and stats, and the spans
+// it starts, and their children, are sent through it. Use ContextWithTracer to have the
+// integrations start their spans with it, while the package-level functions keep using the
+// global tracer started with Start.
+//
+// The process-wide features, such as AppSec, remote configuration and telemetry, are only
+// enabled by Start, and the process-wide settings, such as the header tags, the analytics rate
+// and the logger, are left to the global tracer. The tracer must be stopped with its Stop method.
+func New(opts ...StartOption) (Tracer, error) {
+	c, err := newInstanceConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	t, err := newUnstartedTracerWithConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	if !t.config.enabled.current {
+		t.statsd.Close()
+		return &NoopTracer{}, nil
+	}
+	t.instance = true
+	if tr, ok := t.config.transport.(*httpTransport); ok {
+		tr.tracer = t
+	}
+	t.start()
+	if t.dataStreams != nil {
+		t.dataStreams.Start()
+	}
 	return t, nil
 }
 
@@ -417,6 +501,12 @@ func newTracer(opts ...StartOption) (*tracer, error) {
 	if err != nil {
 		return nil, err
 	}
+	t.start()
+	return t, nil
+}
+
+// start starts the goroutines of the tracer.
+func (t *tracer) start() {
 	c := t.config
 	t.statsd.Incr("datadog.tracer.started", nil, 1)
 	if c.runtimeMetrics {
@@ -437,7 +527,7 @@ func newTracer(opts ...StartOption) (*tracer, error) {
 	}
 	if c.debugAbandonedSpans {
 		log.Info("Abandoned spans logs enabled.")
-		t.abandonedSpansDebugger = newAbandonedSpansDebugger()
+		t.abandonedSpansDebugger = newAbandonedSpansDebugger(c.now)
 		t.abandonedSpansDebugger.Start(t.config.spanTimeout)
 	}
 	t.wg.Add(1)
@@ -457,7 +547,6 @@ func newTracer(opts ...StartOption) (*tracer, error) {
 		t.reportHealthMetricsAtInterval(statsInterval)
 	}()
 	t.stats.Start()
-	return t, nil
 }
 
 // Flush flushes any buffered traces. Flush is in effect only if a tracer
@@ -492,6 +581,10 @@ func (t *tracer) worker(tick <-chan time.Time) {
 	for {
 		select {
 		case trace := <-t.out:
+			t.processChunk(trace)
+			if len(trace.spans) == 0 {
+				continue
+			}
 			t.sampleChunk(trace)
 			t.traceWriter.add(trace.spans)
 		case <-tick:
@@ -503,7 +596,7 @@ func (t *tracer) worker(tick <-chan time.Time) {
 			t.traceWriter.flush()
 			t.statsd.Flush()
 			if !t.config.tracingAsTransport {
-				t.stats.flushAndSend(time.Now(), withCurrentBucket)
+				t.stats.flushAndSend(t.config.nowTime(), withCurrentBucket)
 			}
 			// TODO(x): In reality, the traceWriter.flush() call is not synchronous
 			// when using the agent traceWriter. However, this functionality is used
@@ -517,6 +610,10 @@ func (t *tracer) worker(tick <-chan time.Time) {
 			for {
 				select {
 				case trace := <-t.out:
+					t.processChunk(trace)
+					if len(trace.spans) == 0 {
+						continue
+					}
 					t.sampleChunk(trace)
 					t.traceWriter.add(trace.spans)
 				default:
@@ -595,14 +692,24 @@ func (t *tracer) pushChunk(trace *Chunk) {
 	}
 }
 
+// defaultSpanConfig is the configuration of the spans started by spanStart, which uses the
+// system clock and random IDs.
+var defaultSpanConfig = &config{}
+
+// spanStart starts a span with the default configuration. It's used by the mocktracer.
 func spanStart(operationName string, options ...StartSpanOption) *Span {
+	return startSpan(defaultSpanConfig, operationName, options...)
+}
+
+// startSpan starts a span, using the clock and the ID generator of the configuration c.
+func startSpan(c *config, operationName string, options ...StartSpanOption) *Span {
 	var opts StartSpanConfig
 	for _, fn := range options {
 		fn(&opts)
 	}
 	var startTime int64
 	if opts.StartTime.IsZero() {
-		startTime = now()
+		startTime = c.now()
 	} else {
 		startTime = opts.StartTime.UnixNano()
 	}
@@ -631,7 +738,7 @@ func spanStart(operationName string, options ...StartSpanOption) *Span {
 	}
 	id := opts.SpanID
 	if id == 0 {
-		id = generateSpanID(startTime)
+		id = c.newSpanID(startTime)
 	}
 	// span defaults
 	span := &Span{
@@ -654,10 +761,11 @@ func spanStart(operationName string, options ...StartSpanOption) *Span {
 			span.setMetric(keySamplingPriority, float64(p))
 		}
 		if context.span != nil {
-			// local parent, inherit service
+			// local parent, inherit service and tracer
 			context.span.RLock()
 			span.service = context.span.service
 			context.span.RUnlock()
+			span.tracer = context.span.tracer
 		} else {
 			// remote parent
 			if context.origin != "" {
@@ -672,6 +780,9 @@ func spanStart(operationName string, options ...StartSpanOption) *Span {
 
 	}
 	span.context = newSpanContext(span, context)
+	if context == nil && c.idGenerator != nil && traceID128Enabled() {
+		span.context.traceID.SetUpper(c.idGenerator.TraceIDUpper(time.Unix(0, startTime)))
+	}
 	span.setMeta("language", "go")
 	// add tags from options
 	for k, v := range opts.Tags {
@@ -696,7 +807,10 @@ func (t *tracer) StartSpan(operationName string, options ...StartSpanOption) *Sp
 	if !t.config.enabled.current {
 		return nil
 	}
-	span := spanStart(operationName, options...)
+	span := startSpan(t.config, operationName, options...)
+	if t.instance {
+		span.tracer = t
+	}
 	if span.service == "" {
 		span.service = t.config.serviceName
 	}
@@ -758,6 +872,9 @@ func (t *tracer) StartSpan(operationName string, options ...StartSpanOption) *Sp
 	}
 	span.setMetric(ext.Pid, float64(t.pid))
 	t.spansStarted.Inc(span.integration)
+	if len(t.config.spanProcessors) > 0 {
+		t.processStartedSpan(span)
+	}
 
 	return span
 }
@@ -808,7 +925,9 @@ func (t *tracer) Stop() {
 		close(t.stop)
 		t.statsd.Incr("datadog.tracer.stopped", nil, 1)
 	})
-	globalconfig.SetServiceName("")
+	if !t.instance {
+		globalconfig.SetServiceName("")
+	}
 	t.abandonedSpansDebugger.Stop()
 	t.stats.Stop()
 	t.wg.Wait()
@@ -817,8 +936,10 @@ func (t *tracer) Stop() {
 	if t.dataStreams != nil {
 		t.dataStreams.Stop()
 	}
-	appsec.Stop()
-	remoteconfig.Stop()
+	if !t.instance {
+		appsec.Stop()
+		remoteconfig.Stop()
+	}
 	// Close log file last to account for any logs from the above calls
 	if t.logFile != nil {
 		t.logFile.Close()
@@ -13,6 +13,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -2752,3 +2753,97 @@ func TestPprofLabels(t *testing.T) {
 		wasteC(time.Second)
 	})
 }
+
+func TestNew(t *testing.T) {
+	_, global, flush, stop, err := startTestTracer(t, WithService("global"))
+	require.NoError(t, err)
+	defer stop()
+
+	// The previous global tracer is stopped by startTestTracer, which may reset the global service name.
+	service := globalconfig.ServiceName()
+
+	transport := newDummyTransport()
+	tr, err := New(withTransport(transport), withTickChan(make(chan time.Time)), WithService("tenant"), WithEnv("staging"))
+	require.NoError(t, err)
+	assert.Equal(t, service, globalconfig.ServiceName())
+
+	ctx := ContextWithTracer(context.Background(), tr)
+	assert.Equal(t, tr, TracerFromContext(ctx))
+	parent, ctx := StartSpanFromContext(ctx, "parent")
+	// The children of the span are started by the same tracer, even without the tracer in the context.
+	assert.Equal(t, tr, TracerFromContext(ContextWithSpan(context.Background(), parent)))
+	child := parent.StartChild("child")
+	grandchild, _ := StartSpanFromContext(ctx, "grandchild")
+	grandchild.Finish()
+	child.Finish()
+	parent.Finish()
+	other := StartSpan("other")
+	other.Finish()
+
+	// Stopping the tracer waits for the traces to be sent.
+	tr.Stop()
+	assert.Equal(t, service, globalconfig.ServiceName())
+	traces := transport.Traces()
+	require.Len(t, traces, 1)
+	require.Len(t, traces[0], 3)
+	for _, s := range traces[0] {
+		assert.Equal(t, "tenant", s.service)
+		assert.Equal(t, "staging", s.meta[ext.Environment])
+	}
+
+	flush(1)
+	traces = global.Traces()
+	require.Len(t, traces, 1)
+	assert.Equal(t, "other", traces[0][0].name)
+	assert.Equal(t, "global", traces[0][0].service)
+	assert.Equal(t, GetGlobalTracer(), TracerFromContext(context.Background()))
+}
+
+func TestNewChildSpans(t *testing.T) {
+	// There is no global tracer: the children started with the package-level functions are started by
+	// the tracer of their parent.
+	Stop()
+	transport := newDummyTransport()
+	tr, err := New(withTransport(transport), withTickChan(make(chan time.Time)), WithService("tenant"))
+	require.NoError(t, err)
+
+	parent := tr.StartSpan("parent")
+	child := StartSpan("child", ChildOf(parent.Context()))
+	require.NotNil(t, child)
+	assert.Contains(t, fmt.Sprintf("%v", child), "dd.service=tenant ")
+	grandchild := StartSpan("grandchild", ChildOf(child.Context()))
+	require.NotNil(t, grandchild)
+	grandchild.Finish()
+	child.Finish()
+	parent.Finish()
+
+	tr.Stop()
+	traces := transport.Traces()
+	require.Len(t, traces, 1)
+	require.Len(t, traces[0], 3)
+	for _, s := range traces[0] {
+		assert.Equal(t, "tenant", s.service)
+	}
+}
+
+func TestNewGlobalConfig(t *testing.T) {
+	_, _, _, stop, err := startTestTracer(t, WithService("global"), WithHeaderTags([]string{"X-Global:global"}), WithDogstatsdAddr("localhost:8125"))
+	require.NoError(t, err)
+	defer stop()
+	defer globalconfig.ClearHeaderTags()
+	service, statsTags, debug := globalconfig.ServiceName(), globalconfig.StatsTags(), log.DebugEnabled()
+
+	tr, err := New(withTransport(newDummyTransport()), WithService("tenant"), WithEnv("staging"),
+		WithHeaderTags([]string{"X-Tenant:tenant"}), WithAnalytics(true), WithDogstatsdAddr("localhost:8126"), WithDebugMode(true))
+	require.NoError(t, err)
+	defer tr.Stop()
+
+	assert.Equal(t, service, globalconfig.ServiceName())
+	assert.Equal(t, 1, globalconfig.HeaderTagsLen())
+	assert.Equal(t, "global", globalconfig.HeaderTag("X-Global"))
+	assert.Empty(t, globalconfig.HeaderTag("X-Tenant"))
+	assert.True(t, math.IsNaN(globalconfig.AnalyticsRate()))
+	assert.Equal(t, "localhost:8125", globalconfig.DogstatsdAddr())
+	assert.Equal(t, statsTags, globalconfig.StatsTags())
+	assert.Equal(t, debug, log.DebugEnabled())
+}
@@ -14,6 +14,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
@@ -76,10 +77,23 @@ type transport interface {
 }
 
 type httpTransport struct {
-	traceURL string            // the delivery URL for traces
-	statsURL string            // the delivery URL for stats
-	client   *http.Client      // the HTTP client used in the POST
-	headers  map[string]string // the Transport headers
+	traceURL    string            // the delivery URL for traces
+	traceV05URL string            // the delivery URL for v0.5 traces, if negotiated with the agent
+	statsURL    string            // the delivery URL for stats
+	client      *http.Client      // the HTTP client used in the POST
+	headers     map[string]string // the Transport headers
+
+	// contentEncoding is the encoding compressing the payloads, negotiated with the agent. The payloads
+	// aren't compressed if it's empty.
+	contentEncoding string
+	compression     compressionStats
+
+	// traceV04Fallback reports whether the traces are sent with the v0.4 protocol although v0.5 was
+	// negotiated, as the writer fell back to it.
+	traceV04Fallback atomic.Bool
+
+	// tracer is the tracer created with New sending the payloads, nil for the global tracer.
+	tracer *tracer
 }
 
 // newTransport returns a new Transport implementation that sends traces to a
@@ -120,13 +134,24 @@ func (t *httpTransport) sendStats(p *pb.ClientStatsPayload, tracerObfuscationVer
 	if err := msgp.Encode(&buf, p); err != nil {
 		return err
 	}
-	req, err := http.NewRequest("POST", t.statsURL, &buf)
+	body := &buf
+	if t.contentEncoding != "" {
+		compressed, err := compress(t.contentEncoding, &buf, &t.compression)
+		if err != nil {
+			return err
+		}
+		body = compressed
+	}
+	req, err := http.NewRequest("POST", t.statsURL, body)
 	if err != nil {
 		return err
 	}
 	for header, value := range t.headers {
 		req.Header.Set(header, value)
 	}
+	if t.contentEncoding != "" {
+		req.Header.Set("Content-Encoding", t.contentEncoding)
+	}
 	if tracerObfuscationVersion > 0 {
 		req.Header.Set(obfuscationVersionHeader, strconv.Itoa(tracerObfuscationVersion))
 	}
@@ -150,17 +175,36 @@ func (t *httpTransport) sendStats(p *pb.ClientStatsPayload, tracerObfuscationVer
 }
 
 func (t *httpTransport) send(p *payload) (body io.ReadCloser, err error) {
-	req, err := http.NewRequest("POST", t.traceURL, p)
+	var content io.Reader = p
+	size := p.size()
+	if t.contentEncoding != "" {
+		compressed, err := compress(t.contentEncoding, p, &t.compression)
+		if err != nil {
+			return nil, fmt.Errorf("cannot compress payload: %v", err)
+		}
+		content, size = compressed, compressed.Len()
+	}
+	traceURL := t.traceURL
+	if p.strings != nil {
+		traceURL = t.traceV05URL
+	} else if t.traceV05URL != "" {
+		// The writer fell back to v0.4.
+		t.traceV04Fallback.Store(true)
+	}
+	req, err := http.NewRequest("POST", traceURL, content)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create http request: %v", err)
 	}
 	for header, value := range t.headers {
 		req.Header.Set(header, value)
 	}
+	if t.contentEncoding != "" {
+		req.Header.Set("Content-Encoding", t.contentEncoding)
+	}
 	req.Header.Set(traceCountHeader, strconv.Itoa(p.itemCount()))
-	req.Header.Set("Content-Length", strconv.Itoa(p.size()))
+	req.Header.Set("Content-Length", strconv.Itoa(size))
 	req.Header.Set(headerComputedTopLevel, "yes")
-	if t := GetGlobalTracer(); t != nil {
+	if t := t.getTracer(); t != nil {
 		tc := t.TracerConf()
 		if tc.TracingAsTransport || tc.CanComputeStats {
 			// tracingAsTransport uses this header to disable the trace agent's stats computation
@@ -182,11 +226,11 @@ func (t *httpTransport) send(p *payload) (body io.ReadCloser, err error) {
 	}
 	response, err := t.client.Do(req)
 	if err != nil {
-		reportAPIErrorsMetric(response, err)
+		reportAPIErrorsMetric(t.getTracer(), response, err)
 		return nil, err
 	}
 	if code := response.StatusCode; code >= 400 {
-		reportAPIErrorsMetric(response, err)
+		reportAPIErrorsMetric(t.getTracer(), response, err)
 		// error, check the body for context information and
 		// return a nice error.
 		msg := make([]byte, 1000)
@@ -201,8 +245,16 @@ func (t *httpTransport) send(p *payload) (body io.ReadCloser, err error) {
 	return response.Body, nil
 }
 
-func reportAPIErrorsMetric(response *http.Response, err error) {
-	if t, ok := GetGlobalTracer().(*tracer); ok {
+// getTracer returns the tracer sending the payloads.
+func (t *httpTransport) getTracer() Tracer {
+	if t.tracer != nil {
+		return t.tracer
+	}
+	return GetGlobalTracer()
+}
+
+func reportAPIErrorsMetric(tr Tracer, response *http.Response, err error) {
+	if t, ok := tr.(*tracer); ok {
 		var reason string
 		if err != nil {
 			reason = "network_failure"
@@ -217,5 +269,8 @@ func reportAPIErrorsMetric(response *http.Response, err error) {
 }
 
 func (t *httpTransport) endpoint() string {
+	if t.traceV05URL != "" && !t.traceV04Fallback.Load() {
+		return t.traceV05URL
+	}
 	return t.traceURL
 }
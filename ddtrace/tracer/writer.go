@@ -36,6 +36,10 @@ type agentTraceWriter struct {
 	// config holds the tracer configuration
 	config *config
 
+	// protocol is the version of the protocol encoding the payloads, which falls back from v0.5 to v0.4
+	// once a trace holds a meta_struct.
+	protocol string
+
 	// payload encodes and buffers traces in msgpack format
 	payload *payload
 
@@ -58,14 +62,30 @@ type agentTraceWriter struct {
 func newAgentTraceWriter(c *config, s *prioritySampler, statsdClient globalinternal.StatsdClient) *agentTraceWriter {
 	return &agentTraceWriter{
 		config:           c,
-		payload:          newPayload(),
+		protocol:         c.traceProtocol,
+		payload:          newTracePayload(c.traceProtocol),
 		climit:           make(chan struct{}, concurrentConnectionLimit),
 		prioritySampling: s,
 		statsd:           statsdClient,
 	}
 }
 
+// newTracePayload returns a payload encoding the traces with the given protocol.
+func newTracePayload(protocol string) *payload {
+	if protocol == traceProtocolV05 {
+		return newPayloadV05()
+	}
+	return newPayload()
+}
+
 func (h *agentTraceWriter) add(trace []*Span) {
+	if h.protocol == traceProtocolV05 && hasMetaStruct(trace) {
+		// The meta_struct would be lost with the v0.5 protocol, so the traces are sent with v0.4 from now on.
+		log.Warn("Traces with a meta_struct can't be sent with the v0.5 protocol, falling back to v0.4")
+		h.flush()
+		h.protocol = traceProtocolV04
+		h.payload = newTracePayload(h.protocol)
+	}
 	if err := h.payload.push(trace); err != nil {
 		h.statsd.Incr("datadog.tracer.traces_dropped", []string{"reason:encoding_error"}, 1)
 		log.Error("Error encoding msgpack: %v", err)
@@ -91,7 +111,7 @@ func (h *agentTraceWriter) flush() {
 	h.wg.Add(1)
 	h.climit <- struct{}{}
 	oldp := h.payload
-	h.payload = newPayload()
+	h.payload = newTracePayload(h.protocol)
 	go func(p *payload) {
 		defer func(start time.Time) {
 			// Once the payload has been used, clear the buffer for garbage
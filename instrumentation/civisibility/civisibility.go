@@ -0,0 +1,203 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+// Package civisibility exposes the CI Visibility test API to the test framework
+// integrations of the contrib modules, which can't import the internal packages.
+// It's not intended to be used directly by applications.
+package civisibility
+
+import (
+	"time"
+
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/mocktracer"
+	"github.com/DataDog/dd-trace-go/v2/internal"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/integrations"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/net"
+)
+
+type (
+	// TestSession is the session of the tests run by a test command.
+	TestSession = integrations.TestSession
+	// TestModule is the module of the tests of a Go package.
+	TestModule = integrations.TestModule
+	// TestSuite is a suite of tests.
+	TestSuite = integrations.TestSuite
+	// Test is an execution of a test.
+	Test = integrations.Test
+	// TestResultStatus is the result status of a test.
+	TestResultStatus = integrations.TestResultStatus
+
+	// TestSessionStartOption is an option of CreateTestSession.
+	TestSessionStartOption = integrations.TestSessionStartOption
+	// TestModuleStartOption is an option of TestSession.GetOrCreateModule.
+	TestModuleStartOption = integrations.TestModuleStartOption
+	// TestStartOption is an option of TestSuite.CreateTest.
+	TestStartOption = integrations.TestStartOption
+	// TestCloseOption is an option of Test.Close.
+	TestCloseOption = integrations.TestCloseOption
+	// ErrorOption is an option of Test.SetError.
+	ErrorOption = integrations.ErrorOption
+
+	// Settings are the CI Visibility settings of the session.
+	Settings = net.SettingsResponseData
+	// KnownTests are the tests known by the backend, by module and suite.
+	KnownTests = net.KnownTestsResponseData
+	// TestManagementTests are the properties of the managed tests, by module and suite.
+	TestManagementTests = net.TestManagementTestsResponseDataModules
+	// FlakyRetriesSetting holds the settings of the automatic flaky test retries.
+	FlakyRetriesSetting = integrations.FlakyRetriesSetting
+	// CodeOwners holds the code owners of the repository.
+	CodeOwners = utils.CodeOwners
+)
+
+const (
+	// ResultStatusPass indicates that the test has passed.
+	ResultStatusPass = integrations.ResultStatusPass
+	// ResultStatusFail indicates that the test has failed.
+	ResultStatusFail = integrations.ResultStatusFail
+	// ResultStatusSkip indicates that the test has been skipped.
+	ResultStatusSkip = integrations.ResultStatusSkip
+)
+
+// Span types of the test events.
+const (
+	SpanTypeTestSession = constants.SpanTypeTestSession
+	SpanTypeTestModule  = constants.SpanTypeTestModule
+	SpanTypeTestSuite   = constants.SpanTypeTestSuite
+	SpanTypeTest        = constants.SpanTypeTest
+)
+
+// Tags of the test events, and their values.
+const (
+	TestModuleTag                  = constants.TestModule
+	TestSuiteTag                   = constants.TestSuite
+	TestName                       = constants.TestName
+	TestStatus                     = constants.TestStatus
+	TestStatusPass                 = constants.TestStatusPass
+	TestStatusFail                 = constants.TestStatusFail
+	TestStatusSkip                 = constants.TestStatusSkip
+	TestSkipReason                 = constants.TestSkipReason
+	TestSourceFile                 = constants.TestSourceFile
+	TestSourceStartLine            = constants.TestSourceStartLine
+	TestCodeOwners                 = constants.TestCodeOwners
+	TestManagementEnabledTag       = constants.TestManagementEnabled
+	TestIsNew                      = constants.TestIsNew
+	TestIsRetry                    = constants.TestIsRetry
+	TestRetryReason                = constants.TestRetryReason
+	TestHasFailedAllRetries        = constants.TestHasFailedAllRetries
+	TestIsQuarantined              = constants.TestIsQuarantined
+	TestIsDisabled                 = constants.TestIsDisabled
+	TestIsAttempToFix              = constants.TestIsAttempToFix
+	TestAttemptToFixPassed         = constants.TestAttemptToFixPassed
+	TestDisabledSkipReason         = constants.TestDisabledSkipReason
+	EarlyFlakeDetectionRetryReason = constants.EarlyFlakeDetectionRetryReason
+	AutoTestRetriesRetryReason     = constants.AutoTestRetriesRetryReason
+	ExternalRetryReason            = constants.ExternalRetryReason
+	GinkgoLabels                   = constants.GinkgoLabels
+	GinkgoContainers               = constants.GinkgoContainers
+	GinkgoFocused                  = constants.GinkgoFocused
+	GinkgoPendingSkipReason        = constants.GinkgoPendingSkipReason
+	GinkgoNotFocusedSkipReason     = constants.GinkgoNotFocusedSkipReason
+)
+
+// Enabled returns whether CI Visibility is enabled with DD_CIVISIBILITY_ENABLED.
+func Enabled() bool {
+	return internal.BoolEnv(constants.CIVisibilityEnabledEnvironmentVariable, false)
+}
+
+// TestManagementEnabled returns whether test management is enabled both by the settings
+// of the session and by DD_TEST_MANAGEMENT_ENABLED.
+func TestManagementEnabled() bool {
+	settings := integrations.GetSettings()
+	return settings != nil && settings.TestManagement.Enabled &&
+		internal.BoolEnv(constants.CIVisibilityTestManagementEnabledEnvironmentVariable, true)
+}
+
+// EnsureCiVisibilityInitialization initializes CI Visibility if it wasn't already.
+func EnsureCiVisibilityInitialization() {
+	integrations.EnsureCiVisibilityInitialization()
+}
+
+// ExitCiVisibility flushes the events and shuts CI Visibility down.
+func ExitCiVisibility() {
+	integrations.ExitCiVisibility()
+}
+
+// InitializeCIVisibilityMock initializes CI Visibility with a mock tracer, for the tests of the integrations.
+func InitializeCIVisibilityMock() mocktracer.Tracer {
+	return integrations.InitializeCIVisibilityMock()
+}
+
+// CreateTestSession creates the test session.
+func CreateTestSession(options ...TestSessionStartOption) TestSession {
+	return integrations.CreateTestSession(options...)
+}
+
+// GetActiveTestSession returns the test session, if it was created.
+func GetActiveTestSession() (TestSession, bool) {
+	return integrations.GetActiveTestSession()
+}
+
+// WithTestSessionFramework sets the test framework of the test session.
+func WithTestSessionFramework(framework, frameworkVersion string) TestSessionStartOption {
+	return integrations.WithTestSessionFramework(framework, frameworkVersion)
+}
+
+// WithTestModuleFramework sets the test framework of the test module.
+func WithTestModuleFramework(framework, frameworkVersion string) TestModuleStartOption {
+	return integrations.WithTestModuleFramework(framework, frameworkVersion)
+}
+
+// WithTestStartTime sets the start time of the test.
+func WithTestStartTime(startTime time.Time) TestStartOption {
+	return integrations.WithTestStartTime(startTime)
+}
+
+// WithTestSkipReason sets the reason why the test was skipped.
+func WithTestSkipReason(skipReason string) TestCloseOption {
+	return integrations.WithTestSkipReason(skipReason)
+}
+
+// WithErrorInfo sets the type, message and callstack of the error of the test.
+func WithErrorInfo(errType string, message string, callstack string) ErrorOption {
+	return integrations.WithErrorInfo(errType, message, callstack)
+}
+
+// GetSettings returns the CI Visibility settings of the session.
+func GetSettings() *Settings {
+	return integrations.GetSettings()
+}
+
+// GetKnownTests returns the tests known by the backend.
+func GetKnownTests() *KnownTests {
+	return integrations.GetKnownTests()
+}
+
+// GetTestManagementTestsData returns the properties of the managed tests.
+func GetTestManagementTestsData() *TestManagementTests {
+	return integrations.GetTestManagementTestsData()
+}
+
+// GetFlakyRetriesSettings returns the settings of the automatic flaky test retries.
+func GetFlakyRetriesSettings() *FlakyRetriesSetting {
+	return integrations.GetFlakyRetriesSettings()
+}
+
+// GetModuleAndSuiteName returns the test module and suite names of the function at pc.
+func GetModuleAndSuiteName(pc uintptr) (module string, suite string) {
+	return utils.GetModuleAndSuiteName(pc)
+}
+
+// GetRelativePathFromCITagsSourceRoot returns path relative to the root of the sources.
+func GetRelativePathFromCITagsSourceRoot(path string) string {
+	return utils.GetRelativePathFromCITagsSourceRoot(path)
+}
+
+// GetCodeOwners returns the code owners of the repository, or nil if there's no CODEOWNERS file.
+func GetCodeOwners() *CodeOwners {
+	return utils.GetCodeOwners()
+}
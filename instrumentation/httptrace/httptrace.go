@@ -70,11 +70,11 @@ func StartRequestSpan(r *http.Request, opts ...tracer.StartSpanOption) (*tracer.
 		if !inferredProxySpanCreated {
 			var inferredStartSpanOpts []tracer.StartSpanOption
 
-			requestProxyContext, err := extractInferredProxyContext(r.Header)
+			requestProxyContext, err := extractInferredProxyContext(r)
 			if err != nil {
 				log.Debug("%s\n", err.Error())
 			} else {
-				spanParentCtx, spanParentErr := tracer.Extract(tracer.HTTPHeadersCarrier(r.Header))
+				spanParentCtx, spanParentErr := tracer.ExtractFromContext(r.Context(), tracer.HTTPHeadersCarrier(r.Header))
 				if spanParentErr == nil {
 					if spanParentCtx != nil && spanParentCtx.SpanLinks() != nil {
 						inferredStartSpanOpts = append(inferredStartSpanOpts, tracer.WithSpanLinks(spanParentCtx.SpanLinks()))
@@ -103,7 +103,7 @@ func StartRequestSpan(r *http.Request, opts ...tracer.StartSpanOption) (*tracer.
 			if inferredProxySpan != nil {
 				tracer.ChildOf(inferredProxySpan.Context())(ssCfg)
 			} else {
-				if spanctx, err := tracer.Extract(tracer.HTTPHeadersCarrier(r.Header)); err == nil {
+				if spanctx, err := tracer.ExtractFromContext(r.Context(), tracer.HTTPHeadersCarrier(r.Header)); err == nil {
 					// If there are span links as a result of context extraction, add them as a StartSpanOption
 					if spanctx != nil && spanctx.SpanLinks() != nil {
 						tracer.WithSpanLinks(spanctx.SpanLinks())(ssCfg)
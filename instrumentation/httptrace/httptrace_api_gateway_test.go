@@ -19,6 +19,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/mocktracer"
+	"github.com/DataDog/dd-trace-go/v2/internal/globalconfig"
 	"github.com/DataDog/dd-trace-go/v2/internal/normalizer"
 )
 
@@ -196,3 +197,124 @@ func TestInferredProxySpans(t *testing.T) {
 		assert.Equal(t, startTime.UnixMilli(), gwSpan.StartTime().UnixMilli())
 	})
 }
+
+func TestInferredProxySystems(t *testing.T) {
+	t.Setenv("DD_SERVICE", "aws-server")
+	t.Setenv("DD_TRACE_INFERRED_PROXY_SERVICES_ENABLED", "true")
+	ResetCfg()
+
+	// The mocktracer doesn't set the global service name.
+	globalconfig.SetServiceName("aws-server")
+	defer globalconfig.SetServiceName("")
+
+	for _, tt := range []struct {
+		system    string
+		domain    string
+		spanName  string
+		component string
+		service   string
+	}{
+		{"azure-apim", "contoso.azure-api.net", "azure.apim", "azure-apim", "contoso.azure-api.net"},
+		{"azure-apim", "", "azure.apim", "azure-apim", "aws-server"},
+		{"gcp-apigateway", "gateway.uc.gateway.dev", "gcp.apigateway", "gcp-apigateway", "gateway.uc.gateway.dev"},
+		{"kong", "api.example.com", "kong.proxy", "kong", "api.example.com"},
+		{"kong", "", "kong.proxy", "kong", "kong"},
+	} {
+		t.Run(tt.system+"/"+tt.domain, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			req, err := http.NewRequest("GET", "https://example.com/users/1", nil)
+			require.NoError(t, err)
+			req.Header.Set("x-dd-proxy", tt.system)
+			req.Header.Set("x-dd-proxy-request-time-ms", strconv.FormatInt(time.Now().UnixMilli(), 10))
+			req.Header.Set("x-dd-proxy-path", "/users/{id}")
+			req.Header.Set("x-dd-proxy-httpmethod", "GET")
+			req.Header.Set("x-dd-proxy-domain-name", tt.domain)
+
+			_, _, finishSpans := StartRequestSpan(req)
+			finishSpans(200, nil)
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 2)
+			proxySpan := spans[1]
+			assert.Equal(t, tt.spanName, proxySpan.OperationName())
+			assert.Equal(t, tt.component, proxySpan.Tag(ext.Component))
+			assert.Equal(t, tt.service, proxySpan.Tag(ext.ServiceName))
+			assert.Equal(t, "GET /users/{id}", proxySpan.Tag(ext.ResourceName))
+			assert.Equal(t, spans[0].ParentID(), proxySpan.SpanID())
+		})
+	}
+}
+
+func TestQueueTimeInferredSpans(t *testing.T) {
+	t.Setenv("DD_SERVICE", "web-server")
+	t.Setenv("DD_TRACE_INFERRED_PROXY_SERVICES_ENABLED", "true")
+	ResetCfg()
+
+	// The mocktracer doesn't set the global service name.
+	globalconfig.SetServiceName("web-server")
+	defer globalconfig.SetServiceName("")
+
+	startTime := time.Now().Add(-250 * time.Millisecond)
+	for name, header := range map[string][2]string{
+		"nginx":   {"X-Request-Start", fmt.Sprintf("t=%.3f", float64(startTime.UnixMilli())/1000)},
+		"haproxy": {"X-Request-Start", fmt.Sprintf("t=%d", startTime.UnixMicro())},
+		"heroku":  {"X-Request-Start", strconv.FormatInt(startTime.UnixMilli(), 10)},
+		"queue":   {"X-Queue-Start", fmt.Sprintf("t=%d", startTime.UnixMilli())},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			req, err := http.NewRequest("POST", "https://example.com/test", nil)
+			require.NoError(t, err)
+			req.Header.Set(header[0], header[1])
+
+			_, _, finishSpans := StartRequestSpan(req)
+			finishSpans(200, nil)
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 2)
+			proxySpan := spans[1]
+			assert.Equal(t, "http.proxy", proxySpan.OperationName())
+			assert.Equal(t, "web-server", proxySpan.Tag(ext.ServiceName))
+			assert.Equal(t, "POST", proxySpan.Tag(ext.HTTPMethod))
+			assert.Equal(t, "https://example.com/test", proxySpan.Tag(ext.HTTPURL))
+			assert.Equal(t, "POST /test", proxySpan.Tag(ext.ResourceName))
+			assert.Nil(t, proxySpan.Tag(ext.HTTPRoute))
+			assert.Equal(t, float64(1), proxySpan.Tag("_dd.inferred_span"))
+			assert.Equal(t, startTime.UnixMilli(), proxySpan.StartTime().UnixMilli())
+			assert.InDelta(t, 250, proxySpan.Tag("http.queue_time_ms"), 100)
+			assert.Equal(t, spans[0].ParentID(), proxySpan.SpanID())
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		req, err := http.NewRequest("GET", "https://example.com/test", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Request-Start", "t=yesterday")
+
+		_, _, finishSpans := StartRequestSpan(req)
+		finishSpans(200, nil)
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "http.request", spans[0].OperationName())
+	})
+}
+
+func TestParseRequestStart(t *testing.T) {
+	want := time.Date(2025, 1, 2, 3, 4, 5, 678000000, time.UTC)
+	for _, value := range []string{"t=1735787045.678", "1735787045678", "t=1735787045678", "t=1735787045678000", " t=1735787045678 "} {
+		got, err := parseRequestStart(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want.UnixMilli(), got.UnixMilli(), value)
+	}
+	for _, value := range []string{"", "t=", "t=abc", "-1"} {
+		_, err := parseRequestStart(value)
+		assert.Error(t, err, value)
+	}
+}
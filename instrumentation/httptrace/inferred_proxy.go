@@ -8,8 +8,10 @@ package httptrace
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
@@ -57,50 +59,141 @@ const (
 	ProxyHeaderStage = "X-Dd-Proxy-Stage"
 )
 
+// Standard headers set by load balancers and routers such as NGINX, HAProxy and the Heroku router,
+// holding the time at which they received the request.
+const (
+	// HeaderRequestStart is the header holding the time at which the proxy received the request,
+	// either in the "t=<time>" form or as a bare timestamp. The time is a UNIX timestamp in seconds,
+	// with an optional fractional part, milliseconds or microseconds, told apart by its magnitude.
+	HeaderRequestStart = "X-Request-Start"
+
+	// HeaderQueueStart is an alternative to HeaderRequestStart, with the same format.
+	HeaderQueueStart = "X-Queue-Start"
+)
+
+// queueTimeMetric is the metric holding the time spent by the request between the proxy and the
+// application, in milliseconds.
+const queueTimeMetric = "http.queue_time_ms"
+
 type proxyDetails struct {
 	spanName  string
 	component string
+	// service returns the service name of the inferred span.
+	service func(pc *proxyContext) string
+	// resource returns the resource name of the inferred span.
+	resource func(pc *proxyContext) string
+	// queueTime is set for the proxies only known from the queue-time headers, which don't tell
+	// the route or the stage of the request.
+	queueTime bool
 }
 
 type proxyContext struct {
 	startTime       time.Time
 	method          string
 	path            string
+	url             string
 	stage           string
 	domainName      string
 	proxySystemName string
+	details         proxyDetails
+	// queueTime is the time spent by the request between the proxy and the application, when the proxy
+	// is only known from the queue-time headers.
+	queueTime time.Duration
+}
+
+// domainService names the inferred span after the domain name of the proxy, or the global service.
+func domainService(pc *proxyContext) string {
+	if pc.domainName != "" {
+		return pc.domainName
+	}
+	return globalconfig.ServiceName()
+}
+
+// methodPathResource names the resource of the inferred span after the method and path of the request.
+func methodPathResource(pc *proxyContext) string {
+	return fmt.Sprintf("%s %s", pc.method, pc.path)
 }
 
 var (
+	// supportedProxies holds the proxies known from the X-Dd-Proxy headers, by system. The Datadog
+	// policies of Azure API Management and GCP API Gateway set the headers with the semantics of
+	// AWS API Gateway: the domain name is the host of the gateway and the path is the path of the
+	// request to it. Their spans are deliberately named like the aws-apigateway ones, after the
+	// domain name and the method and path, so that the gateways are reported alike.
 	supportedProxies = map[string]proxyDetails{
 		"aws-apigateway": {
 			spanName:  "aws.apigateway",
 			component: "aws-apigateway",
+			service:   domainService,
+			resource:  methodPathResource,
+		},
+		"azure-apim": {
+			spanName:  "azure.apim",
+			component: "azure-apim",
+			service:   domainService,
+			resource:  methodPathResource,
+		},
+		"gcp-apigateway": {
+			spanName:  "gcp.apigateway",
+			component: "gcp-apigateway",
+			service:   domainService,
+			resource:  methodPathResource,
+		},
+		"kong": {
+			spanName:  "kong.proxy",
+			component: "kong",
+			// Kong is usually reached through the domain of the application, so the span is named
+			// after Kong unless a domain name is set.
+			service: func(pc *proxyContext) string {
+				if pc.domainName != "" {
+					return pc.domainName
+				}
+				return "kong"
+			},
+			resource: methodPathResource,
 		},
 	}
-)
 
-func extractInferredProxyContext(headers http.Header) (*proxyContext, error) {
-	_, exists := headers[ProxyHeaderStartTimeMs]
-	if !exists {
-		return nil, errors.New("proxy header start time does not exist")
+	// queueTimeProxy describes the proxies only known from the queue-time headers.
+	queueTimeProxy = proxyDetails{
+		spanName:  "http.proxy",
+		component: "http-proxy",
+		service: func(*proxyContext) string {
+			return globalconfig.ServiceName()
+		},
+		resource:  methodPathResource,
+		queueTime: true,
 	}
+)
 
+func extractInferredProxyContext(r *http.Request) (*proxyContext, error) {
+	headers := r.Header
 	proxyHeaderSystem, exists := headers[ProxyHeaderSystem]
 	if !exists {
+		if pc, ok := extractQueueTimeContext(r); ok {
+			return pc, nil
+		}
 		return nil, errors.New("proxy header system does not exist")
 	}
 
-	if _, ok := supportedProxies[proxyHeaderSystem[0]]; !ok {
+	_, exists = headers[ProxyHeaderStartTimeMs]
+	if !exists {
+		return nil, errors.New("proxy header start time does not exist")
+	}
+
+	details, ok := supportedProxies[proxyHeaderSystem[0]]
+	if !ok {
 		return nil, errors.New("unsupported Proxy header system")
 	}
 
 	pc := proxyContext{
 		method:          headers.Get(ProxyHeaderHTTPMethod),
 		path:            headers.Get(ProxyHeaderPath),
+		url:             headers.Get(ProxyHeaderDomain) + headers.Get(ProxyHeaderPath),
 		stage:           headers.Get(ProxyHeaderStage),
 		domainName:      headers.Get(ProxyHeaderDomain),
 		proxySystemName: headers.Get(ProxyHeaderSystem),
+		details:         details,
 	}
 
 	startTimeUnixMilli, err := strconv.ParseInt(headers[ProxyHeaderStartTimeMs][0], 10, 64)
@@ -112,16 +205,65 @@ func extractInferredProxyContext(headers http.Header) (*proxyContext, error) {
 	return &pc, nil
 }
 
+// extractQueueTimeContext returns the context of the proxy which set the queue-time headers, if any.
+// As the headers don't tell the request received by the proxy, it's assumed to be the request
+// forwarded to the application.
+func extractQueueTimeContext(r *http.Request) (*proxyContext, bool) {
+	value := r.Header.Get(HeaderRequestStart)
+	if value == "" {
+		value = r.Header.Get(HeaderQueueStart)
+	}
+	if value == "" {
+		return nil, false
+	}
+	startTime, err := parseRequestStart(value)
+	if err != nil {
+		log.Debug("httptrace: ignoring the queue-time header %q: %v", value, err.Error())
+		return nil, false
+	}
+	queueTime := time.Since(startTime)
+	if queueTime < 0 {
+		// The clocks of the proxy and the application are skewed.
+		queueTime = 0
+		startTime = time.Now()
+	}
+	return &proxyContext{
+		startTime: startTime,
+		method:    r.Method,
+		path:      r.URL.Path,
+		url:       URLFromRequest(r, false),
+		details:   queueTimeProxy,
+		queueTime: queueTime,
+	}, true
+}
+
+// parseRequestStart parses the value of a queue-time header, in the "t=<time>" form set by NGINX and
+// HAProxy, or as a bare timestamp as set by the Heroku router. The unit of the timestamp, seconds with
+// an optional fractional part, milliseconds or microseconds, is told apart by its magnitude.
+func parseRequestStart(value string) (time.Time, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "t=")
+	ts, err := strconv.ParseFloat(value, 64)
+	if err != nil || ts <= 0 {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+	}
+	switch {
+	case ts > 1e15:
+		return time.UnixMicro(int64(ts)), nil
+	case ts > 1e12:
+		return time.UnixMilli(int64(ts)), nil
+	default:
+		// The fractional part is rounded to the microsecond, as floats can't represent all the timestamps.
+		return time.UnixMicro(int64(math.Round(ts * 1e6))), nil
+	}
+}
+
 func startInferredProxySpan(requestProxyContext *proxyContext, parent *tracer.SpanContext, opts ...tracer.StartSpanOption) *tracer.Span {
-	proxySpanInfo := supportedProxies[requestProxyContext.proxySystemName]
-	log.Debug(`Successfully extracted inferred span info ${proxyContext} for proxy: ${proxyContext.proxySystemName}`)
+	proxySpanInfo := requestProxyContext.details
+	log.Debug("httptrace: starting an inferred span for proxy %q", proxySpanInfo.component)
 
 	startTime := requestProxyContext.startTime
 
-	configService := requestProxyContext.domainName
-	if configService == "" {
-		configService = globalconfig.ServiceName()
-	}
+	configService := proxySpanInfo.service(requestProxyContext)
 
 	optsLocal := make([]tracer.StartSpanOption, len(opts), len(opts)+1)
 	copy(optsLocal, opts)
@@ -138,11 +280,15 @@ func startInferredProxySpan(requestProxyContext *proxyContext, parent *tracer.Sp
 			cfg.Tags[ext.SpanType] = ext.SpanTypeWeb
 			cfg.Tags[ext.ServiceName] = configService
 			cfg.Tags[ext.Component] = proxySpanInfo.component
+			cfg.Tags["_dd.inferred_span"] = 1
 			cfg.Tags[ext.HTTPMethod] = requestProxyContext.method
-			cfg.Tags[ext.HTTPURL] = requestProxyContext.domainName + requestProxyContext.path
+			cfg.Tags[ext.HTTPURL] = requestProxyContext.url
+			cfg.Tags[ext.ResourceName] = proxySpanInfo.resource(requestProxyContext)
+			if proxySpanInfo.queueTime {
+				cfg.Tags[queueTimeMetric] = float64(requestProxyContext.queueTime) / float64(time.Millisecond)
+				return
+			}
 			cfg.Tags[ext.HTTPRoute] = requestProxyContext.path
-			cfg.Tags[ext.ResourceName] = fmt.Sprintf("%s %s", requestProxyContext.method, requestProxyContext.path)
-			cfg.Tags["_dd.inferred_span"] = 1
 			cfg.Tags["stage"] = requestProxyContext.stage
 		},
 	)
@@ -52,4 +52,55 @@ const (
 
 	// CIVisibilityImpactedTestsDetectionEnabled indicates if the impacted tests detection feature is enabled.
 	CIVisibilityImpactedTestsDetectionEnabled = "DD_CIVISIBILITY_IMPACTED_TESTS_DETECTION_ENABLED"
+
+	// CIVisibilityOfflineDirEnvironmentVariable enables the offline mode and indicates the directory where the
+	// test cycle and coverage payloads are written to instead of being sent. The payloads can be uploaded later
+	// with the offlineupload command, e.g. from a runner with network access.
+	CIVisibilityOfflineDirEnvironmentVariable = "DD_CIVISIBILITY_OFFLINE_DIR"
+
+	// CIVisibilityBenchmarkRegressionEnabledEnvironmentVariable indicates if the benchmark results are compared to a baseline.
+	// This environment variable should be set to "1" or "true" to enable benchmark regression detection.
+	CIVisibilityBenchmarkRegressionEnabledEnvironmentVariable = "DD_CIVISIBILITY_BENCHMARK_REGRESSION_ENABLED"
+
+	// CIVisibilityBenchmarkBaselineFileEnvironmentVariable indicates the path of a local baseline file. When it is not set,
+	// the baseline is the latest results of the default branch, fetched from the backend.
+	CIVisibilityBenchmarkBaselineFileEnvironmentVariable = "DD_CIVISIBILITY_BENCHMARK_BASELINE_FILE"
+
+	// CIVisibilityBenchmarkBaselineOutputEnvironmentVariable indicates the path of the file the benchmark results of the session
+	// are written to, in the baseline file format, so that they can be used as a local baseline.
+	CIVisibilityBenchmarkBaselineOutputEnvironmentVariable = "DD_CIVISIBILITY_BENCHMARK_BASELINE_OUTPUT"
+
+	// CIVisibilityBenchmarkRegressionThresholdEnvironmentVariable indicates the relative change, in percent, from which
+	// a benchmark metric is considered to have regressed or improved (10 by default).
+	CIVisibilityBenchmarkRegressionThresholdEnvironmentVariable = "DD_CIVISIBILITY_BENCHMARK_REGRESSION_THRESHOLD"
+
+	// CIVisibilityBenchmarkRegressionFailEnvironmentVariable indicates if benchmarks regressing should fail.
+	// This environment variable should be set to "1" or "true" to fail the session on regressions.
+	CIVisibilityBenchmarkRegressionFailEnvironmentVariable = "DD_CIVISIBILITY_BENCHMARK_REGRESSION_FAIL"
+
+	// CIVisibilityLocalImpactAnalysisEnabledEnvironmentVariable indicates if the local test impact analysis is enabled.
+	// It skips the tests whose covered lines weren't changed since the base revision, using the per test coverage
+	// recorded locally instead of the backend. This environment variable should be set to "1" or "true" to enable it.
+	CIVisibilityLocalImpactAnalysisEnabledEnvironmentVariable = "DD_CIVISIBILITY_LOCAL_IMPACT_ANALYSIS_ENABLED"
+
+	// CIVisibilityLocalImpactAnalysisCacheDirEnvironmentVariable indicates the directory of the per test coverage
+	// cache of the local test impact analysis (a directory in the user cache directory by default).
+	CIVisibilityLocalImpactAnalysisCacheDirEnvironmentVariable = "DD_CIVISIBILITY_LOCAL_IMPACT_ANALYSIS_CACHE_DIR"
+
+	// CIVisibilityLocalImpactAnalysisBaseEnvironmentVariable indicates the revision the working tree is compared to by
+	// the local test impact analysis (HEAD by default, i.e. the uncommitted changes).
+	CIVisibilityLocalImpactAnalysisBaseEnvironmentVariable = "DD_CIVISIBILITY_LOCAL_IMPACT_ANALYSIS_BASE"
+
+	// CIVisibilityCoverageLcovOutputEnvironmentVariable indicates the path of the LCOV line coverage report written at the
+	// end of the tests. The reports of the test binaries run by the same go test command are merged.
+	CIVisibilityCoverageLcovOutputEnvironmentVariable = "DD_CIVISIBILITY_COVERAGE_LCOV_OUTPUT"
+
+	// CIVisibilityCoverageCoberturaOutputEnvironmentVariable indicates the path of the Cobertura XML line coverage report
+	// written at the end of the tests. The reports of the test binaries run by the same go test command are merged.
+	CIVisibilityCoverageCoberturaOutputEnvironmentVariable = "DD_CIVISIBILITY_COVERAGE_COBERTURA_OUTPUT"
+
+	// CIVisibilityCoverageReportIDEnvironmentVariable identifies the go test command the line coverage reports are
+	// written for, and should be set to a new value for each command (e.g. the CI job ID) by the process running it.
+	// The coverage of the test binaries sharing the ID is merged (the go test process ID is used by default).
+	CIVisibilityCoverageReportIDEnvironmentVariable = "DD_CIVISIBILITY_COVERAGE_REPORT_ID"
 )
@@ -148,6 +148,80 @@ const (
 	TestStatusSkip = "skip"
 )
 
+// Fuzz tests tags and values.
+const (
+	// TestFuzzMode indicates whether a fuzz target ran in fuzzing mode or only
+	// replayed its seed corpus.
+	// This constant is used to tag fuzz target test events.
+	TestFuzzMode = "test.fuzz.mode"
+
+	// TestFuzzCorpusEntry indicates the name of the seed corpus entry of a fuzz target execution.
+	// This constant is used to tag the test events of seed corpus replays.
+	TestFuzzCorpusEntry = "test.fuzz.corpus_entry"
+
+	// TestFuzzFailingInputPath indicates the path of the file containing the input that made a fuzz target fail.
+	// This constant is used to tag failed fuzz target test events.
+	TestFuzzFailingInputPath = "test.fuzz.failing_input.path"
+
+	// TestFuzzFailingInputHash indicates the SHA-256 hash of the contents of the input that made a fuzz target fail.
+	// This constant is used to tag failed fuzz target test events.
+	TestFuzzFailingInputHash = "test.fuzz.failing_input.sha256"
+
+	// TestFuzzModeCorpus marks a fuzz target execution replaying its seed corpus.
+	TestFuzzModeCorpus = "corpus"
+
+	// TestFuzzModeFuzzing marks a fuzz target execution generating new inputs (go test -fuzz).
+	TestFuzzModeFuzzing = "fuzzing"
+)
+
+// Ginkgo tags.
+const (
+	// GinkgoLabels indicates the labels of a Ginkgo spec, including the ones inherited from its containers.
+	// This constant is used to tag Ginkgo spec test events.
+	GinkgoLabels = "test.ginkgo.labels"
+
+	// GinkgoContainers indicates the texts of the Describe/Context containers of a Ginkgo spec, outermost first.
+	// This constant is used to tag Ginkgo spec test events.
+	GinkgoContainers = "test.ginkgo.containers"
+
+	// GinkgoFocused indicates that a Ginkgo spec ran in a suite with focused specs (FIt, FDescribe, --focus...).
+	// This constant is used to tag Ginkgo spec test events.
+	GinkgoFocused = "test.ginkgo.focused"
+
+	// GinkgoPendingSkipReason indicates the skip reason of a pending Ginkgo spec.
+	GinkgoPendingSkipReason = "Spec is pending"
+
+	// GinkgoNotFocusedSkipReason indicates the skip reason of a Ginkgo spec filtered out by focus or label filters.
+	GinkgoNotFocusedSkipReason = "Spec not selected by focus or filters"
+)
+
+// Benchmark regression detection tags.
+const (
+	// BenchmarkRegressionStatus indicates how the results of a benchmark compare to the baseline.
+	// This constant is used to tag benchmark test events with BenchmarkRegressionStatusRegression,
+	// BenchmarkRegressionStatusImprovement or BenchmarkRegressionStatusUnchanged.
+	BenchmarkRegressionStatus = "benchmark.regression.status"
+
+	// BenchmarkRegressionMetrics indicates the comma-separated list of the metrics of a benchmark which regressed.
+	BenchmarkRegressionMetrics = "benchmark.regression.metrics"
+
+	// BenchmarkRegressionChangePrefix is the prefix of the tags holding the relative change, in percent,
+	// of every metric of a benchmark compared to the baseline, e.g. "benchmark.regression.change.ns_per_op".
+	BenchmarkRegressionChangePrefix = "benchmark.regression.change."
+
+	// BenchmarkRegressionEnabled indicates that benchmark regression detection is enabled for the test session.
+	BenchmarkRegressionEnabled = "benchmark.regression.enabled"
+
+	// BenchmarkRegressionStatusRegression marks a benchmark slower or allocating more than its baseline.
+	BenchmarkRegressionStatusRegression = "regression"
+
+	// BenchmarkRegressionStatusImprovement marks a benchmark faster or allocating less than its baseline.
+	BenchmarkRegressionStatusImprovement = "improvement"
+
+	// BenchmarkRegressionStatusUnchanged marks a benchmark within the threshold of its baseline.
+	BenchmarkRegressionStatusUnchanged = "unchanged"
+)
+
 // Define valid test types.
 const (
 	// TestTypeTest defines test type as test.
@@ -0,0 +1,236 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package integrations
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/net"
+	"github.com/DataDog/dd-trace-go/v2/internal/log"
+)
+
+// benchmarkRegressionStdDevs is the number of standard deviations of the baseline a metric has to
+// move by to be considered to have changed, so that noisy benchmarks don't report false positives.
+const benchmarkRegressionStdDevs = 2.0
+
+// Names of the metrics compared to the baseline, as used in the tags.
+const (
+	BenchmarkMetricNsPerOp     = "ns_per_op"
+	BenchmarkMetricBytesPerOp  = "bytes_per_op"
+	BenchmarkMetricAllocsPerOp = "allocs_per_op"
+)
+
+var (
+	// benchmarkBaselineOnce ensures we load the benchmark baseline just once
+	benchmarkBaselineOnce sync.Once
+
+	// ciVisibilityBenchmarkBaseline contains the baseline the benchmarks of this session are compared to
+	ciVisibilityBenchmarkBaseline *net.BenchmarkBaselineResponseData
+)
+
+// GetBenchmarkBaseline gets the baseline the benchmark results are compared to, or nil if there's none.
+// The baseline is read from the file set with DD_CIVISIBILITY_BENCHMARK_BASELINE_FILE if any, otherwise
+// the latest results of the default branch are fetched from the backend.
+func GetBenchmarkBaseline() *net.BenchmarkBaselineResponseData {
+	benchmarkBaselineOnce.Do(func() {
+		if path := os.Getenv(constants.CIVisibilityBenchmarkBaselineFileEnvironmentVariable); path != "" {
+			baseline, err := LoadBenchmarkBaselineFile(path)
+			if err != nil {
+				log.Error("civisibility: error loading the benchmark baseline file: %v", err)
+				return
+			}
+			ciVisibilityBenchmarkBaseline = baseline
+			log.Debug("civisibility: benchmark baseline loaded from %s", path)
+			return
+		}
+
+		// call to ensure the settings initialization is completed (service name can be null here)
+		ensureSettingsInitialization("")
+		if ciVisibilityClient == nil {
+			return
+		}
+		baseline, err := ciVisibilityClient.GetBenchmarkBaseline()
+		if err != nil {
+			log.Error("civisibility: error getting the benchmark baseline: %v", err)
+			return
+		}
+		if baseline == nil {
+			log.Debug("civisibility: no benchmark baseline found for the default branch.")
+			return
+		}
+		ciVisibilityBenchmarkBaseline = baseline
+		log.Debug("civisibility: benchmark baseline loaded.")
+	})
+	return ciVisibilityBenchmarkBaseline
+}
+
+// LoadBenchmarkBaselineFile reads a benchmark baseline file.
+func LoadBenchmarkBaselineFile(path string) (*net.BenchmarkBaselineResponseData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var baseline net.BenchmarkBaselineResponseData
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, err
+	}
+	return &baseline, nil
+}
+
+// WriteBenchmarkBaselineFile writes a benchmark baseline file, which can be used later with
+// DD_CIVISIBILITY_BENCHMARK_BASELINE_FILE.
+func WriteBenchmarkBaselineFile(path string, baseline *net.BenchmarkBaselineResponseData) error {
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	// 0644 is what touch does, should be reasonable for the use cases here.
+	return os.WriteFile(path, data, 0644)
+}
+
+// BenchmarkMeasure holds the per operation metrics of a benchmark run.
+type BenchmarkMeasure struct {
+	NsPerOp     float64
+	BytesPerOp  float64
+	AllocsPerOp float64
+}
+
+// BenchmarkComparison is the result of the comparison of a benchmark run to its baseline.
+type BenchmarkComparison struct {
+	// Status is one of the constants.BenchmarkRegressionStatus* values.
+	Status string
+	// RegressedMetrics lists the names of the metrics which regressed.
+	RegressedMetrics []string
+	// Details describes the metrics which regressed, e.g. for error messages.
+	Details []string
+	// Changes holds the relative change, in percent, of the metrics with a non-zero baseline.
+	Changes map[string]float64
+}
+
+// CompareBenchmark compares the metrics of a benchmark run to its baseline. A metric regresses
+// (or improves) when it grows (or shrinks) by more than threshold percent of the baseline mean,
+// and by more than two standard deviations of the baseline runs.
+func CompareBenchmark(baseline net.BenchmarkBaselineStats, m BenchmarkMeasure, threshold float64) BenchmarkComparison {
+	c := BenchmarkComparison{
+		Status:  constants.BenchmarkRegressionStatusUnchanged,
+		Changes: map[string]float64{},
+	}
+	improved := false
+	for _, metric := range []struct {
+		name     string
+		unit     string
+		baseline net.BenchmarkBaselineMetric
+		value    float64
+	}{
+		{BenchmarkMetricNsPerOp, "ns/op", baseline.NsPerOp, m.NsPerOp},
+		{BenchmarkMetricBytesPerOp, "B/op", baseline.BytesPerOp, m.BytesPerOp},
+		{BenchmarkMetricAllocsPerOp, "allocs/op", baseline.AllocsPerOp, m.AllocsPerOp},
+	} {
+		if metric.baseline.Runs == 0 {
+			continue
+		}
+		delta := metric.value - metric.baseline.Mean
+		var significant bool
+		if metric.baseline.Mean == 0 {
+			// There's no relative change from zero, e.g. for a benchmark starting to allocate.
+			significant = delta != 0
+		} else {
+			change := delta / metric.baseline.Mean * 100
+			c.Changes[metric.name] = change
+			significant = math.Abs(change) > threshold
+		}
+		if !significant || math.Abs(delta) <= benchmarkRegressionStdDevs*metric.baseline.StdDev {
+			continue
+		}
+		if delta < 0 {
+			improved = true
+			continue
+		}
+		c.RegressedMetrics = append(c.RegressedMetrics, metric.name)
+		detail := fmt.Sprintf("%s %.2f vs %.2f", metric.unit, metric.value, metric.baseline.Mean)
+		if change, ok := c.Changes[metric.name]; ok {
+			detail += fmt.Sprintf(" (%+.2f%%)", change)
+		}
+		c.Details = append(c.Details, detail)
+	}
+	if len(c.RegressedMetrics) > 0 {
+		c.Status = constants.BenchmarkRegressionStatusRegression
+	} else if improved {
+		c.Status = constants.BenchmarkRegressionStatusImprovement
+	}
+	return c
+}
+
+// BenchmarkResultsRecorder records the results of benchmark runs to produce a baseline.
+// The zero value is ready to use.
+type BenchmarkResultsRecorder struct {
+	mu sync.Mutex
+	// runs holds the runs of every benchmark, by module, suite and benchmark name.
+	runs map[string]map[string]map[string][]BenchmarkMeasure
+}
+
+// Add records a benchmark run.
+func (r *BenchmarkResultsRecorder) Add(module, suite, name string, m BenchmarkMeasure) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.runs == nil {
+		r.runs = map[string]map[string]map[string][]BenchmarkMeasure{}
+	}
+	if r.runs[module] == nil {
+		r.runs[module] = map[string]map[string][]BenchmarkMeasure{}
+	}
+	if r.runs[module][suite] == nil {
+		r.runs[module][suite] = map[string][]BenchmarkMeasure{}
+	}
+	r.runs[module][suite][name] = append(r.runs[module][suite][name], m)
+}
+
+// Baseline returns the statistics of the recorded runs in the baseline format.
+func (r *BenchmarkResultsRecorder) Baseline() *net.BenchmarkBaselineResponseData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	baseline := &net.BenchmarkBaselineResponseData{Benchmarks: net.BenchmarkBaselineResponseDataModules{}}
+	for module, suites := range r.runs {
+		baseline.Benchmarks[module] = net.BenchmarkBaselineResponseDataSuites{}
+		for suite, benchmarks := range suites {
+			baseline.Benchmarks[module][suite] = net.BenchmarkBaselineResponseDataBenchmarks{}
+			for name, runs := range benchmarks {
+				baseline.Benchmarks[module][suite][name] = net.BenchmarkBaselineStats{
+					NsPerOp:     benchmarkMetricStats(runs, func(m BenchmarkMeasure) float64 { return m.NsPerOp }),
+					BytesPerOp:  benchmarkMetricStats(runs, func(m BenchmarkMeasure) float64 { return m.BytesPerOp }),
+					AllocsPerOp: benchmarkMetricStats(runs, func(m BenchmarkMeasure) float64 { return m.AllocsPerOp }),
+				}
+			}
+		}
+	}
+	return baseline
+}
+
+// benchmarkMetricStats returns the mean and the sample standard deviation of a metric over runs.
+func benchmarkMetricStats(runs []BenchmarkMeasure, metric func(BenchmarkMeasure) float64) net.BenchmarkBaselineMetric {
+	stats := net.BenchmarkBaselineMetric{Runs: len(runs)}
+	if len(runs) == 0 {
+		return stats
+	}
+	for _, m := range runs {
+		stats.Mean += metric(m)
+	}
+	stats.Mean /= float64(len(runs))
+	if len(runs) > 1 {
+		var sum float64
+		for _, m := range runs {
+			d := metric(m) - stats.Mean
+			sum += d * d
+		}
+		stats.StdDev = math.Sqrt(sum / float64(len(runs)-1))
+	}
+	return stats
+}
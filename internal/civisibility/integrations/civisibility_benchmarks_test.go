@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package integrations
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/net"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareBenchmark(t *testing.T) {
+	baseline := net.BenchmarkBaselineStats{
+		NsPerOp:     net.BenchmarkBaselineMetric{Mean: 1000, StdDev: 20, Runs: 5},
+		BytesPerOp:  net.BenchmarkBaselineMetric{Mean: 64, Runs: 5},
+		AllocsPerOp: net.BenchmarkBaselineMetric{Mean: 0, Runs: 5},
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		c := CompareBenchmark(baseline, BenchmarkMeasure{NsPerOp: 1050, BytesPerOp: 64}, 10)
+		assert.Equal(t, constants.BenchmarkRegressionStatusUnchanged, c.Status)
+		assert.Empty(t, c.RegressedMetrics)
+		assert.InDelta(t, 5.0, c.Changes[BenchmarkMetricNsPerOp], 0.001)
+		assert.Equal(t, 0.0, c.Changes[BenchmarkMetricBytesPerOp])
+		assert.NotContains(t, c.Changes, BenchmarkMetricAllocsPerOp)
+	})
+
+	t.Run("regression", func(t *testing.T) {
+		c := CompareBenchmark(baseline, BenchmarkMeasure{NsPerOp: 1250, BytesPerOp: 64, AllocsPerOp: 1}, 10)
+		assert.Equal(t, constants.BenchmarkRegressionStatusRegression, c.Status)
+		assert.Equal(t, []string{BenchmarkMetricNsPerOp, BenchmarkMetricAllocsPerOp}, c.RegressedMetrics)
+		assert.Equal(t, []string{"ns/op 1250.00 vs 1000.00 (+25.00%)", "allocs/op 1.00 vs 0.00"}, c.Details)
+	})
+
+	t.Run("improvement", func(t *testing.T) {
+		c := CompareBenchmark(baseline, BenchmarkMeasure{NsPerOp: 800, BytesPerOp: 32}, 10)
+		assert.Equal(t, constants.BenchmarkRegressionStatusImprovement, c.Status)
+		assert.Empty(t, c.RegressedMetrics)
+		assert.InDelta(t, -50.0, c.Changes[BenchmarkMetricBytesPerOp], 0.001)
+	})
+
+	t.Run("noisy", func(t *testing.T) {
+		// A change above the threshold but within two standard deviations isn't significant.
+		noisy := net.BenchmarkBaselineStats{NsPerOp: net.BenchmarkBaselineMetric{Mean: 1000, StdDev: 200, Runs: 5}}
+		c := CompareBenchmark(noisy, BenchmarkMeasure{NsPerOp: 1300}, 10)
+		assert.Equal(t, constants.BenchmarkRegressionStatusUnchanged, c.Status)
+	})
+
+	t.Run("threshold", func(t *testing.T) {
+		c := CompareBenchmark(baseline, BenchmarkMeasure{NsPerOp: 1250, BytesPerOp: 64}, 30)
+		assert.Equal(t, constants.BenchmarkRegressionStatusUnchanged, c.Status)
+	})
+}
+
+func TestBenchmarkResultsRecorder(t *testing.T) {
+	var r BenchmarkResultsRecorder
+	r.Add("module", "suite", "BenchmarkOne", BenchmarkMeasure{NsPerOp: 100, BytesPerOp: 8, AllocsPerOp: 1})
+	r.Add("module", "suite", "BenchmarkOne", BenchmarkMeasure{NsPerOp: 200, BytesPerOp: 8, AllocsPerOp: 1})
+	r.Add("module", "suite", "BenchmarkTwo", BenchmarkMeasure{NsPerOp: 50})
+
+	baseline := r.Baseline()
+	one, ok := baseline.Get("module", "suite", "BenchmarkOne")
+	require.True(t, ok)
+	assert.Equal(t, net.BenchmarkBaselineMetric{Mean: 150, StdDev: 70.71067811865476, Runs: 2}, one.NsPerOp)
+	assert.Equal(t, net.BenchmarkBaselineMetric{Mean: 8, Runs: 2}, one.BytesPerOp)
+	two, ok := baseline.Get("module", "suite", "BenchmarkTwo")
+	require.True(t, ok)
+	assert.Equal(t, net.BenchmarkBaselineMetric{Mean: 50, Runs: 1}, two.NsPerOp)
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, WriteBenchmarkBaselineFile(path, baseline))
+	loaded, err := LoadBenchmarkBaselineFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, baseline, loaded)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils"
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/impactedtests"
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/net"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/offline"
 	"github.com/DataDog/dd-trace-go/v2/internal/log"
 )
 
@@ -72,6 +73,14 @@ func ensureSettingsInitialization(serviceName string) {
 	settingsInitializationOnce.Do(func() {
 		log.Debug("civisibility: initializing settings")
 
+		// In offline mode there's no network access: the settings are not requested, which disables
+		// all the additional features, and the git metadata is uploaded by the offlineupload command
+		// along with the recorded payloads, see UploadRepositoryChanges.
+		if offline.Enabled() {
+			log.Debug("civisibility: offline mode enabled, skipping the settings request and the git upload")
+			return
+		}
+
 		// Create the CI Visibility client
 		ciVisibilityClient = net.NewClientWithServiceName(serviceName)
 		if ciVisibilityClient == nil {
@@ -82,7 +91,7 @@ func ensureSettingsInitialization(serviceName string) {
 		// upload the repository changes
 		var uploadChannel = make(chan struct{})
 		go func() {
-			bytes, err := uploadRepositoryChanges()
+			bytes, err := uploadRepositoryChanges(ciVisibilityClient)
 			if err != nil {
 				log.Error("civisibility: error uploading repository changes: %v", err)
 			} else {
@@ -316,9 +325,16 @@ func GetImpactedTestsAnalyzer() *impactedtests.ImpactedTestAnalyzer {
 	return ciVisibilityImpactedTestsAnalyzer
 }
 
-func uploadRepositoryChanges() (bytes int64, err error) {
+// UploadRepositoryChanges uploads the commits of the current git repository which are missing on the backend with
+// the given client. It's done when the settings are initialized, except in offline mode where the offlineupload
+// command does it before sending the recorded payloads.
+func UploadRepositoryChanges(client net.Client) (bytes int64, err error) {
+	return uploadRepositoryChanges(client)
+}
+
+func uploadRepositoryChanges(client net.Client) (bytes int64, err error) {
 	// get the search commits response
-	initialCommitData, err := getSearchCommits()
+	initialCommitData, err := getSearchCommits(client)
 	if err != nil {
 		return 0, fmt.Errorf("civisibility: error getting the search commits response: %s", err.Error())
 	}
@@ -353,11 +369,11 @@ func uploadRepositoryChanges() (bytes int64, err error) {
 		// the initial commit data
 
 		// send the pack file with the missing commits
-		return sendObjectsPackFile(initialCommitData.LocalCommits[0], initialCommitData.missingCommits(), initialCommitData.RemoteCommits)
+		return sendObjectsPackFile(client, initialCommitData.LocalCommits[0], initialCommitData.missingCommits(), initialCommitData.RemoteCommits)
 	}
 
 	// after unshallowing the repository we need to get the search commits to calculate the missing commits again
-	commitsData, err := getSearchCommits()
+	commitsData, err := getSearchCommits(client)
 	if err != nil {
 		return 0, fmt.Errorf("civisibility: error getting the search commits response: %s", err.Error())
 	}
@@ -368,11 +384,11 @@ func uploadRepositoryChanges() (bytes int64, err error) {
 	}
 
 	// send the pack file with the missing commits
-	return sendObjectsPackFile(commitsData.LocalCommits[0], commitsData.missingCommits(), commitsData.RemoteCommits)
+	return sendObjectsPackFile(client, commitsData.LocalCommits[0], commitsData.missingCommits(), commitsData.RemoteCommits)
 }
 
 // getSearchCommits gets the search commits response with the local and remote commits
-func getSearchCommits() (*searchCommitsResponse, error) {
+func getSearchCommits(client net.Client) (*searchCommitsResponse, error) {
 	localCommits := utils.GetLastLocalGitCommitShas()
 	if len(localCommits) == 0 {
 		log.Debug("civisibility: no local commits found")
@@ -380,7 +396,7 @@ func getSearchCommits() (*searchCommitsResponse, error) {
 	}
 
 	log.Debug("civisibility: local commits found: %d", len(localCommits))
-	remoteCommits, err := ciVisibilityClient.GetCommits(localCommits)
+	remoteCommits, err := client.GetCommits(localCommits)
 	return newSearchCommitsResponse(localCommits, remoteCommits, true), err
 }
 
@@ -410,7 +426,7 @@ func (r *searchCommitsResponse) missingCommits() []string {
 	return missingCommits
 }
 
-func sendObjectsPackFile(commitSha string, commitsToInclude []string, commitsToExclude []string) (bytes int64, err error) {
+func sendObjectsPackFile(client net.Client, commitSha string, commitsToInclude []string, commitsToExclude []string) (bytes int64, err error) {
 	// get the pack files to send
 	packFiles := utils.CreatePackFiles(commitsToInclude, commitsToExclude)
 	if len(packFiles) == 0 {
@@ -430,5 +446,5 @@ func sendObjectsPackFile(commitSha string, commitsToInclude []string, commitsToE
 	}(packFiles)
 
 	// send the pack files
-	return ciVisibilityClient.SendPackFiles(commitSha, packFiles)
+	return client.SendPackFiles(commitSha, packFiles)
 }
@@ -0,0 +1,105 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package gotesting
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/dd-trace-go/v2/internal"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/integrations"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/net"
+	"github.com/DataDog/dd-trace-go/v2/internal/log"
+)
+
+// defaultBenchmarkRegressionThreshold is the default relative change, in percent, from which
+// a benchmark metric is considered to have regressed or improved.
+const defaultBenchmarkRegressionThreshold = 10.0
+
+var (
+	// benchmarkRegression holds the benchmark regression detection configuration of the session
+	benchmarkRegression benchmarkRegressionConfig
+
+	// benchmarkResults records the benchmark results of the session to be written as a baseline
+	benchmarkResults integrations.BenchmarkResultsRecorder
+)
+
+// benchmarkRegressionConfig controls the benchmark regression detection.
+type benchmarkRegressionConfig struct {
+	// enabled indicates whether the benchmark results are compared to the baseline.
+	enabled bool
+	// threshold is the relative change, in percent, from which a metric has changed.
+	threshold float64
+	// fail indicates whether the benchmarks regressing fail.
+	fail bool
+	// output is the path of the file the results of the session are written to as a baseline.
+	output string
+	// baseline holds the results the benchmarks are compared to, if any.
+	baseline *net.BenchmarkBaselineResponseData
+}
+
+// benchmarkRegressionConfigFromEnv returns the benchmark regression detection configuration
+// from the environment, applying defaults as needed.
+func benchmarkRegressionConfigFromEnv() benchmarkRegressionConfig {
+	cfg := benchmarkRegressionConfig{
+		enabled:   internal.BoolEnv(constants.CIVisibilityBenchmarkRegressionEnabledEnvironmentVariable, false),
+		threshold: internal.FloatEnv(constants.CIVisibilityBenchmarkRegressionThresholdEnvironmentVariable, defaultBenchmarkRegressionThreshold),
+		fail:      internal.BoolEnv(constants.CIVisibilityBenchmarkRegressionFailEnvironmentVariable, false),
+		output:    os.Getenv(constants.CIVisibilityBenchmarkBaselineOutputEnvironmentVariable),
+	}
+	if cfg.threshold < 0 {
+		log.Warn("civisibility: invalid benchmark regression threshold %v, using the default %v", cfg.threshold, defaultBenchmarkRegressionThreshold)
+		cfg.threshold = defaultBenchmarkRegressionThreshold
+	}
+	return cfg
+}
+
+// checkBenchmarkRegression records the result of a benchmark run, compares it to the baseline and tags
+// the test with the outcome. It returns an error message if the benchmark regressed and should fail.
+func checkBenchmarkRegression(test integrations.Test, info *commonInfo, result testing.BenchmarkResult) string {
+	m := integrations.BenchmarkMeasure{
+		NsPerOp:     float64(result.NsPerOp()),
+		BytesPerOp:  float64(result.AllocedBytesPerOp()),
+		AllocsPerOp: float64(result.AllocsPerOp()),
+	}
+	if benchmarkRegression.output != "" {
+		benchmarkResults.Add(info.moduleName, info.suiteName, info.testName, m)
+	}
+	if !benchmarkRegression.enabled {
+		return ""
+	}
+	baseline, ok := benchmarkRegression.baseline.Get(info.moduleName, info.suiteName, info.testName)
+	if !ok {
+		return ""
+	}
+
+	c := integrations.CompareBenchmark(baseline, m, benchmarkRegression.threshold)
+	test.SetTag(constants.BenchmarkRegressionStatus, c.Status)
+	for metric, change := range c.Changes {
+		test.SetTag(constants.BenchmarkRegressionChangePrefix+metric, change)
+	}
+	if len(c.RegressedMetrics) == 0 {
+		return ""
+	}
+	test.SetTag(constants.BenchmarkRegressionMetrics, strings.Join(c.RegressedMetrics, ","))
+	if !benchmarkRegression.fail {
+		return ""
+	}
+	return fmt.Sprintf("benchmark regression compared to the baseline: %s", strings.Join(c.Details, ", "))
+}
+
+// writeBenchmarkBaseline writes the benchmark results of the session to the configured output file, if any.
+func writeBenchmarkBaseline() {
+	if benchmarkRegression.output == "" {
+		return
+	}
+	if err := integrations.WriteBenchmarkBaselineFile(benchmarkRegression.output, benchmarkResults.Baseline()); err != nil {
+		log.Error("civisibility: error writing the benchmark baseline file: %v", err)
+	}
+}
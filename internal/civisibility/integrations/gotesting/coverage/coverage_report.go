@@ -0,0 +1,364 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package coverage
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils"
+	"github.com/DataDog/dd-trace-go/v2/internal/log"
+	"github.com/DataDog/dd-trace-go/v2/internal/version"
+)
+
+// reportLockTimeout is the time to wait for the test binaries of the run to release the coverage reports,
+// after which the lock is considered left over by a test binary which didn't complete.
+const reportLockTimeout = 30 * time.Second
+
+// lineCoverage holds the hit count of each line, by file path relative to the source root.
+type lineCoverage map[string]map[int]int
+
+// ReportsEnabled returns whether line coverage reports are written at the end of the tests.
+func ReportsEnabled() bool {
+	return os.Getenv(constants.CIVisibilityCoverageLcovOutputEnvironmentVariable) != "" ||
+		os.Getenv(constants.CIVisibilityCoverageCoberturaOutputEnvironmentVariable) != ""
+}
+
+// WriteReports writes the configured LCOV and Cobertura line coverage reports, merging the coverage of the
+// test binary with the coverage of the test binaries run before by the same go test command.
+func WriteReports() {
+	lcovPath := os.Getenv(constants.CIVisibilityCoverageLcovOutputEnvironmentVariable)
+	coberturaPath := os.Getenv(constants.CIVisibilityCoverageCoberturaOutputEnvironmentVariable)
+	if lcovPath == "" && coberturaPath == "" {
+		return
+	}
+	if tearDown == nil {
+		log.Debug("civisibility.coverage: runtime coverage not initialized, the coverage reports can't be written")
+		return
+	}
+	if modulePath == "" {
+		loadModuleInfo()
+	}
+
+	profile, err := os.CreateTemp("", "coverage-report")
+	if err != nil {
+		log.Error("civisibility.coverage: error creating the coverage file: %v", err)
+		return
+	}
+	_ = profile.Close()
+	defer os.Remove(profile.Name())
+
+	if _, err := tearDown(profile.Name(), ""); err != nil {
+		log.Error("civisibility.coverage: error getting coverage file: %v", err)
+		return
+	}
+	blocks, err := parseCoverProfile(profile.Name())
+	if err != nil {
+		log.Error("civisibility.coverage: error parsing coverage file: %v", err)
+		return
+	}
+
+	if err := mergeReports(lcovPath, coberturaPath, newLineCoverage(blocks)); err != nil {
+		log.Error("civisibility.coverage: error writing the coverage reports: %v", err)
+	}
+}
+
+// newLineCoverage returns the line coverage of the blocks of a coverage profile. A line is hit as many
+// times as the most executed block it belongs to.
+func newLineCoverage(blocks map[string][]coverageBlock) lineCoverage {
+	lc := lineCoverage{}
+	for fileName, fileBlocks := range blocks {
+		lines := map[int]int{}
+		for _, block := range fileBlocks {
+			for line := block.startLine; line <= block.endLine; line++ {
+				lines[line] = max(lines[line], block.count)
+			}
+		}
+		lc[getRelativePathFromCITagsSourceRootForCoverage(fileName)] = lines
+	}
+	return lc
+}
+
+// merge adds the hit counts of other to lc.
+func (lc lineCoverage) merge(other lineCoverage) {
+	for file, lines := range other {
+		if lc[file] == nil {
+			lc[file] = map[int]int{}
+		}
+		for line, hits := range lines {
+			lc[file][line] += hits
+		}
+	}
+}
+
+// stats returns the number of lines and of lines hit of a file, or of all the files if file is empty.
+func (lc lineCoverage) stats(file string) (valid, covered int) {
+	for f, lines := range lc {
+		if file != "" && f != file {
+			continue
+		}
+		for _, hits := range lines {
+			valid++
+			if hits > 0 {
+				covered++
+			}
+		}
+	}
+	return valid, covered
+}
+
+// writeLCOV writes the line coverage in the LCOV trace file format.
+func (lc lineCoverage) writeLCOV(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, file := range sortedKeys(lc) {
+		fmt.Fprintf(bw, "TN:\nSF:%s\n", file)
+		for _, line := range sortedKeys(lc[file]) {
+			fmt.Fprintf(bw, "DA:%d,%d\n", line, lc[file][line])
+		}
+		valid, covered := lc.stats(file)
+		fmt.Fprintf(bw, "LF:%d\nLH:%d\nend_of_record\n", valid, covered)
+	}
+	return bw.Flush()
+}
+
+type (
+	// coberturaCoverage is the root element of a Cobertura report.
+	coberturaCoverage struct {
+		XMLName         xml.Name           `xml:"coverage"`
+		LineRate        float64            `xml:"line-rate,attr"`
+		BranchRate      float64            `xml:"branch-rate,attr"`
+		LinesCovered    int                `xml:"lines-covered,attr"`
+		LinesValid      int                `xml:"lines-valid,attr"`
+		BranchesCovered int                `xml:"branches-covered,attr"`
+		BranchesValid   int                `xml:"branches-valid,attr"`
+		Complexity      float64            `xml:"complexity,attr"`
+		Version         string             `xml:"version,attr"`
+		Timestamp       int64              `xml:"timestamp,attr"`
+		Sources         []string           `xml:"sources>source"`
+		Packages        []coberturaPackage `xml:"packages>package"`
+	}
+
+	// coberturaPackage holds the classes of a directory.
+	coberturaPackage struct {
+		Name       string           `xml:"name,attr"`
+		LineRate   float64          `xml:"line-rate,attr"`
+		BranchRate float64          `xml:"branch-rate,attr"`
+		Complexity float64          `xml:"complexity,attr"`
+		Classes    []coberturaClass `xml:"classes>class"`
+	}
+
+	// coberturaClass holds the lines of a file.
+	coberturaClass struct {
+		Name       string          `xml:"name,attr"`
+		Filename   string          `xml:"filename,attr"`
+		LineRate   float64         `xml:"line-rate,attr"`
+		BranchRate float64         `xml:"branch-rate,attr"`
+		Complexity float64         `xml:"complexity,attr"`
+		Methods    struct{}        `xml:"methods"`
+		Lines      []coberturaLine `xml:"lines>line"`
+	}
+
+	// coberturaLine holds the hit count of a line.
+	coberturaLine struct {
+		Number int `xml:"number,attr"`
+		Hits   int `xml:"hits,attr"`
+	}
+)
+
+// writeCobertura writes the line coverage in the Cobertura XML format, with a package by directory and
+// a class by file. The file names are relative to source.
+func (lc lineCoverage) writeCobertura(w io.Writer, source string) error {
+	report := coberturaCoverage{
+		Version:   version.Tag,
+		Timestamp: time.Now().UnixMilli(),
+		Sources:   []string{source},
+	}
+	report.LinesValid, report.LinesCovered = lc.stats("")
+	report.LineRate = lineRate(report.LinesValid, report.LinesCovered)
+
+	packages := map[string]*coberturaPackage{}
+	var packageNames []string
+	for _, file := range sortedKeys(lc) {
+		dir := path.Dir(file)
+		pkg, ok := packages[dir]
+		if !ok {
+			pkg = &coberturaPackage{Name: dir}
+			packages[dir] = pkg
+			packageNames = append(packageNames, dir)
+		}
+		class := coberturaClass{Name: path.Base(file), Filename: file}
+		for _, line := range sortedKeys(lc[file]) {
+			class.Lines = append(class.Lines, coberturaLine{Number: line, Hits: lc[file][line]})
+		}
+		class.LineRate = lineRate(lc.stats(file))
+		pkg.Classes = append(pkg.Classes, class)
+	}
+	slices.Sort(packageNames)
+	for _, name := range packageNames {
+		pkg := packages[name]
+		var valid, covered int
+		for _, class := range pkg.Classes {
+			v, c := lc.stats(class.Filename)
+			valid += v
+			covered += c
+		}
+		pkg.LineRate = lineRate(valid, covered)
+		report.Packages = append(report.Packages, *pkg)
+	}
+
+	if _, err := io.WriteString(w, xml.Header+`<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">`+"\n"); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// lineRate returns the ratio of lines covered.
+func lineRate(valid, covered int) float64 {
+	if valid == 0 {
+		return 0
+	}
+	return float64(covered) / float64(valid)
+}
+
+// sortedKeys returns the keys of a map in order.
+func sortedKeys[K string | int, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// reportID returns the identifier of the go test command the reports are written for: the one set with
+// DD_CIVISIBILITY_COVERAGE_REPORT_ID, or the ID of the parent process, i.e. of go test.
+func reportID() string {
+	if id := os.Getenv(constants.CIVisibilityCoverageReportIDEnvironmentVariable); id != "" {
+		sum := sha256.Sum256([]byte(id))
+		return hex.EncodeToString(sum[:8])
+	}
+	return fmt.Sprintf("ppid%d", os.Getppid())
+}
+
+// mergeReports merges lc with the coverage of the test binaries run before by the same go test command,
+// i.e. with the same report ID, and writes the reports. The merged coverage is kept in the temporary
+// directory, and the test binaries of the run take turns using a lock file as they may run concurrently.
+// The merged coverage of the previous commands writing the same reports is removed, as they're done.
+func mergeReports(lcovPath, coberturaPath string, lc lineCoverage) error {
+	sum := sha256.Sum256([]byte(lcovPath + "\n" + coberturaPath))
+	statePrefix := filepath.Join(os.TempDir(), fmt.Sprintf("dd-civisibility-coverage-%s-", hex.EncodeToString(sum[:8])))
+	statePath := statePrefix + reportID()
+	unlock, err := lockFile(statePath + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if previous, err := filepath.Glob(statePrefix + "*.json"); err == nil {
+		for _, file := range previous {
+			if file != statePath+".json" {
+				_ = os.Remove(file)
+			}
+		}
+	}
+
+	merged := lineCoverage{}
+	if data, err := os.ReadFile(statePath + ".json"); err == nil {
+		if err := json.Unmarshal(data, &merged); err != nil {
+			log.Debug("civisibility.coverage: ignoring the invalid merged coverage %s: %v", statePath, err)
+			merged = lineCoverage{}
+		}
+	}
+	merged.merge(lc)
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(statePath+".json", data, 0644); err != nil {
+		return err
+	}
+
+	if lcovPath != "" {
+		if err := writeFileAtomic(lcovPath, merged.writeLCOV); err != nil {
+			return err
+		}
+	}
+	if coberturaPath != "" {
+		source := utils.GetCITags()[constants.CIWorkspacePath]
+		if source == "" {
+			source, _ = os.Getwd()
+		}
+		err := writeFileAtomic(coberturaPath, func(w io.Writer) error {
+			return merged.writeCobertura(w, source)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic writes a file with write, replacing it only once written.
+func writeFileAtomic(name string, write func(io.Writer) error) error {
+	tmp := name + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// lockFile creates the lock file name, waiting for it to be removed if it exists. It returns the function
+// removing the lock file.
+func lockFile(name string) (func(), error) {
+	deadline := time.Now().Add(reportLockTimeout)
+	for {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(name) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > reportLockTimeout {
+			// The lock was left over by a test binary which didn't complete.
+			_ = os.Remove(name)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timeout waiting for the lock %s", name)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,177 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package coverage
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+)
+
+func TestNewLineCoverage(t *testing.T) {
+	blocks := map[string][]coverageBlock{
+		"file1.go": {
+			{startLine: 1, startCol: 0, endLine: 3, endCol: 10, numStmt: 2, count: 2},
+			{startLine: 3, startCol: 12, endLine: 4, endCol: 2, numStmt: 1, count: 0},
+		},
+	}
+
+	lc := newLineCoverage(blocks)
+	lines := lc[getRelativePathFromCITagsSourceRootForCoverage("file1.go")]
+	expected := map[int]int{1: 2, 2: 2, 3: 2, 4: 0}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d", len(expected), len(lines))
+	}
+	for line, hits := range expected {
+		if lines[line] != hits {
+			t.Errorf("Expected line %d to be hit %d times, got %d", line, hits, lines[line])
+		}
+	}
+}
+
+func TestWriteLCOV(t *testing.T) {
+	lc := lineCoverage{
+		"pkg/b.go": {2: 0, 1: 3},
+		"pkg/a.go": {5: 1},
+	}
+
+	var sb strings.Builder
+	if err := lc.writeLCOV(&sb); err != nil {
+		t.Fatalf("writeLCOV returned error: %v", err)
+	}
+
+	expected := `TN:
+SF:pkg/a.go
+DA:5,1
+LF:1
+LH:1
+end_of_record
+TN:
+SF:pkg/b.go
+DA:1,3
+DA:2,0
+LF:2
+LH:1
+end_of_record
+`
+	if sb.String() != expected {
+		t.Errorf("Expected LCOV report:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
+
+func TestWriteCobertura(t *testing.T) {
+	lc := lineCoverage{
+		"pkg/a.go":     {1: 1, 2: 0},
+		"pkg/b.go":     {1: 1},
+		"other/c.go":   {1: 0},
+		"other/d/e.go": {7: 4},
+	}
+
+	var sb strings.Builder
+	if err := lc.writeCobertura(&sb, "/src"); err != nil {
+		t.Fatalf("writeCobertura returned error: %v", err)
+	}
+
+	var report coberturaCoverage
+	if err := xml.Unmarshal([]byte(sb.String()), &report); err != nil {
+		t.Fatalf("invalid Cobertura report: %v\n%s", err, sb.String())
+	}
+	if report.LinesValid != 5 || report.LinesCovered != 3 || report.LineRate != 0.6 {
+		t.Errorf("Expected 3 of 5 lines covered, got %d of %d (%v)", report.LinesCovered, report.LinesValid, report.LineRate)
+	}
+	if len(report.Sources) != 1 || report.Sources[0] != "/src" {
+		t.Errorf("Expected the /src source, got %v", report.Sources)
+	}
+
+	var packages []string
+	for _, pkg := range report.Packages {
+		packages = append(packages, pkg.Name)
+	}
+	if strings.Join(packages, ",") != "other,other/d,pkg" {
+		t.Fatalf("Unexpected packages %v", packages)
+	}
+	pkg := report.Packages[2]
+	if pkg.LineRate != 2.0/3 || len(pkg.Classes) != 2 {
+		t.Fatalf("Unexpected package %+v", pkg)
+	}
+	class := pkg.Classes[0]
+	if class.Name != "a.go" || class.Filename != "pkg/a.go" || class.LineRate != 0.5 {
+		t.Errorf("Unexpected class %+v", class)
+	}
+	if len(class.Lines) != 2 || class.Lines[0] != (coberturaLine{Number: 1, Hits: 1}) || class.Lines[1] != (coberturaLine{Number: 2, Hits: 0}) {
+		t.Errorf("Unexpected lines %+v", class.Lines)
+	}
+}
+
+func TestMergeReports(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv(constants.CIVisibilityCoverageReportIDEnvironmentVariable, "run-1")
+	dir := t.TempDir()
+	lcovPath := filepath.Join(dir, "coverage.lcov")
+	coberturaPath := filepath.Join(dir, "coverage.xml")
+
+	// Two test binaries of the same run covering the same file.
+	if err := mergeReports(lcovPath, coberturaPath, lineCoverage{"pkg/a.go": {1: 1, 2: 0}}); err != nil {
+		t.Fatalf("mergeReports returned error: %v", err)
+	}
+	if err := mergeReports(lcovPath, coberturaPath, lineCoverage{"pkg/a.go": {2: 2}, "pkg/b.go": {1: 0}}); err != nil {
+		t.Fatalf("mergeReports returned error: %v", err)
+	}
+
+	lcov, err := os.ReadFile(lcovPath)
+	if err != nil {
+		t.Fatalf("Expected the LCOV report to be written: %v", err)
+	}
+	if !strings.Contains(string(lcov), "SF:pkg/a.go\nDA:1,1\nDA:2,2\nLF:2\nLH:2\n") || !strings.Contains(string(lcov), "SF:pkg/b.go\nDA:1,0\n") {
+		t.Errorf("Unexpected LCOV report:\n%s", lcov)
+	}
+
+	data, err := os.ReadFile(coberturaPath)
+	if err != nil {
+		t.Fatalf("Expected the Cobertura report to be written: %v", err)
+	}
+	var report coberturaCoverage
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid Cobertura report: %v", err)
+	}
+	if report.LinesValid != 3 || report.LinesCovered != 2 {
+		t.Errorf("Expected 2 of 3 lines covered, got %d of %d", report.LinesCovered, report.LinesValid)
+	}
+
+	entries, err := os.ReadDir(os.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".lock") {
+			t.Errorf("Expected the lock %s to be released", entry.Name())
+		}
+	}
+
+	// The next go test command starts over, and removes the merged coverage of the previous one.
+	t.Setenv(constants.CIVisibilityCoverageReportIDEnvironmentVariable, "run-2")
+	if err := mergeReports(lcovPath, coberturaPath, lineCoverage{"pkg/c.go": {1: 1}}); err != nil {
+		t.Fatalf("mergeReports returned error: %v", err)
+	}
+	lcov, err = os.ReadFile(lcovPath)
+	if err != nil {
+		t.Fatalf("Expected the LCOV report to be written: %v", err)
+	}
+	if strings.Contains(string(lcov), "pkg/a.go") || !strings.Contains(string(lcov), "SF:pkg/c.go\n") {
+		t.Errorf("Unexpected LCOV report:\n%s", lcov)
+	}
+	states, err := filepath.Glob(filepath.Join(os.TempDir(), "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 1 {
+		t.Errorf("Expected the merged coverage of the current command only, got %v", states)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"sync"
 
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/net"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/offline"
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/telemetry"
 	"github.com/DataDog/dd-trace-go/v2/internal/log"
 )
@@ -33,10 +34,18 @@ type coverageWriter struct {
 	payload *coveragePayload // Encodes and buffers events in msgpack format.
 	climit  chan struct{}    // Limits the number of concurrent outgoing connections.
 	wg      sync.WaitGroup   // Waits for all uploads to finish.
+	offline bool             // Writes the payloads to the offline directory instead of sending them.
 }
 
 func newCoverageWriter() *coverageWriter {
 	log.Debug("coverageWriter: creating trace writer instance")
+	if offline.Enabled() {
+		return &coverageWriter{
+			payload: newCoveragePayload(),
+			climit:  make(chan struct{}, concurrentConnectionLimit),
+			offline: true,
+		}
+	}
 	return &coverageWriter{
 		client:  net.NewClientForCodeCoverage(),
 		payload: newCoveragePayload(),
@@ -93,6 +102,12 @@ func (w *coverageWriter) flush() {
 		}
 
 		telemetry.CodeCoverageFiles(float64(p.itemCount()))
+		if w.offline {
+			if _, err := offline.WritePayload(offline.KindCoverage, buf); err != nil {
+				log.Error("coverageWriter: failure writing offline coverage data: %v", err)
+			}
+			return
+		}
 		err = w.client.SendCoveragePayload(buf)
 		if err != nil {
 			log.Error("coverageWriter: failure sending coverage data: %v", err)
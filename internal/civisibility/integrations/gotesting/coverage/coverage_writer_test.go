@@ -10,7 +10,9 @@ import (
 	"io"
 	"testing"
 
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/net"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/offline"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -68,10 +70,30 @@ func TestCoverageWriterFlushError(t *testing.T) {
 	assert.Equal(t, 0, writer.payload.itemCount())
 }
 
+func TestCoverageWriterOffline(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(constants.CIVisibilityOfflineDirEnvironmentVariable, dir)
+	writer := newCoverageWriter()
+	assert.True(t, writer.offline)
+	assert.Nil(t, writer.client)
+
+	coverage := &testCoverage{}
+	writer.add(coverage)
+	writer.stop()
+	assert.Equal(t, 0, writer.payload.itemCount())
+
+	payloads, err := offline.Payloads(dir)
+	assert.NoError(t, err)
+	if assert.Len(t, payloads, 1) {
+		assert.Equal(t, offline.KindCoverage, payloads[0].Kind)
+	}
+}
+
 // MockClient is a mock implementation of the Client interface for testing purposes.
 type MockClient struct {
 	SendCoveragePayloadFunc           func(ciTestCovPayload io.Reader) error
 	SendCoveragePayloadWithFormatFunc func(ciTestCovPayload io.Reader, format string) error
+	SendTestCyclePayloadFunc          func(ciTestCyclePayload io.Reader) error
 	GetSettingsFunc                   func() (*net.SettingsResponseData, error)
 	GetKnownTestsFunc                 func() (*net.KnownTestsResponseData, error)
 	GetCommitsFunc                    func(localCommits []string) ([]string, error)
@@ -79,6 +101,7 @@ type MockClient struct {
 	GetSkippableTestsFunc             func() (correlationId string, skippables map[string]map[string][]net.SkippableResponseDataAttributes, err error)
 	GetTestManagementTestsFunc        func() (*net.TestManagementTestsResponseDataModules, error)
 	GetImpactedTestsFunc              func() (*net.ImpactedTestsDetectionResponse, error)
+	GetBenchmarkBaselineFunc          func() (*net.BenchmarkBaselineResponseData, error)
 }
 
 func (m *MockClient) SendCoveragePayload(ciTestCovPayload io.Reader) error {
@@ -89,6 +112,10 @@ func (m *MockClient) SendCoveragePayloadWithFormat(ciTestCovPayload io.Reader, f
 	return m.SendCoveragePayloadWithFormatFunc(ciTestCovPayload, format)
 }
 
+func (m *MockClient) SendTestCyclePayload(ciTestCyclePayload io.Reader) error {
+	return m.SendTestCyclePayloadFunc(ciTestCyclePayload)
+}
+
 func (m *MockClient) GetSettings() (*net.SettingsResponseData, error) {
 	return m.GetSettingsFunc()
 }
@@ -116,3 +143,7 @@ func (m *MockClient) GetTestManagementTests() (*net.TestManagementTestsResponseD
 func (m *MockClient) GetImpactedTests() (*net.ImpactedTestsDetectionResponse, error) {
 	return m.GetImpactedTestsFunc()
 }
+
+func (m *MockClient) GetBenchmarkBaseline() (*net.BenchmarkBaselineResponseData, error) {
+	return m.GetBenchmarkBaselineFunc()
+}
@@ -17,6 +17,8 @@ import (
 
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/integrations"
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/filebitmap"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/impactedtests"
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/telemetry"
 	"github.com/DataDog/dd-trace-go/v2/internal/log"
 )
@@ -33,6 +35,8 @@ type (
 		CollectCoverageBeforeTestExecution()
 		// CollectCoverageAfterTestExecution collects coverage after test execution.
 		CollectCoverageAfterTestExecution()
+		// SetTestNames sets the names of the module, suite and test, used by the local test impact analysis.
+		SetTestNames(moduleName, suiteName, testName string)
 	}
 
 	// testCoverage holds information about test coverage.
@@ -42,6 +46,9 @@ type (
 		suiteID              uint64
 		testID               uint64
 		testFile             string
+		moduleName           string
+		suiteName            string
+		testName             string
 		preCoverageFilename  string
 		postCoverageFilename string
 		filesCovered         []string
@@ -78,6 +85,9 @@ var (
 	// covWriter is the coverage writer for sending test coverage data to the backend.
 	covWriter *coverageWriter
 
+	// localImpactAnalyzer records the lines covered by each test for the local test impact analysis, if enabled.
+	localImpactAnalyzer *impactedtests.LocalImpactAnalyzer
+
 	// temporaryDir is the temporary directory to store coverage files.
 	temporaryDir string
 	// modulePath is the module path.
@@ -107,19 +117,21 @@ func InitializeCoverage(m *testing.M) {
 		return tDown(coverprofile, gocoverdir)
 	}
 
+	// creating a temp file to store coverage messages that we don't want to print to stdout
+	tempFile, _ = os.CreateTemp("", "coverage")
+
 	// if we cannot collect we bailout early
 	if !CanCollect() {
 		return
 	}
 
-	// creating a temp file to store coverage messages that we don't want to print to stdout
-	tempFile, _ = os.CreateTemp("", "coverage")
-
-	// initializing coverage writer
-	covWriter = newCoverageWriter()
-	integrations.PushCiVisibilityCloseAction(func() {
-		covWriter.stop()
-	})
+	// initializing coverage writer, unless the coverage is only collected for the local test impact analysis
+	if settings := integrations.GetSettings(); settings != nil && settings.CodeCoverage {
+		covWriter = newCoverageWriter()
+		integrations.PushCiVisibilityCloseAction(func() {
+			covWriter.stop()
+		})
+	}
 
 	// create a temporary directory to store coverage files
 	temporaryDir, err = os.MkdirTemp("", "coverage")
@@ -132,6 +144,12 @@ func InitializeCoverage(m *testing.M) {
 		_ = os.RemoveAll(temporaryDir)
 	})
 
+	loadModuleInfo()
+}
+
+// loadModuleInfo loads the path and the directory of the module being tested, used to convert the
+// file names of the coverage profiles to paths.
+func loadModuleInfo() {
 	// executing go list -f '{{.Module.Path}};{{.Module.Dir}}' to get the module path and module dir
 	stdOut, err := exec.Command("go", "list", "-f", "{{.Module.Path}};{{.Module.Dir}}").CombinedOutput()
 	if err != nil {
@@ -145,6 +163,11 @@ func InitializeCoverage(m *testing.M) {
 	}
 }
 
+// SetLocalImpactAnalyzer sets the analyzer recording the lines covered by each test for the local test impact analysis.
+func SetLocalImpactAnalyzer(analyzer *impactedtests.LocalImpactAnalyzer) {
+	localImpactAnalyzer = analyzer
+}
+
 // CanCollect returns whether coverage can be collected.
 func CanCollect() bool {
 	return mode == "count" || mode == "atomic"
@@ -193,6 +216,13 @@ func NewTestCoverage(sessionID, moduleID, suiteID, testID uint64, testFile strin
 	}
 }
 
+// SetTestNames sets the names of the module, suite and test, used by the local test impact analysis.
+func (t *testCoverage) SetTestNames(moduleName, suiteName, testName string) {
+	t.moduleName = moduleName
+	t.suiteName = suiteName
+	t.testName = testName
+}
+
 // CollectCoverageBeforeTestExecution collects coverage before test execution.
 func (t *testCoverage) CollectCoverageBeforeTestExecution() {
 	if !CanCollect() {
@@ -269,7 +299,12 @@ func (t *testCoverage) processCoverageData() {
 		telemetry.CodeCoverageIsEmpty()
 	}
 
-	covWriter.add(t)
+	if covWriter != nil {
+		covWriter.add(t)
+	}
+	if localImpactAnalyzer != nil {
+		localImpactAnalyzer.Record(t.moduleName, t.suiteName, t.testName, getFileBitmapsCovered(t.testFile, preCoverage, postCoverage))
+	}
 
 	err = os.Remove(t.preCoverageFilename)
 	if err != nil {
@@ -399,6 +434,44 @@ func getFilesCovered(testFile string, before, after map[string][]coverageBlock)
 	return result
 }
 
+// getFileBitmapsCovered subtracts the before profile from the after profile and returns the lines covered, by file.
+// The test file itself isn't instrumented, so it's considered covered as a whole.
+func getFileBitmapsCovered(testFile string, before, after map[string][]coverageBlock) impactedtests.LocalTestCoverage {
+	result := impactedtests.LocalTestCoverage{testFile: nil}
+
+	for fileName, afterBlocks := range after {
+		beforeMap := make(map[string]coverageBlock)
+		for _, block := range before[fileName] {
+			key := fmt.Sprintf("%d.%d-%d.%d", block.startLine, block.startCol, block.endLine, block.endCol)
+			beforeMap[key] = block
+		}
+
+		// Collect the blocks executed by the test
+		var covered []coverageBlock
+		maxLine := 0
+		for _, afterBlock := range afterBlocks {
+			key := fmt.Sprintf("%d.%d-%d.%d", afterBlock.startLine, afterBlock.startCol, afterBlock.endLine, afterBlock.endCol)
+			if afterBlock.count-beforeMap[key].count > 0 {
+				covered = append(covered, afterBlock)
+				maxLine = max(maxLine, afterBlock.endLine)
+			}
+		}
+		if len(covered) == 0 || maxLine <= 0 {
+			continue
+		}
+
+		bitmap := filebitmap.FromLineCount(maxLine)
+		for _, block := range covered {
+			for line := max(block.startLine, 1); line <= block.endLine; line++ {
+				bitmap.Set(line)
+			}
+		}
+		result[getRelativePathFromCITagsSourceRootForCoverage(fileName)] = bitmap.GetBuffer()
+	}
+
+	return result
+}
+
 // getRelativePathFromCITagsSourceRootForCoverage returns the relative path from the CI tags source root for coverage
 // by converting a module path to a module directory.
 func getRelativePathFromCITagsSourceRootForCoverage(filePath string) string {
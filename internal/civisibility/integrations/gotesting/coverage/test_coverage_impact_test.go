@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package coverage
+
+import (
+	"testing"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/filebitmap"
+)
+
+func TestSetTestNames(t *testing.T) {
+	tc := NewTestCoverage(1, 2, 3, 4, "/path/to/testfile.go")
+	tc.SetTestNames("module", "suite", "TestName")
+
+	c := tc.(*testCoverage)
+	if c.moduleName != "module" || c.suiteName != "suite" || c.testName != "TestName" {
+		t.Errorf("Unexpected test names %q, %q and %q", c.moduleName, c.suiteName, c.testName)
+	}
+}
+
+func TestGetFileBitmapsCovered(t *testing.T) {
+	before := map[string][]coverageBlock{
+		"file1.go": {
+			{startLine: 1, startCol: 0, endLine: 1, endCol: 10, numStmt: 1, count: 1},
+			{startLine: 3, startCol: 0, endLine: 5, endCol: 10, numStmt: 2, count: 0},
+		},
+	}
+
+	after := map[string][]coverageBlock{
+		"file1.go": {
+			{startLine: 1, startCol: 0, endLine: 1, endCol: 10, numStmt: 1, count: 1},
+			{startLine: 3, startCol: 0, endLine: 5, endCol: 10, numStmt: 2, count: 1},
+		},
+		"file2.go": {
+			{startLine: 2, startCol: 0, endLine: 2, endCol: 10, numStmt: 1, count: 0},
+		},
+		"file3.go": {
+			{startLine: 9, startCol: 0, endLine: 9, endCol: 10, numStmt: 1, count: 2},
+		},
+	}
+
+	bitmaps := getFileBitmapsCovered("testfile.go", before, after)
+	if len(bitmaps) != 3 {
+		t.Fatalf("Expected 3 files covered, got %d", len(bitmaps))
+	}
+	if bitmap, ok := bitmaps["testfile.go"]; !ok || bitmap != nil {
+		t.Errorf("Expected the test file to be covered as a whole, got %v", bitmap)
+	}
+	if _, ok := bitmaps["file2.go"]; ok {
+		t.Errorf("Expected file2.go not to be covered")
+	}
+
+	file1 := filebitmap.NewFileBitmapFromBytes(bitmaps["file1.go"])
+	for line := 1; line <= 5; line++ {
+		if expected := line >= 3; file1.Get(line) != expected {
+			t.Errorf("Expected line %d of file1.go covered to be %v", line, expected)
+		}
+	}
+	file3 := filebitmap.NewFileBitmapFromBytes(bitmaps["file3.go"])
+	if file3.CountActiveBits() != 1 || !file3.Get(9) {
+		t.Errorf("Expected only line 9 of file3.go to be covered, got %s", file3)
+	}
+}
@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package gotesting
+
+import (
+	"testing"
+)
+
+// FuzzCorpusEntries only has passing seed corpus entries.
+func FuzzCorpusEntries(f *testing.F) {
+	ddf := GetFuzz(f)
+	ddf.Add(1)
+	ddf.Add(2)
+	ddf.Fuzz(func(t *testing.T, n int) {
+		if n < 0 {
+			t.Fatalf("unexpected negative number: %d", n)
+		}
+	})
+}
+
+// FuzzFailingEntry has a passing seed corpus entry and a failing one in testdata/fuzz.
+func FuzzFailingEntry(f *testing.F) {
+	ddf := GetFuzz(f)
+	ddf.Add("ok")
+	ddf.Fuzz(func(t *testing.T, s string) {
+		if s == "boom" {
+			t.Fatal("boom")
+		}
+	})
+}
+
+// FuzzSkipped is skipped before running the seed corpus.
+func FuzzSkipped(f *testing.F) {
+	f.Skip("nothing to fuzz")
+}
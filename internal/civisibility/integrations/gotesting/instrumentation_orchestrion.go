@@ -23,6 +23,7 @@ import (
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/integrations"
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/integrations/gotesting/coverage"
 	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils"
+	"github.com/DataDog/dd-trace-go/v2/internal/log"
 )
 
 // ******************************************************************************************************************
@@ -42,6 +43,12 @@ func instrumentTestingM(m *testing.M) func(exitCode int) {
 		return func(_ int) {}
 	}
 
+	// Fuzzing worker processes are spawned by the test process running the fuzz target,
+	// which is the one reporting it.
+	if isFuzzWorker() {
+		return func(_ int) {}
+	}
+
 	// Initialize CI Visibility
 	integrations.EnsureCiVisibilityInitialization()
 
@@ -49,11 +56,17 @@ func instrumentTestingM(m *testing.M) func(exitCode int) {
 	session = integrations.CreateTestSession(integrations.WithTestSessionFramework(testFramework, runtime.Version()))
 
 	settings := integrations.GetSettings()
-	if settings != nil {
-		if settings.CodeCoverage {
-			// Initialize the runtime coverage if enabled.
-			coverage.InitializeCoverage(m)
+
+	// Initialize the local test impact analysis if enabled.
+	initializeLocalImpactAnalysis()
+	if (settings != nil && settings.CodeCoverage) || localImpact != nil || coverage.ReportsEnabled() {
+		// Initialize the runtime coverage if enabled.
+		coverage.InitializeCoverage(m)
+		if localImpact != nil && !coverage.CanCollect() {
+			log.Warn("civisibility: the tests coverage can't be recorded for the local test impact analysis, run the tests with -covermode=count or -covermode=atomic")
 		}
+	}
+	if settings != nil {
 		if settings.TestManagement.Enabled && internal.BoolEnv(constants.CIVisibilityTestManagementEnabledEnvironmentVariable, true) {
 			// Set the test management tag if enabled.
 			session.SetTag(constants.TestManagementEnabled, "true")
@@ -65,6 +78,9 @@ func instrumentTestingM(m *testing.M) func(exitCode int) {
 	// Instrument the internal tests for CI visibility.
 	ddm.instrumentInternalTests(getInternalTestArray(m))
 
+	// Instrument the internal fuzz targets for CI visibility.
+	ddm.instrumentInternalFuzzTargets(getInternalFuzzTargetArray(m))
+
 	// Instrument the internal benchmarks for CI visibility.
 	for _, v := range os.Args {
 		// check if benchmarking is enabled to instrument
@@ -90,8 +106,14 @@ func instrumentTestingM(m *testing.M) func(exitCode int) {
 
 			coveragePercentage := cov * 100
 			session.SetTag(constants.CodeCoveragePercentageOfTotalLines, coveragePercentage)
+
+			// Write the line coverage reports if configured.
+			coverage.WriteReports()
 		}
 
+		// Write the benchmark results as a baseline if configured to.
+		writeBenchmarkBaseline()
+
 		// Close the session and return the exit code.
 		session.Close(exitCode)
 
@@ -226,6 +248,39 @@ func instrumentTestingTFunc(f func(*testing.T)) func(*testing.T) {
 	return instrumentedFn
 }
 
+// instrumentTestingFFunc helper function to instrument a fuzz function passed to `(*testing.F).Fuzz`
+//
+//go:linkname instrumentTestingFFunc
+func instrumentTestingFFunc(f *testing.F, ff any) any {
+	// Check if CI Visibility was disabled using the kill switch before instrumenting
+	if !isCiVisibilityEnabled() || !testing.Testing() {
+		return ff
+	}
+
+	// Only seed corpus entries are reported, inputs generated while fuzzing are way too many.
+	execution := getFuzzTargetExecution(f)
+	if execution == nil || execution.mode != constants.TestFuzzModeCorpus {
+		return ff
+	}
+
+	// Invalid fuzz functions are reported by (*testing.F).Fuzz itself.
+	fn := reflect.ValueOf(ff)
+	if fn.Kind() != reflect.Func || fn.Type().NumIn() == 0 || fn.Type().In(0) != reflect.TypeOf((*testing.T)(nil)) {
+		return ff
+	}
+
+	return reflect.MakeFunc(fn.Type(), func(args []reflect.Value) []reflect.Value {
+		t := args[0].Interface().(*testing.T)
+		if getTestMetadata(t) != nil {
+			// The fuzz function is already instrumented
+			return fn.Call(args)
+		}
+		var results []reflect.Value
+		execution.runEntry(t, func() { results = fn.Call(args) })
+		return results
+	}).Interface()
+}
+
 // instrumentSetErrorInfo helper function to set an error in the `*testing.T, *testing.B, *testing.common` CI Visibility span
 //
 //go:linkname instrumentSetErrorInfo
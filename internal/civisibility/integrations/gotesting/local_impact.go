@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package gotesting
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/DataDog/dd-trace-go/v2/internal"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/integrations"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/integrations/gotesting/coverage"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/impactedtests"
+	"github.com/DataDog/dd-trace-go/v2/internal/log"
+)
+
+// defaultLocalImpactAnalysisBase is the revision the working tree is compared to by default, so that
+// only the tests impacted by the uncommitted changes are run.
+const defaultLocalImpactAnalysisBase = "HEAD"
+
+// localImpact is the local test impact analysis of the session, or nil if it's disabled.
+var localImpact *impactedtests.LocalImpactAnalyzer
+
+// initializeLocalImpactAnalysis initializes the local test impact analysis if enabled, which skips the tests
+// whose covered lines are unchanged using the per test coverage recorded by the previous runs.
+func initializeLocalImpactAnalysis() {
+	if !internal.BoolEnv(constants.CIVisibilityLocalImpactAnalysisEnabledEnvironmentVariable, false) {
+		return
+	}
+
+	dir := os.Getenv(constants.CIVisibilityLocalImpactAnalysisCacheDirEnvironmentVariable)
+	if dir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			log.Warn("civisibility: local test impact analysis disabled, no cache directory: %v", err)
+			return
+		}
+		dir = filepath.Join(cacheDir, "dd-trace-go", "civisibility", "local-impact")
+	}
+	base := os.Getenv(constants.CIVisibilityLocalImpactAnalysisBaseEnvironmentVariable)
+	if base == "" {
+		base = defaultLocalImpactAnalysisBase
+	}
+
+	// The coverage is cached by test binary, i.e. by package directory, as packages are tested concurrently.
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Warn("civisibility: local test impact analysis disabled: %v", err)
+		return
+	}
+	analyzer, err := impactedtests.NewLocalImpactAnalyzer(dir, utils.GetRelativePathFromCITagsSourceRoot(wd), base)
+	if err != nil {
+		log.Warn("civisibility: local test impact analysis disabled: %v", err)
+		return
+	}
+
+	localImpact = analyzer
+	coverage.SetLocalImpactAnalyzer(analyzer)
+	integrations.PushCiVisibilityCloseAction(func() {
+		if err := analyzer.Save(); err != nil {
+			log.Error("civisibility: error saving the local test impact analysis coverage: %v", err)
+		}
+	})
+}
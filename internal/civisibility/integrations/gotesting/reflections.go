@@ -355,3 +355,42 @@ func getBenchmarkPrivateFields(b *testing.B) *benchmarkPrivateFields {
 
 	return benchFields
 }
+
+// ****************
+// FUZZ TARGETS
+// ****************
+
+// fuzzModeFuzzing is the value of testing.fuzzMode when the fuzz target is
+// coordinating fuzzing (go test -fuzz). The zero value means that only the
+// seed corpus is replayed.
+const fuzzModeFuzzing = 1
+
+// getInternalFuzzTargetArray gets the pointer to the testing.InternalFuzzTarget array inside
+// a testing.M instance containing all the "root" fuzz targets
+func getInternalFuzzTargetArray(m *testing.M) *[]testing.InternalFuzzTarget {
+	if ptr, err := getFieldPointerFrom(m, "fuzzTargets"); err == nil && ptr != nil {
+		return (*[]testing.InternalFuzzTarget)(ptr)
+	}
+	return nil
+}
+
+// getFuzzMode gets the value of the testing.F.fstate.mode field (testing.F.fuzzContext.mode
+// in older Go versions) of a testing.F instance
+func getFuzzMode(f *testing.F) (int64, bool) {
+	indirectValue := reflect.Indirect(reflect.ValueOf(f))
+	stateMember := indirectValue.FieldByName("fstate")
+	if !stateMember.IsValid() {
+		stateMember = indirectValue.FieldByName("fuzzContext")
+		if !stateMember.IsValid() {
+			return 0, false
+		}
+	}
+	if stateMember.IsNil() {
+		return 0, false
+	}
+	modeMember := stateMember.Elem().FieldByName("mode")
+	if !modeMember.IsValid() || !modeMember.CanInt() {
+		return 0, false
+	}
+	return modeMember.Int(), true
+}
@@ -35,7 +35,12 @@ func TestMain(m *testing.M) {
 
 	const scenarioStarted = "Scenario %s started.\n"
 	// We need to spawn separated test process for each scenario
-	scenarios := []string{"TestFlakyTestRetries", "TestEarlyFlakeDetection", "TestFlakyTestRetriesAndEarlyFlakeDetection", "TestIntelligentTestRunner", "TestManagementTests", "TestImpactedTests"}
+	scenarios := []string{"TestFlakyTestRetries", "TestEarlyFlakeDetection", "TestFlakyTestRetriesAndEarlyFlakeDetection", "TestIntelligentTestRunner", "TestManagementTests", "TestImpactedTests", "TestFuzzTargets"}
+
+	// Fuzz targets are only executed by their own scenario
+	if !internal.BoolEnv(scenarios[6], false) {
+		*getInternalFuzzTargetArray(m) = nil
+	}
 
 	if internal.BoolEnv(scenarios[0], false) {
 		fmt.Printf(scenarioStarted, scenarios[0])
@@ -55,6 +60,9 @@ func TestMain(m *testing.M) {
 	} else if internal.BoolEnv(scenarios[5], false) {
 		fmt.Printf(scenarioStarted, scenarios[5])
 		runFlakyTestRetriesWithEarlyFlakyTestDetectionTests(m, true)
+	} else if internal.BoolEnv(scenarios[6], false) {
+		fmt.Printf(scenarioStarted, scenarios[6])
+		runFuzzTargetsTests(m)
 	} else {
 		fmt.Println("Starting tests...")
 		for _, v := range scenarios {
@@ -706,6 +714,65 @@ func runTestManagementTests(m *testing.M) {
 	os.Exit(0)
 }
 
+func runFuzzTargetsTests(m *testing.M) {
+	// mock the settings api without any additional feature
+	server := setUpHTTPServer(false, false, false, nil, false, nil, false, nil, false)
+	defer server.Close()
+
+	// only run the fuzz targets
+	*getInternalTestArray(m) = nil
+
+	// initialize the mock tracer for doing assertions on the finished spans
+	currentM = m
+	mTracer = integrations.InitializeCIVisibilityMock()
+
+	// execute the fuzz targets, we are expecting a seed corpus entry to fail
+	exitCode := RunM(m)
+	if exitCode != 1 {
+		panic("expected the exit code to be 1. Got exit code: " + fmt.Sprintf("%d", exitCode))
+	}
+
+	// get all finished spans
+	finishedSpans := mTracer.FinishedSpans()
+	showResourcesNameFromSpans(finishedSpans)
+
+	// 1 session span
+	// 1 module span
+	// 1 suite span (fuzz_test.go)
+	// 2 FuzzCorpusEntries seed corpus entries
+	// 2 FuzzFailingEntry seed corpus entries (one from f.Add and a failing one from testdata/fuzz)
+	// 1 FuzzSkipped
+	checkSpansByType(finishedSpans,
+		8,
+		1,
+		1,
+		1,
+		5,
+		0)
+
+	corpusEntries := checkSpansByResourceName(finishedSpans, "fuzz_test.go.FuzzCorpusEntries", 2)
+	checkSpansByTagValue(corpusEntries, constants.TestFuzzMode, constants.TestFuzzModeCorpus, 2)
+	checkSpansByTagValue(corpusEntries, constants.TestFuzzCorpusEntry, "seed#0", 1)
+	checkSpansByTagValue(corpusEntries, constants.TestFuzzCorpusEntry, "seed#1", 1)
+	checkSpansByTagValue(corpusEntries, constants.TestStatus, constants.TestStatusPass, 2)
+
+	failingEntry := checkSpansByResourceName(finishedSpans, "fuzz_test.go.FuzzFailingEntry", 2)
+	checkSpansByTagValue(failingEntry, constants.TestStatus, constants.TestStatusPass, 1)
+	failed := checkSpansByTagValue(failingEntry, constants.TestStatus, constants.TestStatusFail, 1)
+	checkSpansByTagValue(failed, constants.TestFuzzCorpusEntry, "boom", 1)
+	checkSpansByTagValue(failed, constants.TestFuzzFailingInputPath, "testdata/fuzz/FuzzFailingEntry/boom", 1)
+	// sha256 of testdata/fuzz/FuzzFailingEntry/boom
+	checkSpansByTagValue(failed, constants.TestFuzzFailingInputHash, "e8ba5669f3836ba876c450f719a0e00cfcfd378f8ec367804de405dc9be969dd", 1)
+
+	skipped := checkSpansByResourceName(finishedSpans, "fuzz_test.go.FuzzSkipped", 1)
+	checkSpansByTagValue(skipped, constants.TestFuzzMode, constants.TestFuzzModeCorpus, 1)
+	checkSpansByTagName(skipped, constants.TestFuzzCorpusEntry, 0)
+	checkSpansByTagValue(skipped, constants.TestStatus, constants.TestStatusSkip, 1)
+
+	fmt.Println("All tests passed.")
+	os.Exit(0)
+}
+
 func checkSpansByType(finishedSpans []*mocktracer.Span,
 	totalFinishedSpansCount int, sessionSpansCount int, moduleSpansCount int,
 	suiteSpansCount int, testSpansCount int, normalSpansCount int) {
@@ -38,6 +38,9 @@ var (
 	// benchmarkInfos holds information about the instrumented benchmarks.
 	benchmarkInfos []*testingBInfo
 
+	// fuzzTargetInfos holds information about the instrumented fuzz targets.
+	fuzzTargetInfos []*testingFInfo
+
 	// modulesCounters keeps track of the number of tests per module.
 	modulesCounters = map[string]*int32{}
 
@@ -68,6 +71,12 @@ type (
 		originalFunc func(b *testing.B)
 	}
 
+	// testingFInfo holds information specific to fuzz targets.
+	testingFInfo struct {
+		commonInfo
+		originalFunc func(*testing.F)
+	}
+
 	// M is a wrapper around testing.M to provide instrumentation.
 	M testing.M
 )
@@ -120,6 +129,15 @@ func (ddm *M) instrumentInternalTests(internalTests *[]testing.InternalTest) {
 		}
 	}
 
+	// Tests are also skipped by the local test impact analysis
+	if localImpact != nil {
+		if coverage.CanCollect() {
+			session.SetTag(constants.CodeCoverageEnabled, "true")
+		}
+		session.SetTag(constants.ITRTestsSkippingEnabled, "true")
+		session.SetTag(constants.ITRTestsSkippingType, "test")
+	}
+
 	// Extract info from internal tests
 	testInfos = make([]*testingTInfo, len(*internalTests))
 	for idx, test := range *internalTests {
@@ -168,7 +186,7 @@ func (ddm *M) executeInternalTest(testInfo *testingTInfo) func(*testing.T) {
 
 	// Get the settings response for this session
 	settings := integrations.GetSettings()
-	coverageEnabled := settings.CodeCoverage
+	coverageEnabled := settings.CodeCoverage || localImpact != nil
 	testSkippedByITR := false
 	testIsNew := true
 
@@ -185,6 +203,11 @@ func (ddm *M) executeInternalTest(testInfo *testingTInfo) func(*testing.T) {
 		}
 	}
 
+	// Check if the test is going to be skipped by the local test impact analysis
+	if !testSkippedByITR && localImpact != nil {
+		testSkippedByITR = localImpact.IsUnchanged(testInfo.moduleName, testInfo.suiteName, testInfo.testName)
+	}
+
 	// Check if the test is known
 	if settings.KnownTestsEnabled {
 		testIsKnown, testKnownDataOk := isKnownTest(&testInfo.commonInfo)
@@ -251,6 +274,7 @@ func (ddm *M) executeInternalTest(testInfo *testingTInfo) func(*testing.T) {
 				suite.SuiteID(),
 				test.TestID(),
 				testFile)
+			tCoverage.SetTestNames(testInfo.moduleName, testInfo.suiteName, testInfo.testName)
 
 			// now we need to disable parallelism for the test in order to collect the test coverage
 			tParent := getTestParentPrivateFields(t)
@@ -353,6 +377,13 @@ func (ddm *M) instrumentInternalBenchmarks(internalBenchmarks *[]testing.Interna
 		return
 	}
 
+	// Load the benchmark regression detection configuration and the baseline if enabled
+	benchmarkRegression = benchmarkRegressionConfigFromEnv()
+	if benchmarkRegression.enabled {
+		benchmarkRegression.baseline = integrations.GetBenchmarkBaseline()
+		session.SetTag(constants.BenchmarkRegressionEnabled, "true")
+	}
+
 	// Extract info from internal benchmarks
 	benchmarkInfos = make([]*testingBInfo, len(*internalBenchmarks))
 	for idx, benchmark := range *internalBenchmarks {
@@ -525,8 +556,16 @@ func (ddm *M) executeInternalBenchmark(benchmarkInfo *testingBInfo) func(*testin
 		}
 		recoverFunc = &panicFunc
 
+		// Compare the results to the baseline, failing the benchmark on regressions if configured to.
+		if !iPfOfB.B.Failed() && !iPfOfB.B.Skipped() {
+			if msg := checkBenchmarkRegression(test, &benchmarkInfo.commonInfo, *results); msg != "" {
+				test.SetError(integrations.WithErrorInfo("BenchmarkRegression", msg, ""))
+				b.Error(msg)
+			}
+		}
+
 		// Normal finalization: determine the benchmark result based on its state.
-		if iPfOfB.B.Failed() {
+		if iPfOfB.B.Failed() || b.Failed() {
 			test.SetTag(ext.Error, true)
 			suite.SetTag(ext.Error, true)
 			module.SetTag(ext.Error, true)
@@ -544,6 +583,142 @@ func (ddm *M) executeInternalBenchmark(benchmarkInfo *testingBInfo) func(*testin
 	return instrumentedInternalFunc
 }
 
+// instrumentInternalFuzzTargets instruments the internal fuzz targets for CI visibility.
+func (ddm *M) instrumentInternalFuzzTargets(internalFuzzTargets *[]testing.InternalFuzzTarget) {
+	if internalFuzzTargets == nil {
+		return
+	}
+
+	// The fuzz target selected with -test.fuzz runs twice: once replaying its seed corpus with
+	// the other fuzz targets, then once more in fuzzing mode.
+	fuzzPattern := getFuzzPattern()
+
+	// Extract info from internal fuzz targets
+	fuzzTargetInfos = make([]*testingFInfo, len(*internalFuzzTargets))
+	for idx, fuzzTarget := range *internalFuzzTargets {
+		moduleName, suiteName := utils.GetModuleAndSuiteName(reflect.Indirect(reflect.ValueOf(fuzzTarget.Fn)).Pointer())
+		fuzzTargetInfo := &testingFInfo{
+			originalFunc: fuzzTarget.Fn,
+			commonInfo: commonInfo{
+				moduleName: moduleName,
+				suiteName:  suiteName,
+				testName:   fuzzTarget.Name,
+			},
+		}
+
+		executions := int32(1)
+		if fuzzPattern != nil && fuzzPattern.MatchString(fuzzTarget.Name) {
+			executions++
+		}
+
+		// Initialize module and suite counters if not already present.
+		if _, ok := modulesCounters[moduleName]; !ok {
+			var v int32
+			modulesCounters[moduleName] = &v
+		}
+		// Increment the test count in the module.
+		atomic.AddInt32(modulesCounters[moduleName], executions)
+
+		if _, ok := suitesCounters[suiteName]; !ok {
+			var v int32
+			suitesCounters[suiteName] = &v
+		}
+		// Increment the test count in the suite.
+		atomic.AddInt32(suitesCounters[suiteName], executions)
+
+		fuzzTargetInfos[idx] = fuzzTargetInfo
+	}
+
+	// Create new instrumented internal fuzz targets
+	newFuzzTargetArray := make([]testing.InternalFuzzTarget, len(*internalFuzzTargets))
+	for idx, fuzzTargetInfo := range fuzzTargetInfos {
+		newFuzzTargetArray[idx] = testing.InternalFuzzTarget{
+			Name: fuzzTargetInfo.testName,
+			Fn:   ddm.executeInternalFuzzTarget(fuzzTargetInfo),
+		}
+	}
+	*internalFuzzTargets = newFuzzTargetArray
+}
+
+// executeInternalFuzzTarget wraps the original fuzz target function to include CI visibility instrumentation.
+//
+// When replaying the seed corpus, every corpus entry is reported as an execution of the fuzz target by
+// the instrumented F.Fuzz (see instrumentTestingFFunc), so the fuzz target itself is only reported when
+// no entry was executed: in fuzzing mode, or when the fuzz target was skipped or failed before calling F.Fuzz.
+func (ddm *M) executeInternalFuzzTarget(fuzzTargetInfo *testingFInfo) func(*testing.F) {
+	originalFunc := runtime.FuncForPC(reflect.Indirect(reflect.ValueOf(fuzzTargetInfo.originalFunc)).Pointer())
+
+	return func(f *testing.F) {
+		// Set this func as a helper func of f
+		f.Helper()
+
+		startTime := time.Now()
+		module := session.GetOrCreateModule(fuzzTargetInfo.moduleName)
+		suite := module.GetOrCreateSuite(fuzzTargetInfo.suiteName)
+
+		fuzzMode := constants.TestFuzzModeCorpus
+		if mode, ok := getFuzzMode(f); ok && mode == fuzzModeFuzzing {
+			fuzzMode = constants.TestFuzzModeFuzzing
+		}
+		execution := &fuzzTargetExecution{
+			info:         fuzzTargetInfo,
+			originalFunc: originalFunc,
+			module:       module,
+			suite:        suite,
+			mode:         fuzzMode,
+		}
+		setFuzzTargetExecution(f, execution)
+		defer deleteFuzzTargetExecution(f)
+
+		defer func() {
+			r := recover()
+			if execution.entries.Load() > 0 {
+				checkModuleAndSuite(module, suite)
+				if r != nil {
+					// The panic was reported by the corpus entry which raised it, see runEntry.
+					integrations.ExitCiVisibility()
+					panic(r)
+				}
+				return
+			}
+
+			test := suite.CreateTest(fuzzTargetInfo.testName, integrations.WithTestStartTime(startTime))
+			test.SetTestFunc(originalFunc)
+			test.SetTag(constants.TestFuzzMode, fuzzMode)
+
+			if r != nil {
+				// Handle panic and set error information.
+				test.SetError(integrations.WithErrorInfo("panic", fmt.Sprint(r), utils.GetStacktrace(1)))
+				suite.SetTag(ext.Error, true)
+				module.SetTag(ext.Error, true)
+				test.Close(integrations.ResultStatusFail)
+				checkModuleAndSuite(module, suite)
+				integrations.ExitCiVisibility()
+				panic(r)
+			}
+
+			if f.Failed() {
+				if fuzzMode == constants.TestFuzzModeFuzzing {
+					// The failing input generated by the fuzzer was written to the seed corpus directory.
+					setFailingInputTags(test, findFailingFuzzInput(fuzzTargetInfo.testName, startTime))
+				}
+				test.SetTag(ext.Error, true)
+				suite.SetTag(ext.Error, true)
+				module.SetTag(ext.Error, true)
+				test.Close(integrations.ResultStatusFail)
+			} else if f.Skipped() {
+				test.Close(integrations.ResultStatusSkip)
+			} else {
+				test.Close(integrations.ResultStatusPass)
+			}
+			checkModuleAndSuite(module, suite)
+		}()
+
+		// Execute the original fuzz target function.
+		fuzzTargetInfo.originalFunc(f)
+	}
+}
+
 // RunM runs the tests and benchmarks using CI visibility.
 func RunM(m *testing.M) int {
 	return (*M)(m).Run()
@@ -0,0 +1,208 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package gotesting
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/integrations"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils"
+)
+
+// fuzzCorpusDir is the directory, relative to the package directory, where the
+// go tool reads the seed corpus from and writes the failing inputs found by the fuzzer to.
+const fuzzCorpusDir = "testdata/fuzz"
+
+var (
+	// ciVisibilityFuzzTargetExecutions holds the executions of the running fuzz targets
+	ciVisibilityFuzzTargetExecutions sync.Map // map[*testing.F]*fuzzTargetExecution
+)
+
+// F is a type alias for testing.F to provide additional methods for CI visibility.
+type F testing.F
+
+// GetFuzz is a helper to return *gotesting.F from *testing.F.
+// Internally, it is just a (*gotesting.F)(f) cast.
+func GetFuzz(f *testing.F) *F { return (*F)(f) }
+
+// Fuzz runs the fuzz function, ff, for fuzz testing. Each seed corpus entry
+// is reported as an execution of the fuzz target.
+// See (*testing.F).Fuzz for the requirements on ff.
+func (ddf *F) Fuzz(ff any) {
+	f := (*testing.F)(ddf)
+	f.Helper()
+	f.Fuzz(instrumentTestingFFunc(f, ff))
+}
+
+// Add will add the arguments to the seed corpus for the fuzz test.
+func (ddf *F) Add(args ...any) {
+	f := (*testing.F)(ddf)
+	f.Helper()
+	f.Add(args...)
+}
+
+// fuzzTargetExecution holds the state of a running fuzz target.
+type fuzzTargetExecution struct {
+	info         *testingFInfo
+	originalFunc *runtime.Func
+	module       integrations.TestModule
+	suite        integrations.TestSuite
+	mode         string
+	// entries is the number of seed corpus entries executed.
+	entries atomic.Int32
+}
+
+// setFuzzTargetExecution associates a fuzz target execution with a *testing.F
+func setFuzzTargetExecution(f *testing.F, execution *fuzzTargetExecution) {
+	ciVisibilityFuzzTargetExecutions.Store(f, execution)
+}
+
+// getFuzzTargetExecution retrieves the fuzz target execution associated with a *testing.F
+func getFuzzTargetExecution(f *testing.F) *fuzzTargetExecution {
+	if v, ok := ciVisibilityFuzzTargetExecutions.Load(f); ok {
+		return v.(*fuzzTargetExecution)
+	}
+	return nil
+}
+
+// deleteFuzzTargetExecution deletes the fuzz target execution associated with a *testing.F
+func deleteFuzzTargetExecution(f *testing.F) {
+	ciVisibilityFuzzTargetExecutions.Delete(f)
+}
+
+// runEntry reports the execution of a seed corpus entry, run by fn, as an execution of the fuzz target.
+func (e *fuzzTargetExecution) runEntry(t *testing.T, fn func()) {
+	t.Helper()
+	e.entries.Add(1)
+
+	// Seed corpus entries are named after the fuzz target: "FuzzTarget/seed#0" for the
+	// entries added with F.Add, and "FuzzTarget/<file name>" for the ones of the corpus directory.
+	entry := strings.TrimPrefix(t.Name(), e.info.testName+"/")
+
+	startTime := time.Now()
+	test := e.suite.CreateTest(e.info.testName, integrations.WithTestStartTime(startTime))
+	test.SetTestFunc(e.originalFunc)
+	test.SetTag(constants.TestFuzzMode, e.mode)
+	test.SetTag(constants.TestFuzzCorpusEntry, entry)
+
+	execMeta := createTestMetadata(t)
+	defer deleteTestMetadata(t)
+	execMeta.test = test
+
+	defer func() {
+		if r := recover(); r != nil {
+			// Handle panic and set error information.
+			test.SetError(integrations.WithErrorInfo("panic", fmt.Sprint(r), utils.GetStacktrace(1)))
+			e.suite.SetTag(ext.Error, true)
+			e.module.SetTag(ext.Error, true)
+			test.Close(integrations.ResultStatusFail)
+			// The module and suite counters are updated once by the fuzz target (see
+			// executeInternalFuzzTarget), and ExitCiVisibility closes the module and suite.
+			integrations.ExitCiVisibility()
+			panic(r)
+		}
+
+		if t.Failed() {
+			setFailingInputTags(test, filepath.Join(fuzzCorpusDir, e.info.testName, entry))
+			test.SetTag(ext.Error, true)
+			e.suite.SetTag(ext.Error, true)
+			e.module.SetTag(ext.Error, true)
+			test.Close(integrations.ResultStatusFail)
+		} else if t.Skipped() {
+			test.Close(integrations.ResultStatusSkip)
+		} else {
+			test.Close(integrations.ResultStatusPass)
+		}
+	}()
+
+	fn()
+}
+
+// setFailingInputTags sets the path and the SHA-256 hash of the contents of a failing input file
+// as tags of the test. Nothing is set if the file doesn't exist, e.g. for entries added with F.Add.
+func setFailingInputTags(test integrations.Test, path string) {
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256(data)
+	test.SetTag(constants.TestFuzzFailingInputPath, filepath.ToSlash(path))
+	test.SetTag(constants.TestFuzzFailingInputHash, hex.EncodeToString(sum[:]))
+}
+
+// findFailingFuzzInput returns the path of the most recent file written to the corpus directory
+// of the fuzz target since the given time, or an empty string if there's none.
+func findFailingFuzzInput(name string, since time.Time) string {
+	dir := filepath.Join(fuzzCorpusDir, name)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var path string
+	var modTime time.Time
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().Before(since) || info.ModTime().Before(modTime) {
+			continue
+		}
+		path = filepath.Join(dir, entry.Name())
+		modTime = info.ModTime()
+	}
+	return path
+}
+
+// getFuzzPattern returns the regular expression of the -test.fuzz flag, if any.
+func getFuzzPattern() *regexp.Regexp {
+	for i, arg := range os.Args {
+		var pattern string
+		switch {
+		case strings.HasPrefix(arg, "-test.fuzz="):
+			pattern = strings.TrimPrefix(arg, "-test.fuzz=")
+		case arg == "-test.fuzz" && i+1 < len(os.Args):
+			pattern = os.Args[i+1]
+		default:
+			continue
+		}
+		if pattern == "" {
+			return nil
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil
+		}
+		return re
+	}
+	return nil
+}
+
+// isFuzzWorker returns whether the current process is a fuzzing worker spawned by the go test process.
+func isFuzzWorker() bool {
+	for _, arg := range os.Args {
+		if arg == "-test.fuzzworker" || arg == "-test.fuzzworker=true" {
+			return true
+		}
+	}
+	return false
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
@@ -23,6 +24,9 @@ import (
 // Ensures that tslvTestSession implements the TestSession interface.
 var _ TestSession = (*tslvTestSession)(nil)
 
+// activeTestSession holds the last test session created by CreateTestSession until it's closed.
+var activeTestSession atomic.Pointer[tslvTestSession]
+
 // tslvTestSession implements the DdTestSession interface and represents a session for a set of tests.
 type tslvTestSession struct {
 	ciVisibilityCommon
@@ -102,6 +106,7 @@ func CreateTestSession(options ...TestSessionStartOption) TestSession {
 
 	// Ensure to close everything before CI visibility exits. In CI visibility mode, we try to never lose data.
 	PushCiVisibilityCloseAction(func() { s.Close(1) })
+	activeTestSession.Store(s)
 
 	// Creating telemetry event created
 	testingEventType := telemetry.SessionEventType
@@ -120,6 +125,16 @@ func CreateTestSession(options ...TestSessionStartOption) TestSession {
 	return s
 }
 
+// GetActiveTestSession returns the test session currently open in the process, if any.
+// Integrations of test frameworks running inside a `go test` binary use it to report to the
+// session created for the binary instead of creating a new one.
+func GetActiveTestSession() (TestSession, bool) {
+	if s := activeTestSession.Load(); s != nil {
+		return s, true
+	}
+	return nil, false
+}
+
 // SessionID returns the ID of the test session.
 func (t *tslvTestSession) SessionID() uint64 {
 	return t.sessionID
@@ -166,6 +181,7 @@ func (t *tslvTestSession) Close(exitCode int, options ...TestSessionCloseOption)
 
 	t.span.Finish(tracer.FinishTime(defaults.finishTime))
 	t.closed = true
+	activeTestSession.CompareAndSwap(t, nil)
 
 	// Creating telemetry event finished
 	testingEventType := telemetry.SessionEventType
@@ -401,6 +401,42 @@ func GetGitDiff(baseCommit, headCommit string) (string, error) {
 	return out, nil
 }
 
+// GetGitCommitSha resolves a revision (e.g. HEAD, a branch name or a tag) to its commit SHA.
+func GetGitCommitSha(revision string) (string, error) {
+	// git rev-parse --verify {revision}^{commit}
+	out, err := execGitString(telemetry.GetHeadCommandsType, "rev-parse", "--verify", revision+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("civisibility.git: error resolving %s: %s | %s", revision, err.Error(), out)
+	}
+	return out, nil
+}
+
+// GetGitWorkingTreeDiff retrieves the diff between a commit and the working tree, including both staged and
+// unstaged changes, using the `git diff` command. The output is empty when the working tree matches the commit.
+func GetGitWorkingTreeDiff(baseCommit string) (string, error) {
+	// git diff -U0 --word-diff=porcelain {baseCommit}
+	log.Debug("civisibility.git: getting the diff between %s and the working tree", baseCommit)
+	out, err := execGitString(telemetry.Diff, "diff", "-U0", "--word-diff=porcelain", baseCommit)
+	if err != nil {
+		return "", fmt.Errorf("civisibility.git: error getting the diff from %s to the working tree: %s | %s", baseCommit, err.Error(), out)
+	}
+	return out, nil
+}
+
+// GetGitUntrackedFiles retrieves the files of the working tree which are neither tracked nor ignored by Git,
+// relative to the root of the repository.
+func GetGitUntrackedFiles() ([]string, error) {
+	// git ls-files --others --exclude-standard --full-name
+	out, err := execGitString(telemetry.NotSpecifiedCommandsType, "ls-files", "--others", "--exclude-standard", "--full-name")
+	if err != nil {
+		return nil, fmt.Errorf("civisibility.git: error getting the untracked files: %s | %s", err.Error(), out)
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
 // filterSensitiveInfo removes sensitive information from a given URL using a regular expression.
 // It replaces the user credentials part of the URL (if present) with an empty string.
 //
@@ -49,6 +49,10 @@ var diffHeaderRegex = regexp.MustCompile(`^diff --git a\/(?P<fileA>.+) b\/(?P<fi
 // This regex captures "start" and "count" (if available) from the new file's diff.
 var lineChangeRegex = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(?P<start>\d+)(?:,(?P<count>\d+))? @@`)
 
+// Example: @@ -1,2 +3,4 @@
+// This regex captures "start" and "count" (if available) from the old file's diff.
+var baseLineChangeRegex = regexp.MustCompile(`^@@ -(?P<start>\d+)(?:,(?P<count>\d+))? \+\d+(?:,\d+)? @@`)
+
 // NewImpactedTestAnalyzer creates a new instance of ImpactedTestAnalyzer.
 func NewImpactedTestAnalyzer(client net.Client) (*ImpactedTestAnalyzer, error) {
 	ciTags := utils.GetCITags()
@@ -206,6 +210,24 @@ func getTestImpactInfo(sourceFile string, startLine int, endLine int) []*fileWit
 
 // parseGitDiffOutput parses the git diff output to extract modified files and their changed lines.
 func parseGitDiffOutput(output string) []fileWithBitmap {
+	return parseGitDiffHunks(output, false)
+}
+
+// parseGitDiffBaseOutput parses the git diff output to extract the modified files and their changed lines
+// in the base side of the diff, i.e. the lines which were modified or deleted. Lines inserted in a file mark
+// the base lines they were inserted between.
+func parseGitDiffBaseOutput(output string) []fileWithBitmap {
+	return parseGitDiffHunks(output, true)
+}
+
+// parseGitDiffHunks parses the git diff output to extract modified files and their changed lines, from the
+// base (old) or the new side of the diff.
+func parseGitDiffHunks(output string, base bool) []fileWithBitmap {
+	fileGroup, hunkRegex := "fileB", lineChangeRegex
+	if base {
+		fileGroup, hunkRegex = "fileA", baseLineChangeRegex
+	}
+
 	var fileChanges []fileWithBitmap
 	var currentFile *fileWithBitmap = nil
 	var modifiedLines []lineRange
@@ -227,7 +249,7 @@ func parseGitDiffOutput(output string) []fileWithBitmap {
 			// Extract file path from the named group "file"
 			filePath := ""
 			for i, name := range diffHeaderRegex.SubexpNames() {
-				if name == "fileB" {
+				if name == fileGroup {
 					filePath = headerMatch[i]
 					break
 				}
@@ -237,10 +259,10 @@ func parseGitDiffOutput(output string) []fileWithBitmap {
 		}
 
 		// Check for the line change marker (e.g., @@ -1,2 +3,4 @@)
-		if lineChangeMatch := lineChangeRegex.FindStringSubmatch(line); lineChangeMatch != nil {
+		if lineChangeMatch := hunkRegex.FindStringSubmatch(line); lineChangeMatch != nil {
 			startLineStr := ""
 			countStr := ""
-			for i, name := range lineChangeRegex.SubexpNames() {
+			for i, name := range hunkRegex.SubexpNames() {
 				if name == "start" {
 					startLineStr = lineChangeMatch[i]
 				}
@@ -262,6 +284,9 @@ func parseGitDiffOutput(output string) []fileWithBitmap {
 				if lineCount > 0 {
 					// Adjust the line count to account for the start line
 					lineCount = lineCount - 1
+				} else if base {
+					// Nothing was removed from the base, the lines were inserted after the start line
+					startLine, lineCount = max(startLine, 1), 1
 				}
 			}
 
@@ -0,0 +1,285 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package impactedtests
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils"
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/filebitmap"
+	logger "github.com/DataDog/dd-trace-go/v2/internal/log"
+)
+
+type (
+	// LocalTestCoverage holds the lines covered by a test as file bitmaps, by file path relative to the
+	// repository root. A nil bitmap means the whole file is covered.
+	LocalTestCoverage map[string][]byte
+
+	// LocalCoverage holds the coverage of the tests of a test binary at a commit, by module, suite and test name.
+	LocalCoverage struct {
+		Commit string                                             `json:"commit"`
+		Tests  map[string]map[string]map[string]LocalTestCoverage `json:"tests"`
+	}
+
+	// LocalImpactAnalyzer finds the tests impacted by the changes of the working tree from the per test coverage
+	// recorded locally at a base commit, without the backend. It also records the coverage of the tests run, so
+	// that it can be used as the base of the next runs.
+	LocalImpactAnalyzer struct {
+		mu            sync.Mutex
+		dir           string
+		key           string
+		base          *LocalCoverage
+		modifiedFiles []fileWithBitmap
+		// globalChange is set when a file which isn't reflected by the coverage changed, e.g. go.mod.
+		globalChange bool
+		// current holds the coverage recorded in this run, or nil when the working tree doesn't match a commit.
+		current *LocalCoverage
+	}
+)
+
+// NewLocalImpactAnalyzer creates a new LocalImpactAnalyzer comparing the working tree to baseRevision, using
+// the coverage cached in dir for the test binary identified by key (e.g. its package directory).
+func NewLocalImpactAnalyzer(dir string, key string, baseRevision string) (*LocalImpactAnalyzer, error) {
+	headCommitSha, err := utils.GetGitCommitSha("HEAD")
+	if err != nil {
+		return nil, err
+	}
+	baseCommitSha, err := utils.GetGitCommitSha(baseRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := utils.GetGitWorkingTreeDiff(baseCommitSha)
+	if err != nil {
+		return nil, err
+	}
+	// The coverage was recorded at the base commit, so the modified lines are the ones of the base files.
+	modifiedFiles := parseGitDiffBaseOutput(diff)
+	untrackedFiles, err := utils.GetGitUntrackedFiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range untrackedFiles {
+		modifiedFiles = append(modifiedFiles, fileWithBitmap{file: file})
+	}
+
+	base, err := LoadLocalCoverage(dir, baseCommitSha, key)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		logger.Debug("civisibility.ImpactedTests: no local coverage found for %s", baseCommitSha)
+		base = nil
+	}
+
+	// The coverage recorded in this run is only valid for HEAD if the working tree has no changes.
+	clean := len(modifiedFiles) == 0
+	if baseCommitSha != headCommitSha {
+		diff, err = utils.GetGitWorkingTreeDiff(headCommitSha)
+		clean = err == nil && diff == "" && len(untrackedFiles) == 0
+	}
+	var current *LocalCoverage
+	if clean {
+		current = &LocalCoverage{Commit: headCommitSha}
+	} else {
+		logger.Debug("civisibility.ImpactedTests: the working tree has changes, the local coverage won't be recorded")
+	}
+
+	logger.Debug("civisibility.ImpactedTests: local analysis loaded [from: %s to the working tree]: %v", baseCommitSha, modifiedFiles)
+	return newLocalImpactAnalyzer(dir, key, base, modifiedFiles, current), nil
+}
+
+// newLocalImpactAnalyzer creates a new LocalImpactAnalyzer from the coverage of the base commit and the files
+// modified since.
+func newLocalImpactAnalyzer(dir string, key string, base *LocalCoverage, modifiedFiles []fileWithBitmap, current *LocalCoverage) *LocalImpactAnalyzer {
+	a := &LocalImpactAnalyzer{
+		dir:           dir,
+		key:           key,
+		base:          base,
+		modifiedFiles: modifiedFiles,
+		current:       current,
+	}
+	for _, file := range modifiedFiles {
+		if isGlobalFile(file.file) {
+			logger.Debug("civisibility.ImpactedTests: %s changed, no test is skipped", file.file)
+			a.globalChange = true
+			break
+		}
+	}
+	return a
+}
+
+// isGlobalFile returns whether a change of the file can impact any test without being reflected by the
+// coverage, like the module files or test data.
+func isGlobalFile(file string) bool {
+	switch path.Base(file) {
+	case "go.mod", "go.sum", "go.work", "go.work.sum":
+		return true
+	}
+	return strings.HasPrefix(file, "testdata/") || strings.Contains(file, "/testdata/")
+}
+
+// IsUnchanged returns whether the lines covered by a test at the base commit are unchanged in the working
+// tree, in which case the test can be skipped. Tests without recorded coverage are never unchanged.
+func (a *LocalImpactAnalyzer) IsUnchanged(module string, suite string, test string) bool {
+	if a.base == nil || a.globalChange {
+		return false
+	}
+	coverage, ok := a.base.get(module, suite, test)
+	if !ok {
+		return false
+	}
+
+	// The test files aren't instrumented, so the test helpers of the package (e.g. added in untracked files)
+	// aren't part of the coverage: any change of a test file of the package impacts the test.
+	for _, modifiedFile := range a.modifiedFiles {
+		if !strings.HasSuffix(modifiedFile.file, "_test.go") {
+			continue
+		}
+		for file := range coverage {
+			if strings.HasSuffix(file, "_test.go") && path.Dir(file) == path.Dir(modifiedFile.file) {
+				logger.Debug("civisibility.ImpactedTests: %s changed. Test %s is impacted.", modifiedFile.file, test)
+				return false
+			}
+		}
+	}
+
+	// Has any of the files covered by the test been modified?
+	fileModified := false
+	for file, bitmap := range coverage {
+		for _, modifiedFile := range a.modifiedFiles {
+			if modifiedFile.file != file {
+				continue
+			}
+			fileModified = true
+			if bitmap == nil || modifiedFile.bitmap == nil {
+				return false
+			}
+			if filebitmap.NewFileBitmapFromBytes(bitmap).IntersectsWith(filebitmap.NewFileBitmapFromBytes(modifiedFile.bitmap)) {
+				logger.Debug("civisibility.ImpactedTests: Intersecting lines in %s. Test %s is impacted.", file, test)
+				return false
+			}
+		}
+	}
+
+	// The coverage is carried over to the current commit, unless the lines could have moved.
+	if !fileModified {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.current != nil {
+			if _, ok := a.current.get(module, suite, test); !ok {
+				a.current.set(module, suite, test, coverage)
+			}
+		}
+	}
+	return true
+}
+
+// Record records the coverage of a test run, merging it with the coverage already recorded for the test,
+// e.g. by retries.
+func (a *LocalImpactAnalyzer) Record(module string, suite string, test string, coverage LocalTestCoverage) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.current == nil {
+		return
+	}
+	recorded, ok := a.current.get(module, suite, test)
+	if !ok {
+		a.current.set(module, suite, test, coverage)
+		return
+	}
+	merged := LocalTestCoverage{}
+	for file, bitmap := range recorded {
+		merged[file] = bitmap
+	}
+	for file, bitmap := range coverage {
+		previous, ok := merged[file]
+		switch {
+		case !ok:
+			merged[file] = bitmap
+		case previous == nil || bitmap == nil:
+			merged[file] = nil
+		default:
+			merged[file] = filebitmap.Or(filebitmap.NewFileBitmapFromBytes(previous), filebitmap.NewFileBitmapFromBytes(bitmap), false).GetBuffer()
+		}
+	}
+	a.current.set(module, suite, test, merged)
+}
+
+// Save writes the coverage recorded in this run to the cache, if any.
+func (a *LocalImpactAnalyzer) Save() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.current == nil || len(a.current.Tests) == 0 {
+		return nil
+	}
+	return a.current.Save(a.dir, a.key)
+}
+
+// localCoveragePath returns the path of the coverage file of a test binary at a commit.
+func localCoveragePath(dir string, commit string, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, commit, hex.EncodeToString(sum[:8])+".json")
+}
+
+// LoadLocalCoverage reads the coverage of the test binary identified by key at a commit from the cache in dir.
+func LoadLocalCoverage(dir string, commit string, key string) (*LocalCoverage, error) {
+	data, err := os.ReadFile(localCoveragePath(dir, commit, key))
+	if err != nil {
+		return nil, err
+	}
+	var coverage LocalCoverage
+	if err := json.Unmarshal(data, &coverage); err != nil {
+		return nil, err
+	}
+	return &coverage, nil
+}
+
+// Save writes the coverage of the test binary identified by key to the cache in dir.
+func (c *LocalCoverage) Save(dir string, key string) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	file := localCoveragePath(dir, c.Commit, key)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	// The file is written atomically, as test binaries of other packages may be reading the cache.
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// get returns the coverage of a test.
+func (c *LocalCoverage) get(module string, suite string, test string) (LocalTestCoverage, bool) {
+	coverage, ok := c.Tests[module][suite][test]
+	return coverage, ok
+}
+
+// set sets the coverage of a test.
+func (c *LocalCoverage) set(module string, suite string, test string, coverage LocalTestCoverage) {
+	if c.Tests == nil {
+		c.Tests = map[string]map[string]map[string]LocalTestCoverage{}
+	}
+	if c.Tests[module] == nil {
+		c.Tests[module] = map[string]map[string]LocalTestCoverage{}
+	}
+	if c.Tests[module][suite] == nil {
+		c.Tests[module][suite] = map[string]LocalTestCoverage{}
+	}
+	c.Tests[module][suite][test] = coverage
+}
@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package impactedtests
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/filebitmap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lines(from, to int) []byte {
+	return filebitmap.FromActiveRange(from, to).GetBuffer()
+}
+
+func TestLocalImpactAnalyzerIsUnchanged(t *testing.T) {
+	base := &LocalCoverage{Commit: "base"}
+	base.set("module", "a_test.go", "TestA", LocalTestCoverage{"pkg/a_test.go": nil, "pkg/a.go": lines(10, 20)})
+	base.set("module", "b_test.go", "TestB", LocalTestCoverage{"pkgb/b_test.go": nil, "pkgb/b.go": lines(1, 5)})
+	base.set("module", "c_test.go", "TestC", LocalTestCoverage{"pkg/c_test.go": nil, "pkg/a.go": lines(30, 40)})
+
+	diff := `diff --git a/pkg/a.go b/pkg/a.go
+@@ -15,1 +15,2 @@
+diff --git a/pkgb/b_test.go b/pkgb/b_test.go
+@@ -100,0 +101,3 @@`
+	a := newLocalImpactAnalyzer(t.TempDir(), "pkg", base, parseGitDiffBaseOutput(diff), nil)
+
+	assert.False(t, a.IsUnchanged("module", "a_test.go", "TestA"), "covered lines changed")
+	assert.False(t, a.IsUnchanged("module", "b_test.go", "TestB"), "test file changed")
+	assert.True(t, a.IsUnchanged("module", "c_test.go", "TestC"), "other lines of a covered file changed")
+	assert.False(t, a.IsUnchanged("module", "d_test.go", "TestD"), "no coverage recorded")
+
+	t.Run("global change", func(t *testing.T) {
+		modified := append(parseGitDiffBaseOutput(diff), fileWithBitmap{file: "go.mod"})
+		a := newLocalImpactAnalyzer(t.TempDir(), "pkg", base, modified, nil)
+		assert.False(t, a.IsUnchanged("module", "c_test.go", "TestC"))
+	})
+
+	t.Run("deletion", func(t *testing.T) {
+		diff := `diff --git a/pkg/a.go b/pkg/a.go
+@@ -32,3 +31,0 @@`
+		a := newLocalImpactAnalyzer(t.TempDir(), "pkg", base, parseGitDiffBaseOutput(diff), nil)
+		assert.False(t, a.IsUnchanged("module", "c_test.go", "TestC"), "covered lines deleted")
+		assert.True(t, a.IsUnchanged("module", "a_test.go", "TestA"))
+	})
+
+	t.Run("shifted hunk", func(t *testing.T) {
+		// Lines were inserted before, so the changed base lines 25-26 are the lines 35-36 of the working tree.
+		diff := `diff --git a/pkg/a.go b/pkg/a.go
+@@ -5,0 +6,10 @@
+@@ -25,2 +35,2 @@`
+		a := newLocalImpactAnalyzer(t.TempDir(), "pkg", base, parseGitDiffBaseOutput(diff), nil)
+		assert.True(t, a.IsUnchanged("module", "c_test.go", "TestC"), "the working tree lines of the hunk aren't base lines")
+		assert.True(t, a.IsUnchanged("module", "a_test.go", "TestA"))
+
+		base := &LocalCoverage{Commit: "base"}
+		base.set("module", "e_test.go", "TestE", LocalTestCoverage{"pkg/e_test.go": nil, "pkg/a.go": lines(20, 25)})
+		a = newLocalImpactAnalyzer(t.TempDir(), "pkg", base, parseGitDiffBaseOutput(diff), nil)
+		assert.False(t, a.IsUnchanged("module", "e_test.go", "TestE"), "covered base lines changed")
+	})
+
+	t.Run("test helper", func(t *testing.T) {
+		modified := []fileWithBitmap{{file: "pkg/helpers_test.go"}}
+		a := newLocalImpactAnalyzer(t.TempDir(), "pkg", base, modified, nil)
+		assert.False(t, a.IsUnchanged("module", "c_test.go", "TestC"), "untracked helper of the package")
+
+		modified = []fileWithBitmap{{file: "other/helpers_test.go"}}
+		a = newLocalImpactAnalyzer(t.TempDir(), "pkg", base, modified, nil)
+		assert.True(t, a.IsUnchanged("module", "c_test.go", "TestC"), "helper of another package")
+	})
+
+	t.Run("no base", func(t *testing.T) {
+		a := newLocalImpactAnalyzer(t.TempDir(), "pkg", nil, nil, nil)
+		assert.False(t, a.IsUnchanged("module", "c_test.go", "TestC"))
+	})
+}
+
+func TestLocalImpactAnalyzerRecord(t *testing.T) {
+	dir := t.TempDir()
+	base := &LocalCoverage{Commit: "base"}
+	base.set("module", "a_test.go", "TestA", LocalTestCoverage{"pkg/a_test.go": nil, "pkg/a.go": lines(10, 20)})
+	base.set("module", "c_test.go", "TestC", LocalTestCoverage{"pkg/c_test.go": nil, "pkg/c.go": lines(1, 5)})
+	base.set("module", "d_test.go", "TestD", LocalTestCoverage{"pkg/d_test.go": nil, "pkg/a.go": lines(30, 40)})
+	modified := []fileWithBitmap{{file: "pkg/a.go", bitmap: lines(15, 15)}}
+	a := newLocalImpactAnalyzer(dir, "pkg", base, modified, &LocalCoverage{Commit: "head"})
+
+	// Skipped tests whose files are unchanged are carried over.
+	assert.True(t, a.IsUnchanged("module", "c_test.go", "TestC"))
+	// The lines of a modified file could have moved.
+	assert.True(t, a.IsUnchanged("module", "d_test.go", "TestD"))
+
+	a.Record("module", "a_test.go", "TestA", LocalTestCoverage{"pkg/a_test.go": nil, "pkg/a.go": lines(10, 12)})
+	a.Record("module", "a_test.go", "TestA", LocalTestCoverage{"pkg/a.go": lines(20, 25), "pkg/b.go": lines(1, 1)})
+	require.NoError(t, a.Save())
+
+	saved, err := LoadLocalCoverage(dir, "head", "pkg")
+	require.NoError(t, err)
+	assert.Equal(t, "head", saved.Commit)
+
+	testA, ok := saved.get("module", "a_test.go", "TestA")
+	require.True(t, ok)
+	assert.Nil(t, testA["pkg/a_test.go"])
+	assert.Equal(t, 9, filebitmap.NewFileBitmapFromBytes(testA["pkg/a.go"]).CountActiveBits())
+	assert.Equal(t, lines(1, 1), testA["pkg/b.go"])
+
+	testC, ok := saved.get("module", "c_test.go", "TestC")
+	require.True(t, ok)
+	assert.Equal(t, lines(1, 5), testC["pkg/c.go"])
+
+	_, ok = saved.get("module", "d_test.go", "TestD")
+	assert.False(t, ok)
+
+	_, err = LoadLocalCoverage(dir, "base", "pkg")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestLocalImpactAnalyzerDirtyWorkingTree(t *testing.T) {
+	dir := t.TempDir()
+	a := newLocalImpactAnalyzer(dir, "pkg", nil, nil, nil)
+	a.Record("module", "a_test.go", "TestA", LocalTestCoverage{"pkg/a.go": lines(1, 1)})
+	require.NoError(t, a.Save())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
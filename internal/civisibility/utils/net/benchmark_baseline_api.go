@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package net
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const (
+	benchmarkBaselineRequestType string = "ci_app_libraries_benchmarks_baseline_request"
+	benchmarkBaselineURLPath     string = "api/v2/ci/libraries/benchmarks/baseline"
+)
+
+type (
+	benchmarkBaselineRequest struct {
+		Data benchmarkBaselineRequestHeader `json:"data"`
+	}
+
+	benchmarkBaselineRequestHeader struct {
+		ID         string                       `json:"id"`
+		Type       string                       `json:"type"`
+		Attributes benchmarkBaselineRequestData `json:"attributes"`
+	}
+
+	benchmarkBaselineRequestData struct {
+		Service        string             `json:"service"`
+		Env            string             `json:"env"`
+		RepositoryURL  string             `json:"repository_url"`
+		Configurations testConfigurations `json:"configurations"`
+	}
+
+	benchmarkBaselineResponse struct {
+		Data struct {
+			ID         string                        `json:"id"`
+			Type       string                        `json:"type"`
+			Attributes BenchmarkBaselineResponseData `json:"attributes"`
+		} `json:"data"`
+	}
+
+	// BenchmarkBaselineResponseData holds the benchmark results benchmarks are compared to,
+	// i.e. the latest results of the default branch. It's also the format of the local baseline files.
+	BenchmarkBaselineResponseData struct {
+		Benchmarks BenchmarkBaselineResponseDataModules `json:"benchmarks"`
+	}
+
+	BenchmarkBaselineResponseDataModules    map[string]BenchmarkBaselineResponseDataSuites
+	BenchmarkBaselineResponseDataSuites     map[string]BenchmarkBaselineResponseDataBenchmarks
+	BenchmarkBaselineResponseDataBenchmarks map[string]BenchmarkBaselineStats
+
+	// BenchmarkBaselineStats holds the baseline statistics of a benchmark.
+	BenchmarkBaselineStats struct {
+		NsPerOp     BenchmarkBaselineMetric `json:"ns_per_op"`
+		BytesPerOp  BenchmarkBaselineMetric `json:"bytes_per_op"`
+		AllocsPerOp BenchmarkBaselineMetric `json:"allocs_per_op"`
+	}
+
+	// BenchmarkBaselineMetric holds the statistics of a metric over the runs of a benchmark.
+	BenchmarkBaselineMetric struct {
+		Mean   float64 `json:"mean"`
+		StdDev float64 `json:"stddev"`
+		Runs   int     `json:"runs"`
+	}
+)
+
+// Get returns the baseline statistics of a benchmark, if any.
+func (d *BenchmarkBaselineResponseData) Get(module, suite, name string) (BenchmarkBaselineStats, bool) {
+	if d == nil {
+		return BenchmarkBaselineStats{}, false
+	}
+	stats, ok := d.Benchmarks[module][suite][name]
+	return stats, ok
+}
+
+// GetBenchmarkBaseline fetches the latest benchmark results of the default branch of the repository, or nil
+// if there are none.
+func (c *client) GetBenchmarkBaseline() (*BenchmarkBaselineResponseData, error) {
+	if c.repositoryURL == "" {
+		return nil, fmt.Errorf("civisibility.GetBenchmarkBaseline: repository URL is required")
+	}
+
+	body := benchmarkBaselineRequest{
+		Data: benchmarkBaselineRequestHeader{
+			ID:   c.id,
+			Type: benchmarkBaselineRequestType,
+			Attributes: benchmarkBaselineRequestData{
+				Service:        c.serviceName,
+				Env:            c.environment,
+				RepositoryURL:  c.repositoryURL,
+				Configurations: c.testConfigurations,
+			},
+		},
+	}
+
+	request := c.getPostRequestConfig(benchmarkBaselineURLPath, body)
+	response, err := c.handler.SendRequest(*request)
+	if err != nil {
+		return nil, fmt.Errorf("sending benchmark baseline request: %s", err.Error())
+	}
+	if response.StatusCode == http.StatusNotFound {
+		// There are no results of the default branch to compare to yet.
+		return nil, nil
+	}
+
+	var responseObject benchmarkBaselineResponse
+	err = response.Unmarshal(&responseObject)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling benchmark baseline response: %s", err.Error())
+	}
+	if len(responseObject.Data.Attributes.Benchmarks) == 0 {
+		return nil, nil
+	}
+
+	return &responseObject.Data.Attributes, nil
+}
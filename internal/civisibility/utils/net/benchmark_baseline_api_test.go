@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package net
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBenchmarkBaselineApiRequest(t *testing.T) {
+	var c *client
+	expectedResponse := benchmarkBaselineResponse{}
+	expectedResponse.Data.Type = benchmarkBaselineRequestType
+	expectedResponse.Data.Attributes.Benchmarks = BenchmarkBaselineResponseDataModules{
+		"MyModule1": BenchmarkBaselineResponseDataSuites{
+			"MySuite1": BenchmarkBaselineResponseDataBenchmarks{
+				"BenchmarkOne": BenchmarkBaselineStats{
+					NsPerOp:     BenchmarkBaselineMetric{Mean: 1200, StdDev: 30, Runs: 5},
+					BytesPerOp:  BenchmarkBaselineMetric{Mean: 64, Runs: 5},
+					AllocsPerOp: BenchmarkBaselineMetric{Mean: 2, Runs: 5},
+				},
+			},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+
+		if r.Header.Get(HeaderContentType) == ContentTypeJSON {
+			var request benchmarkBaselineRequest
+			json.Unmarshal(body, &request)
+			assert.Equal(t, c.id, request.Data.ID)
+			assert.Equal(t, benchmarkBaselineRequestType, request.Data.Type)
+			assert.Equal(t, benchmarkBaselineURLPath, r.URL.Path[1:])
+			assert.Equal(t, c.environment, request.Data.Attributes.Env)
+			assert.Equal(t, c.repositoryURL, request.Data.Attributes.RepositoryURL)
+			assert.Equal(t, c.serviceName, request.Data.Attributes.Service)
+			assert.Equal(t, c.testConfigurations, request.Data.Attributes.Configurations)
+
+			w.Header().Set(HeaderContentType, ContentTypeJSON)
+			expectedResponse.Data.ID = request.Data.ID
+			json.NewEncoder(w).Encode(expectedResponse)
+		}
+	}))
+	defer server.Close()
+
+	origEnv := saveEnv()
+	path := os.Getenv("PATH")
+	defer restoreEnv(origEnv)
+
+	setCiVisibilityEnv(path, server.URL)
+	os.Setenv("DD_GIT_REPOSITORY_URL", "https://github.com/DataDog/dd-trace-go.git")
+
+	cInterface := NewClient()
+	c = cInterface.(*client)
+	baseline, err := cInterface.GetBenchmarkBaseline()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResponse.Data.Attributes, *baseline)
+
+	stats, ok := baseline.Get("MyModule1", "MySuite1", "BenchmarkOne")
+	assert.True(t, ok)
+	assert.Equal(t, 1200.0, stats.NsPerOp.Mean)
+	_, ok = baseline.Get("MyModule1", "MySuite1", "BenchmarkTwo")
+	assert.False(t, ok)
+}
+
+func TestBenchmarkBaselineApiRequestNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	origEnv := saveEnv()
+	path := os.Getenv("PATH")
+	defer restoreEnv(origEnv)
+
+	setCiVisibilityEnv(path, server.URL)
+	os.Setenv("DD_GIT_REPOSITORY_URL", "https://github.com/DataDog/dd-trace-go.git")
+
+	baseline, err := NewClient().GetBenchmarkBaseline()
+	assert.Nil(t, err)
+	assert.Nil(t, baseline)
+}
@@ -43,9 +43,11 @@ type (
 		SendPackFiles(commitSha string, packFiles []string) (bytes int64, err error)
 		SendCoveragePayload(ciTestCovPayload io.Reader) error
 		SendCoveragePayloadWithFormat(ciTestCovPayload io.Reader, format string) error
+		SendTestCyclePayload(ciTestCyclePayload io.Reader) error
 		GetSkippableTests() (correlationID string, skippables map[string]map[string][]SkippableResponseDataAttributes, err error)
 		GetTestManagementTests() (*TestManagementTestsResponseDataModules, error)
 		GetImpactedTests() (*ImpactedTestsDetectionResponse, error)
+		GetBenchmarkBaseline() (*BenchmarkBaselineResponseData, error)
 	}
 
 	// client is a client for sending requests to the Datadog backend.
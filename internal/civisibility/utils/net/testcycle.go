@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package net
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/utils/telemetry"
+)
+
+const (
+	// testCycleSubDomain is the subdomain for the test cycle endpoint.
+	testCycleSubDomain string = "citestcycle-intake"
+	// testCycleURLPath is the URL path for the test cycle endpoint.
+	testCycleURLPath string = "api/v2/citestcycle"
+)
+
+// NewClientForTestCycle creates a new client for sending test cycle payloads.
+func NewClientForTestCycle() Client {
+	return NewClientWithServiceNameAndSubdomain("", testCycleSubDomain)
+}
+
+// SendTestCyclePayload sends an already encoded test cycle payload to the backend.
+// The tracer sends these payloads itself, this is used to upload the payloads
+// recorded in offline mode.
+func (c *client) SendTestCyclePayload(ciTestCyclePayload io.Reader) error {
+	if ciTestCyclePayload == nil {
+		return errors.New("test cycle payload is nil")
+	}
+
+	// Read the payload upfront so it can be sent again if the request is retried.
+	body, err := io.ReadAll(ciTestCyclePayload)
+	if err != nil {
+		return fmt.Errorf("failed to read test cycle payload: %s", err.Error())
+	}
+
+	request := c.getPostRequestConfig(testCycleURLPath, body)
+	request.Format = FormatMessagePack
+	// The intake only supports compressed payloads in agentless mode.
+	request.Compressed = c.agentless
+
+	if request.Compressed {
+		telemetry.EndpointPayloadRequests(telemetry.TestCycleEndpointType, telemetry.CompressedRequestCompressedType)
+	} else {
+		telemetry.EndpointPayloadRequests(telemetry.TestCycleEndpointType, telemetry.UncompressedRequestCompressedType)
+	}
+
+	startTime := time.Now()
+	response, responseErr := c.handler.SendRequest(*request)
+	telemetry.EndpointPayloadRequestsMs(telemetry.TestCycleEndpointType, float64(time.Since(startTime).Milliseconds()))
+
+	if responseErr != nil {
+		telemetry.EndpointPayloadRequestsErrors(telemetry.TestCycleEndpointType, telemetry.NetworkErrorType)
+		telemetry.EndpointPayloadDropped(telemetry.TestCycleEndpointType)
+		return fmt.Errorf("failed to send test cycle request: %s", responseErr.Error())
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		telemetry.EndpointPayloadRequestsErrors(telemetry.TestCycleEndpointType, telemetry.GetErrorTypeFromStatusCode(response.StatusCode))
+		telemetry.EndpointPayloadDropped(telemetry.TestCycleEndpointType)
+		return fmt.Errorf("unexpected response code %d: %s", response.StatusCode, string(response.Body))
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package net
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestCycleApiRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/"+testCycleURLPath, r.URL.Path)
+		assert.Equal(t, ContentTypeMessagePack, r.Header.Get(HeaderContentType))
+		assert.Equal(t, ContentEncodingGzip, r.Header.Get(HeaderContentEncoding))
+		assert.Equal(t, "test_api_key", r.Header.Get("dd-api-key"))
+
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(reader)
+		assert.Equal(t, "payload", string(body))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	origEnv := saveEnv()
+	path := os.Getenv("PATH")
+	defer restoreEnv(origEnv)
+
+	setCiVisibilityEnv(path, server.URL)
+
+	cInterface := NewClientForTestCycle()
+	err := cInterface.SendTestCyclePayload(bytes.NewBufferString("payload"))
+	assert.Nil(t, err)
+}
+
+func TestTestCycleApiRequestFailToSend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal processing error", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	origEnv := saveEnv()
+	path := os.Getenv("PATH")
+	defer restoreEnv(origEnv)
+
+	setCiVisibilityEnv(path, server.URL)
+
+	cInterface := NewClientForTestCycle()
+	err := cInterface.SendTestCyclePayload(bytes.NewBufferString("payload"))
+	assert.NotNil(t, err)
+}
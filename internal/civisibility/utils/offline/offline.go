@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+// Package offline stores the CI Visibility payloads on disk for runners without
+// network access, so that they can be uploaded later.
+//
+// Payloads are stored exactly as they would have been sent, so the events keep
+// their original timestamps, and the git and CI metadata tags collected when the
+// tests ran.
+package offline
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+	"github.com/DataDog/dd-trace-go/v2/internal/globalconfig"
+)
+
+const (
+	// KindTestCycle is the kind of the test cycle payloads (sessions, modules, suites and tests).
+	KindTestCycle = "citestcycle"
+	// KindCoverage is the kind of the code coverage payloads.
+	KindCoverage = "citestcov"
+
+	// payloadExt is the file extension of the payload files.
+	payloadExt = ".msgpack"
+	// tmpExt is the file extension of the payload files being written.
+	tmpExt = ".tmp"
+)
+
+// seq is the sequence number of the payloads written by this process.
+var seq atomic.Uint64
+
+// Payload is a payload file of an offline directory.
+type Payload struct {
+	// Kind is the kind of the payload, KindTestCycle or KindCoverage.
+	Kind string
+	// Path is the path of the payload file.
+	Path string
+}
+
+// Dir returns the offline directory, or an empty string if the offline mode is disabled.
+func Dir() string {
+	return os.Getenv(constants.CIVisibilityOfflineDirEnvironmentVariable)
+}
+
+// Enabled returns whether the offline mode is enabled.
+func Enabled() bool {
+	return Dir() != ""
+}
+
+// WritePayload writes a payload of the given kind to a new file of the offline
+// directory, and returns its path. The file is written under a temporary name
+// first, so an upload running concurrently never reads a partial payload.
+func WritePayload(kind string, payload io.Reader) (string, error) {
+	dir := Dir()
+	if dir == "" {
+		return "", fmt.Errorf("civisibility: offline mode is disabled")
+	}
+	// 0755 is what mkdir does, should be reasonable for the use cases here.
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s-%06d%s", kind, globalconfig.RuntimeID(), seq.Add(1), payloadExt)
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path + tmpExt)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, payload); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return path, nil
+}
+
+// Payloads returns the payload files of dir, oldest first.
+func Payloads(dir string) ([]Payload, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type payloadFile struct {
+		Payload
+		modTime int64
+	}
+	var files []payloadFile
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), payloadExt) {
+			continue
+		}
+		kind, _, ok := strings.Cut(e.Name(), "-")
+		if !ok || (kind != KindTestCycle && kind != KindCoverage) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, payloadFile{
+			Payload: Payload{Kind: kind, Path: filepath.Join(dir, e.Name())},
+			modTime: info.ModTime().UnixNano(),
+		})
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].modTime != files[j].modTime {
+			return files[i].modTime < files[j].modTime
+		}
+		return files[i].Path < files[j].Path
+	})
+	payloads := make([]Payload, len(files))
+	for i, f := range files {
+		payloads[i] = f.Payload
+	}
+	return payloads, nil
+}
@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package offline
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/civisibility/constants"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnabled(t *testing.T) {
+	t.Setenv(constants.CIVisibilityOfflineDirEnvironmentVariable, "")
+	assert.False(t, Enabled())
+
+	t.Setenv(constants.CIVisibilityOfflineDirEnvironmentVariable, t.TempDir())
+	assert.True(t, Enabled())
+}
+
+func TestWritePayload(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "offline")
+	t.Setenv(constants.CIVisibilityOfflineDirEnvironmentVariable, dir)
+
+	cycle, err := WritePayload(KindTestCycle, strings.NewReader("cycle"))
+	require.NoError(t, err)
+	coverage, err := WritePayload(KindCoverage, strings.NewReader("coverage"))
+	require.NoError(t, err)
+	assert.NotEqual(t, cycle, coverage)
+
+	data, err := os.ReadFile(cycle)
+	require.NoError(t, err)
+	assert.Equal(t, "cycle", string(data))
+
+	// Files being written and unrelated files are ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "citestcycle-1-000009.msgpack.tmp"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.msgpack"), nil, 0644))
+
+	payloads, err := Payloads(dir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Payload{
+		{Kind: KindTestCycle, Path: cycle},
+		{Kind: KindCoverage, Path: coverage},
+	}, payloads)
+}
+
+func TestWritePayloadDisabled(t *testing.T) {
+	t.Setenv(constants.CIVisibilityOfflineDirEnvironmentVariable, "")
+	_, err := WritePayload(KindTestCycle, strings.NewReader("cycle"))
+	assert.Error(t, err)
+}
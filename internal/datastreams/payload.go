@@ -54,6 +54,13 @@ type StatsBucket struct {
 	Stats []StatsPoint
 	// Backlogs store information used to compute queue backlog
 	Backlogs []Backlog
+	// Transactions holds the checkpoints of the transactions tracked, each encoded as the ID of the
+	// checkpoint (1 byte), the timestamp in unix nanoseconds (8 bytes, big endian), the length of the
+	// transaction ID (1 byte) and the transaction ID.
+	Transactions []byte
+	// TransactionCheckpointIds holds the names of the checkpoints of the transactions, each encoded as
+	// the ID of the checkpoint (1 byte), the length of the name (1 byte) and the name.
+	TransactionCheckpointIds []byte
 }
 
 // TimestampType can be either current or origin.
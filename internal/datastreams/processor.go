@@ -11,6 +11,7 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -61,6 +62,9 @@ type bucket struct {
 	latestCommitOffsets        map[partitionConsumerKey]int64
 	latestProduceOffsets       map[partitionKey]int64
 	latestHighWatermarkOffsets map[partitionKey]int64
+	latestBacklogProduce       map[backlogKey]int64
+	latestBacklogCommit        map[backlogKey]int64
+	transactions               *transactions
 	start                      uint64
 	duration                   uint64
 }
@@ -71,6 +75,9 @@ func newBucket(start, duration uint64) bucket {
 		latestCommitOffsets:        make(map[partitionConsumerKey]int64),
 		latestProduceOffsets:       make(map[partitionKey]int64),
 		latestHighWatermarkOffsets: make(map[partitionKey]int64),
+		latestBacklogProduce:       make(map[backlogKey]int64),
+		latestBacklogCommit:        make(map[backlogKey]int64),
+		transactions:               &transactions{},
 		start:                      start,
 		duration:                   duration,
 	}
@@ -105,10 +112,12 @@ func (b bucket) export(timestampType TimestampType) StatsBucket {
 		})
 	}
 	exported := StatsBucket{
-		Start:    b.start,
-		Duration: b.duration,
-		Stats:    stats,
-		Backlogs: make([]Backlog, 0, len(b.latestCommitOffsets)+len(b.latestProduceOffsets)+len(b.latestHighWatermarkOffsets)),
+		Start:                    b.start,
+		Duration:                 b.duration,
+		Stats:                    stats,
+		Backlogs:                 make([]Backlog, 0, len(b.latestCommitOffsets)+len(b.latestProduceOffsets)+len(b.latestHighWatermarkOffsets)+len(b.latestBacklogProduce)+len(b.latestBacklogCommit)),
+		Transactions:             b.transactions.data,
+		TransactionCheckpointIds: b.transactions.names,
 	}
 	for key, offset := range b.latestProduceOffsets {
 		exported.Backlogs = append(exported.Backlogs, Backlog{Tags: []string{fmt.Sprintf("partition:%d", key.partition), fmt.Sprintf("topic:%s", key.topic), "type:kafka_produce"}, Value: offset})
@@ -119,6 +128,12 @@ func (b bucket) export(timestampType TimestampType) StatsBucket {
 	for key, offset := range b.latestHighWatermarkOffsets {
 		exported.Backlogs = append(exported.Backlogs, Backlog{Tags: []string{fmt.Sprintf("partition:%d", key.partition), fmt.Sprintf("topic:%s", key.topic), "type:kafka_high_watermark"}, Value: offset})
 	}
+	for key, offset := range b.latestBacklogProduce {
+		exported.Backlogs = append(exported.Backlogs, Backlog{Tags: key.tags(produceOffset), Value: offset})
+	}
+	for key, offset := range b.latestBacklogCommit {
+		exported.Backlogs = append(exported.Backlogs, Backlog{Tags: key.tags(commitOffset), Value: offset})
+	}
 	return exported
 }
 
@@ -127,13 +142,17 @@ type pointType int
 const (
 	pointTypeStats pointType = iota
 	pointTypeKafkaOffset
+	pointTypeTransaction
+	pointTypeBacklogOffset
 )
 
 type processorInput struct {
-	point       statsPoint
-	kafkaOffset kafkaOffset
-	typ         pointType
-	queuePos    int64
+	point         statsPoint
+	kafkaOffset   kafkaOffset
+	transaction   transaction
+	backlogOffset backlogOffset
+	typ           pointType
+	queuePos      int64
 }
 
 type processorStats struct {
@@ -142,6 +161,8 @@ type processorStats struct {
 	flushedBuckets  int64
 	flushErrors     int64
 	dropped         int64
+	// droppedTransactions counts the transaction checkpoints dropped as their bucket was full.
+	droppedTransactions int64
 }
 
 type partitionKey struct {
@@ -172,6 +193,38 @@ type kafkaOffset struct {
 	timestamp  int64
 }
 
+// backlogKey identifies a partition of a topic, and the consumer group for commit offsets, of a system
+// other than Kafka.
+type backlogKey struct {
+	system    string
+	topic     string
+	partition string
+	group     string
+}
+
+// tags returns the tags of the backlog of the key.
+func (k backlogKey) tags(typ offsetType) []string {
+	var tags []string
+	if typ == commitOffset {
+		tags = append(tags, fmt.Sprintf("consumer_group:%s", k.group))
+	}
+	if k.partition != "" {
+		tags = append(tags, fmt.Sprintf("partition:%s", k.partition))
+	}
+	tags = append(tags, fmt.Sprintf("topic:%s", k.topic))
+	if typ == commitOffset {
+		return append(tags, fmt.Sprintf("type:%s_commit", k.system))
+	}
+	return append(tags, fmt.Sprintf("type:%s_produce", k.system))
+}
+
+type backlogOffset struct {
+	key        backlogKey
+	offset     int64
+	offsetType offsetType
+	timestamp  int64
+}
+
 type bucketKey struct {
 	serviceName string
 	btime       int64
@@ -188,12 +241,16 @@ type Processor struct {
 	stop                 chan struct{} // closing this channel triggers shutdown
 	flushRequest         chan chan<- struct{}
 	stats                processorStats
-	transport            *httpTransport
+	sinksMu              sync.Mutex
+	sinks                []Sink
 	statsd               internal.StatsdClient
 	env                  string
 	primaryTag           string
 	service              string
 	version              string
+	schemaSamplers       sync.Map // schemaSamplerKey -> *schemaSampler
+	// transactionSampleRate is the rate of transactions tracked.
+	transactionSampleRate float64
 	// used for tests
 	timeSource func() time.Time
 }
@@ -210,17 +267,18 @@ func NewProcessor(statsd internal.StatsdClient, env, service, version string, ag
 		service = defaultServiceName
 	}
 	p := &Processor{
-		tsTypeCurrentBuckets: make(map[bucketKey]bucket),
-		tsTypeOriginBuckets:  make(map[bucketKey]bucket),
-		hashCache:            newHashCache(),
-		in:                   newFastQueue(),
-		stopped:              1,
-		statsd:               statsd,
-		env:                  env,
-		service:              service,
-		version:              version,
-		transport:            newHTTPTransport(agentURL, httpClient),
-		timeSource:           time.Now,
+		tsTypeCurrentBuckets:  make(map[bucketKey]bucket),
+		tsTypeOriginBuckets:   make(map[bucketKey]bucket),
+		hashCache:             newHashCache(),
+		in:                    newFastQueue(),
+		stopped:               1,
+		statsd:                statsd,
+		env:                   env,
+		service:               service,
+		version:               version,
+		sinks:                 []Sink{newHTTPTransport(agentURL, httpClient)},
+		timeSource:            time.Now,
+		transactionSampleRate: internal.FloatEnv("DD_DATA_STREAMS_TRANSACTION_SAMPLE_RATE", 1),
 	}
 	return p
 }
@@ -295,12 +353,26 @@ func (p *Processor) addKafkaOffset(o kafkaOffset) {
 	}] = o.offset
 }
 
+func (p *Processor) addBacklogOffset(o backlogOffset) {
+	btime := alignTs(o.timestamp, bucketDuration.Nanoseconds())
+	b := p.getBucket(btime, p.service, p.tsTypeCurrentBuckets)
+	if o.offsetType == produceOffset {
+		b.latestBacklogProduce[o.key] = o.offset
+		return
+	}
+	b.latestBacklogCommit[o.key] = o.offset
+}
+
 func (p *Processor) processInput(in *processorInput) {
 	atomic.AddInt64(&p.stats.payloadsIn, 1)
 	if in.typ == pointTypeStats {
 		p.add(in.point)
 	} else if in.typ == pointTypeKafkaOffset {
 		p.addKafkaOffset(in.kafkaOffset)
+	} else if in.typ == pointTypeTransaction {
+		p.addTransaction(in.transaction)
+	} else if in.typ == pointTypeBacklogOffset {
+		p.addBacklogOffset(in.backlogOffset)
 	}
 }
 
@@ -319,13 +391,13 @@ func (p *Processor) run(tick <-chan time.Time) {
 		select {
 		case <-p.stop:
 			// drop in flight payloads on the input channel
-			p.sendToAgent(p.flush(time.Now().Add(bucketDuration * 10)))
+			p.send(p.flush(time.Now().Add(bucketDuration * 10)))
 			return
 		case now := <-tick:
-			p.sendToAgent(p.flush(now))
+			p.send(p.flush(now))
 		case done := <-p.flushRequest:
 			p.flushInput()
-			p.sendToAgent(p.flush(time.Now().Add(bucketDuration * 10)))
+			p.send(p.flush(time.Now().Add(bucketDuration * 10)))
 			close(done)
 		default:
 			s := p.in.pop()
@@ -395,6 +467,7 @@ func (p *Processor) reportStats() {
 		p.statsd.Count("datadog.datastreams.processor.flushed_buckets", atomic.SwapInt64(&p.stats.flushedBuckets, 0), nil, 1)
 		p.statsd.Count("datadog.datastreams.processor.flush_errors", atomic.SwapInt64(&p.stats.flushErrors, 0), nil, 1)
 		p.statsd.Count("datadog.datastreams.processor.dropped_payloads", atomic.SwapInt64(&p.stats.dropped, 0), nil, 1)
+		p.statsd.Count("datadog.datastreams.processor.dropped_transactions", atomic.SwapInt64(&p.stats.droppedTransactions, 0), nil, 1)
 	}
 }
 
@@ -439,12 +512,18 @@ func (p *Processor) flush(now time.Time) map[string]StatsPayload {
 	return payloads
 }
 
-func (p *Processor) sendToAgent(payloads map[string]StatsPayload) {
+// send sends the payloads to the agent and to the sinks added with AddSink.
+func (p *Processor) send(payloads map[string]StatsPayload) {
+	p.sinksMu.Lock()
+	sinks := p.sinks
+	p.sinksMu.Unlock()
 	for _, payload := range payloads {
 		atomic.AddInt64(&p.stats.flushedPayloads, 1)
 		atomic.AddInt64(&p.stats.flushedBuckets, int64(len(payload.Stats)))
-		if err := p.transport.sendPipelineStats(&payload); err != nil {
-			atomic.AddInt64(&p.stats.flushErrors, 1)
+		for _, s := range sinks {
+			if err := s.Send(&payload); err != nil {
+				atomic.AddInt64(&p.stats.flushErrors, 1)
+			}
 		}
 	}
 }
@@ -486,6 +565,14 @@ func (p *Processor) SetCheckpointWithParams(ctx context.Context, params options.
 	if dropped {
 		atomic.AddInt64(&p.stats.dropped, 1)
 	}
+	if params.TransactionID != "" {
+		p.trackTransaction(transaction{
+			id:         params.TransactionID,
+			checkpoint: strings.Join(edgeTags, ","),
+			timestamp:  now.UnixNano(),
+			service:    service,
+		})
+	}
 	return ContextWithPathway(ctx, child)
 }
 
@@ -515,6 +602,32 @@ func (p *Processor) TrackKafkaProduceOffset(topic string, partition int32, offse
 	}
 }
 
+// TrackProduceOffset tracks the offset of the last message produced to a partition of a topic of a system other
+// than Kafka, e.g. the sequence number of a Kinesis shard or of a NATS stream. Used together with TrackCommitOffset,
+// it reports the backlog of the consumer groups. The system is used as the prefix of the type of the backlog, e.g.
+// "kinesis", and partition may be empty for systems without partitions.
+func (p *Processor) TrackProduceOffset(system string, topic string, partition string, offset int64) {
+	p.trackBacklogOffset(backlogKey{system: system, topic: topic, partition: partition}, produceOffset, offset)
+}
+
+// TrackCommitOffset tracks the offset of the last message acknowledged by a consumer group on a partition of a
+// topic of a system other than Kafka. See TrackProduceOffset.
+func (p *Processor) TrackCommitOffset(system string, group string, topic string, partition string, offset int64) {
+	p.trackBacklogOffset(backlogKey{system: system, topic: topic, partition: partition, group: group}, commitOffset, offset)
+}
+
+func (p *Processor) trackBacklogOffset(key backlogKey, typ offsetType, offset int64) {
+	dropped := p.in.push(&processorInput{typ: pointTypeBacklogOffset, backlogOffset: backlogOffset{
+		key:        key,
+		offset:     offset,
+		offsetType: typ,
+		timestamp:  p.time().UnixNano(),
+	}})
+	if dropped {
+		atomic.AddInt64(&p.stats.dropped, 1)
+	}
+}
+
 // TrackKafkaHighWatermarkOffset should be used in the consumer, to track the high watermark offsets of each partition.
 // The first argument is the Kafka cluster ID, and will be used later.
 func (p *Processor) TrackKafkaHighWatermarkOffset(_ string, topic string, partition int32, offset int64) {
@@ -356,6 +356,37 @@ func TestKafkaLag(t *testing.T) {
 	assert.Equal(t, expectedBacklogs, payloads["service"].Stats[0].Backlogs)
 }
 
+func TestBacklogs(t *testing.T) {
+	p := NewProcessor(nil, "env", "service", "v1", &url.URL{Scheme: "http", Host: "agent-address"}, nil)
+	tp1 := time.Now()
+	p.TrackProduceOffset("kinesis", "stream1", "shardId-000000000001", 5)
+	p.TrackProduceOffset("kinesis", "stream1", "shardId-000000000001", 15)
+	p.TrackCommitOffset("kinesis", "app1", "stream1", "shardId-000000000001", 10)
+	p.TrackProduceOffset("nats", "orders", "", 7)
+	p.TrackCommitOffset("nats", "durable1", "orders", "", 3)
+	p.flushInput()
+	payloads := sortedPayloads(p.flush(tp1.Add(bucketDuration * 2)))
+	expectedBacklogs := []Backlog{
+		{
+			Tags:  []string{"consumer_group:app1", "partition:shardId-000000000001", "topic:stream1", "type:kinesis_commit"},
+			Value: 10,
+		},
+		{
+			Tags:  []string{"consumer_group:durable1", "topic:orders", "type:nats_commit"},
+			Value: 3,
+		},
+		{
+			Tags:  []string{"partition:shardId-000000000001", "topic:stream1", "type:kinesis_produce"},
+			Value: 15,
+		},
+		{
+			Tags:  []string{"topic:orders", "type:nats_produce"},
+			Value: 7,
+		},
+	}
+	assert.Equal(t, expectedBacklogs, payloads["service"].Stats[0].Backlogs)
+}
+
 type noOpTransport struct{}
 
 // RoundTrip does nothing and returns a dummy response.
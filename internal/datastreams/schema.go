@@ -0,0 +1,224 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datastreams
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// schemaSampleInterval is the minimum interval between two samples of the definition of a schema.
+const schemaSampleInterval = 30 * time.Second
+
+// Types of schemas.
+const (
+	SchemaTypeProtobuf = "protobuf"
+	SchemaTypeAvro     = "avro"
+	SchemaTypeJSON     = "json"
+)
+
+// Schema is the schema of the messages of a topic.
+type Schema struct {
+	// ID identifies the definition of the schema. It's stable across processes and languages, so that
+	// producers and consumers using the same definition report the same ID.
+	ID string
+	// Name is the name of the schema, e.g. the full name of a Protobuf message.
+	Name string
+	// Type is the type of the schema, one of SchemaTypeProtobuf, SchemaTypeAvro or SchemaTypeJSON.
+	Type string
+	// Definition is the canonical definition of the schema.
+	Definition string
+}
+
+// newSchema returns a schema, computing its ID from its canonical definition.
+func newSchema(typ, name, definition string) Schema {
+	h := fnv.New64a()
+	h.Write([]byte(typ))
+	h.Write([]byte{0})
+	h.Write([]byte(definition))
+	return Schema{
+		ID:         strconv.FormatUint(h.Sum64(), 10),
+		Name:       name,
+		Type:       typ,
+		Definition: definition,
+	}
+}
+
+// NewProtobufSchema returns the schema of a Protobuf message. The definition holds the descriptor of the
+// message followed by the descriptors of the messages its fields reference, in JSON.
+func NewProtobufSchema(desc protoreflect.MessageDescriptor) Schema {
+	var (
+		messages []protoreflect.MessageDescriptor
+		seen     = map[protoreflect.FullName]bool{}
+		visit    func(protoreflect.MessageDescriptor)
+	)
+	visit = func(md protoreflect.MessageDescriptor) {
+		if seen[md.FullName()] {
+			return
+		}
+		seen[md.FullName()] = true
+		messages = append(messages, md)
+		fields := md.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			if fd := fields.Get(i); fd.Message() != nil {
+				visit(fd.Message())
+			}
+		}
+	}
+	visit(desc)
+	// The root message comes first, the messages it references follow by name.
+	slices.SortFunc(messages[1:], func(a, b protoreflect.MessageDescriptor) int {
+		return cmp.Compare(a.FullName(), b.FullName())
+	})
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, md := range messages {
+		dp := protodesc.ToDescriptorProto(md)
+		name := string(md.FullName())
+		dp.Name = &name
+		// Nested messages are listed on their own.
+		dp.NestedType = nil
+		data, err := protojson.Marshal(dp)
+		if err != nil {
+			continue
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		// protojson doesn't guarantee a stable output, so the insignificant spaces are removed.
+		_ = json.Compact(&buf, data)
+	}
+	buf.WriteByte(']')
+	return newSchema(SchemaTypeProtobuf, string(desc.FullName()), buf.String())
+}
+
+// NewAvroSchema returns the schema of an Avro schema definition in JSON.
+func NewAvroSchema(definition string) (Schema, error) {
+	canonical, parsed, err := canonicalJSON(definition)
+	if err != nil {
+		return Schema{}, fmt.Errorf("invalid Avro schema: %w", err)
+	}
+	var name string
+	switch v := parsed.(type) {
+	case string:
+		// A primitive type.
+		name = v
+	case map[string]any:
+		name, _ = v["name"].(string)
+		if namespace, ok := v["namespace"].(string); ok && namespace != "" && name != "" {
+			name = namespace + "." + name
+		}
+		if name == "" {
+			name, _ = v["type"].(string)
+		}
+	}
+	return newSchema(SchemaTypeAvro, name, canonical), nil
+}
+
+// NewJSONSchema returns the schema of a JSON Schema definition. Its name is the title or the $id of the schema.
+func NewJSONSchema(definition string) (Schema, error) {
+	canonical, parsed, err := canonicalJSON(definition)
+	if err != nil {
+		return Schema{}, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	var name string
+	if v, ok := parsed.(map[string]any); ok {
+		name, _ = v["title"].(string)
+		if name == "" {
+			name, _ = v["$id"].(string)
+		}
+	}
+	return newSchema(SchemaTypeJSON, name, canonical), nil
+}
+
+// canonicalJSON returns a JSON document without insignificant spaces and with the keys of its objects
+// sorted, so that equivalent documents have the same representation.
+func canonicalJSON(document string) (string, any, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(document)))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "", nil, err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(data), v, nil
+}
+
+// schemas holds the schemas registered, by topic.
+var schemas sync.Map
+
+// RegisterSchema registers the schema of the messages of a topic. Registering the zero schema unregisters it.
+func RegisterSchema(topic string, schema Schema) {
+	if schema == (Schema{}) {
+		schemas.Delete(topic)
+		return
+	}
+	schemas.Store(topic, schema)
+}
+
+// GetSchema returns the schema registered for a topic.
+func GetSchema(topic string) (Schema, bool) {
+	v, ok := schemas.Load(topic)
+	if !ok {
+		return Schema{}, false
+	}
+	return v.(Schema), true
+}
+
+type schemaSamplerKey struct {
+	topic     string
+	operation string
+}
+
+// schemaSampler samples the definition of a schema at most once per schemaSampleInterval.
+type schemaSampler struct {
+	// weight holds the number of messages since the last sample.
+	weight     atomic.Int64
+	lastSample atomic.Int64
+}
+
+// trySample returns whether the definition of the schema should be sampled for a message, and the number
+// of messages the sample represents.
+func (s *schemaSampler) trySample(now time.Time) (bool, int64) {
+	weight := s.weight.Add(1)
+	last := s.lastSample.Load()
+	if last != 0 && now.UnixNano()-last < schemaSampleInterval.Nanoseconds() {
+		return false, 0
+	}
+	if !s.lastSample.CompareAndSwap(last, now.UnixNano()) {
+		// Another message was sampled concurrently.
+		return false, 0
+	}
+	s.weight.Add(-weight)
+	return true, weight
+}
+
+// TrySampleSchema returns whether the definition of the schema of a topic should be sampled for a message
+// serialized or deserialized by operation, and the number of messages the sample represents.
+func (p *Processor) TrySampleSchema(topic string, operation string) (bool, int64) {
+	key := schemaSamplerKey{topic: topic, operation: operation}
+	s, ok := p.schemaSamplers.Load(key)
+	if !ok {
+		s, _ = p.schemaSamplers.LoadOrStore(key, &schemaSampler{})
+	}
+	return s.(*schemaSampler).trySample(p.time())
+}
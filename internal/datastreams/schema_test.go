@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datastreams
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestNewProtobufSchema(t *testing.T) {
+	s := NewProtobufSchema((&timestamppb.Timestamp{}).ProtoReflect().Descriptor())
+	assert.Equal(t, SchemaTypeProtobuf, s.Type)
+	assert.Equal(t, "google.protobuf.Timestamp", s.Name)
+	assert.NotEmpty(t, s.ID)
+	var messages []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(s.Definition), &messages))
+	require.Len(t, messages, 1)
+	assert.Equal(t, "google.protobuf.Timestamp", messages[0]["name"])
+
+	// The messages referenced by the fields are part of the definition, after the root message.
+	s = NewProtobufSchema((&descriptorpb.FileDescriptorSet{}).ProtoReflect().Descriptor())
+	require.NoError(t, json.Unmarshal([]byte(s.Definition), &messages))
+	assert.Greater(t, len(messages), 2)
+	assert.Equal(t, "google.protobuf.FileDescriptorSet", messages[0]["name"])
+	assert.Equal(t, "google.protobuf.DescriptorProto", messages[1]["name"])
+
+	// The ID is stable.
+	assert.Equal(t, s, NewProtobufSchema((&descriptorpb.FileDescriptorSet{}).ProtoReflect().Descriptor()))
+}
+
+func TestNewAvroSchema(t *testing.T) {
+	s, err := NewAvroSchema(`{"type": "record", "namespace": "com.example", "name": "Order",
+		"fields": [{"name": "id", "type": "long"}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaTypeAvro, s.Type)
+	assert.Equal(t, "com.example.Order", s.Name)
+	assert.Equal(t, `{"fields":[{"name":"id","type":"long"}],"name":"Order","namespace":"com.example","type":"record"}`, s.Definition)
+
+	// Equivalent definitions have the same ID.
+	same, err := NewAvroSchema(`{"name":"Order","namespace":"com.example","type":"record","fields":[{"type":"long","name":"id"}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, s.ID, same.ID)
+	changed, err := NewAvroSchema(`{"name":"Order","namespace":"com.example","type":"record","fields":[{"type":"string","name":"id"}]}`)
+	require.NoError(t, err)
+	assert.NotEqual(t, s.ID, changed.ID)
+
+	s, err = NewAvroSchema(`"string"`)
+	require.NoError(t, err)
+	assert.Equal(t, "string", s.Name)
+
+	_, err = NewAvroSchema(`{"type":`)
+	assert.Error(t, err)
+}
+
+func TestNewJSONSchema(t *testing.T) {
+	s, err := NewJSONSchema(`{"$id": "https://example.com/order.json", "type": "object"}`)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaTypeJSON, s.Type)
+	assert.Equal(t, "https://example.com/order.json", s.Name)
+
+	s, err = NewJSONSchema(`{"title": "Order", "type": "object"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "Order", s.Name)
+
+	// The same definition has a different ID as an Avro schema.
+	avro, err := NewAvroSchema(`{"title": "Order", "type": "object"}`)
+	require.NoError(t, err)
+	assert.NotEqual(t, s.ID, avro.ID)
+}
+
+func TestRegisterSchema(t *testing.T) {
+	s, err := NewJSONSchema(`{"title": "Order"}`)
+	require.NoError(t, err)
+	RegisterSchema("orders", s)
+	got, ok := GetSchema("orders")
+	assert.True(t, ok)
+	assert.Equal(t, s, got)
+
+	RegisterSchema("orders", Schema{})
+	_, ok = GetSchema("orders")
+	assert.False(t, ok)
+}
+
+func TestTrySampleSchema(t *testing.T) {
+	now := time.Now()
+	p := &Processor{timeSource: func() time.Time { return now }}
+
+	sample, weight := p.TrySampleSchema("orders", "serialization")
+	assert.True(t, sample)
+	assert.Equal(t, int64(1), weight)
+	for i := 0; i < 3; i++ {
+		sample, _ = p.TrySampleSchema("orders", "serialization")
+		assert.False(t, sample)
+	}
+	// Topics and operations are sampled independently.
+	sample, _ = p.TrySampleSchema("orders", "deserialization")
+	assert.True(t, sample)
+
+	now = now.Add(schemaSampleInterval)
+	sample, weight = p.TrySampleSchema("orders", "serialization")
+	assert.True(t, sample)
+	assert.Equal(t, int64(4), weight)
+}
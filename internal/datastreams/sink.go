@@ -0,0 +1,264 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datastreams
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DataDog/sketches-go/ddsketch"
+	"github.com/DataDog/sketches-go/ddsketch/pb/sketchpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// Sink receives the stats payloads flushed by the Processor, every 10 seconds.
+type Sink interface {
+	// Send sends a payload. It must not retain the payload after returning.
+	Send(p *StatsPayload) error
+}
+
+// Send implements Sink, sending the payload to the agent.
+func (t *httpTransport) Send(p *StatsPayload) error {
+	return t.sendPipelineStats(p)
+}
+
+// AddSink adds a sink receiving the payloads flushed, in addition to the agent.
+func (p *Processor) AddSink(s Sink) {
+	p.sinksMu.Lock()
+	defer p.sinksMu.Unlock()
+	p.sinks = append(p.sinks, s)
+}
+
+type (
+	// PayloadSummary is a readable form of a StatsPayload, with the distributions summarized.
+	PayloadSummary struct {
+		Service string          `json:"service"`
+		Env     string          `json:"env"`
+		Version string          `json:"version,omitempty"`
+		Buckets []BucketSummary `json:"buckets"`
+	}
+
+	// BucketSummary is a readable form of a StatsBucket.
+	BucketSummary struct {
+		Start         time.Time            `json:"start"`
+		Duration      time.Duration        `json:"duration"`
+		TimestampType TimestampType        `json:"timestamp_type"`
+		Points        []PointSummary       `json:"points,omitempty"`
+		Backlogs      []Backlog            `json:"backlogs,omitempty"`
+		Transactions  []TransactionSummary `json:"transactions,omitempty"`
+	}
+
+	// TransactionSummary is a checkpoint of a transaction.
+	TransactionSummary struct {
+		ID         string    `json:"id"`
+		Checkpoint string    `json:"checkpoint"`
+		Timestamp  time.Time `json:"timestamp"`
+	}
+
+	// PointSummary is a readable form of a StatsPoint.
+	PointSummary struct {
+		Hash           uint64              `json:"hash"`
+		ParentHash     uint64              `json:"parent_hash"`
+		EdgeTags       []string            `json:"edge_tags"`
+		PathwayLatency DistributionSummary `json:"pathway_latency"`
+		EdgeLatency    DistributionSummary `json:"edge_latency"`
+		PayloadSize    DistributionSummary `json:"payload_size"`
+	}
+
+	// DistributionSummary holds the count and percentiles of a distribution. Latencies are in seconds
+	// and payload sizes in bytes.
+	DistributionSummary struct {
+		Count float64 `json:"count"`
+		P50   float64 `json:"p50"`
+		P95   float64 `json:"p95"`
+		P99   float64 `json:"p99"`
+		Max   float64 `json:"max"`
+	}
+)
+
+// Summarize returns the readable form of a payload.
+func Summarize(p *StatsPayload) PayloadSummary {
+	s := PayloadSummary{
+		Service: p.Service,
+		Env:     p.Env,
+		Version: p.Version,
+		Buckets: make([]BucketSummary, 0, len(p.Stats)),
+	}
+	for _, b := range p.Stats {
+		bs := BucketSummary{
+			Start:    time.Unix(0, int64(b.Start)).UTC(),
+			Duration: time.Duration(b.Duration),
+			Backlogs: b.Backlogs,
+		}
+		for _, tr := range decodeTransactions(b.Transactions, b.TransactionCheckpointIds) {
+			bs.Transactions = append(bs.Transactions, TransactionSummary{
+				ID:         tr.id,
+				Checkpoint: tr.checkpoint,
+				Timestamp:  time.Unix(0, tr.timestamp).UTC(),
+			})
+		}
+		for _, point := range b.Stats {
+			bs.TimestampType = point.TimestampType
+			bs.Points = append(bs.Points, PointSummary{
+				Hash:           point.Hash,
+				ParentHash:     point.ParentHash,
+				EdgeTags:       point.EdgeTags,
+				PathwayLatency: summarizeSketch(point.PathwayLatency),
+				EdgeLatency:    summarizeSketch(point.EdgeLatency),
+				PayloadSize:    summarizeSketch(point.PayloadSize),
+			})
+		}
+		slices.SortFunc(bs.Points, func(a, b PointSummary) int {
+			return strings.Compare(strings.Join(a.EdgeTags, ","), strings.Join(b.EdgeTags, ","))
+		})
+		s.Buckets = append(s.Buckets, bs)
+	}
+	return s
+}
+
+// summarizeSketch returns the summary of a serialized sketch.
+func summarizeSketch(data []byte) DistributionSummary {
+	var pb sketchpb.DDSketch
+	if err := proto.Unmarshal(data, &pb); err != nil {
+		return DistributionSummary{}
+	}
+	sketch, err := ddsketch.FromProto(&pb)
+	if err != nil || sketch.IsEmpty() {
+		return DistributionSummary{}
+	}
+	s := DistributionSummary{Count: sketch.GetCount()}
+	s.P50, _ = sketch.GetValueAtQuantile(0.5)
+	s.P95, _ = sketch.GetValueAtQuantile(0.95)
+	s.P99, _ = sketch.GetValueAtQuantile(0.99)
+	s.Max, _ = sketch.GetMaxValue()
+	return s
+}
+
+// FileSink writes the payloads to a file in the JSON lines format, one PayloadSummary by line.
+type FileSink struct {
+	mu sync.Mutex
+	w  io.WriteCloser
+}
+
+// NewFileSink returns a FileSink appending to the file at path, which is created if needed.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{w: f}, nil
+}
+
+// Send implements Sink.
+func (s *FileSink) Send(p *StatsPayload) error {
+	data, err := json.Marshal(Summarize(p))
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Close()
+}
+
+// inspectorRetention is the time for which the Inspector keeps the buckets flushed.
+const inspectorRetention = 5 * time.Minute
+
+// Inspector is a Sink keeping the buckets flushed in the last 5 minutes in memory, and an http.Handler
+// rendering them, to verify the Data Streams instrumentation of a service locally. The buckets are
+// rendered as text, or as JSON with the format=json query parameter.
+type Inspector struct {
+	mu       sync.Mutex
+	payloads []PayloadSummary
+	// now is used for tests.
+	now func() time.Time
+}
+
+// NewInspector returns a new Inspector.
+func NewInspector() *Inspector {
+	return &Inspector{now: time.Now}
+}
+
+// Send implements Sink.
+func (i *Inspector) Send(p *StatsPayload) error {
+	s := Summarize(p)
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.payloads = append(i.payloads, s)
+	cutoff := i.now().Add(-inspectorRetention)
+	i.payloads = slices.DeleteFunc(i.payloads, func(p PayloadSummary) bool {
+		for _, b := range p.Buckets {
+			if b.Start.After(cutoff) {
+				return false
+			}
+		}
+		return true
+	})
+	return nil
+}
+
+// Payloads returns the payloads kept, from the oldest.
+func (i *Inspector) Payloads() []PayloadSummary {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return slices.Clone(i.payloads)
+}
+
+// ServeHTTP implements http.Handler.
+func (i *Inspector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	payloads := i.Payloads()
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		if payloads == nil {
+			payloads = []PayloadSummary{}
+		}
+		_ = json.NewEncoder(w).Encode(payloads)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if len(payloads) == 0 {
+		fmt.Fprintln(w, "No Data Streams stats flushed yet. Stats are flushed every 10 seconds.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	defer tw.Flush()
+	for _, p := range payloads {
+		for _, b := range p.Buckets {
+			fmt.Fprintf(tw, "service: %s, env: %s, bucket: %s (%s), timestamp: %s\n", p.Service, p.Env, b.Start.Format(time.RFC3339), b.Duration, b.TimestampType)
+			if len(b.Points) > 0 {
+				fmt.Fprintln(tw, "  HASH\tPARENT HASH\tEDGE TAGS\tCOUNT\tPATHWAY LATENCY P50/P99\tEDGE LATENCY P50/P99\tPAYLOAD SIZE P50/MAX\t")
+			}
+			for _, pt := range b.Points {
+				fmt.Fprintf(tw, "  %d\t%d\t%s\t%.0f\t%.3fs/%.3fs\t%.3fs/%.3fs\t%.0fB/%.0fB\t\n",
+					pt.Hash, pt.ParentHash, strings.Join(pt.EdgeTags, ","), pt.EdgeLatency.Count,
+					pt.PathwayLatency.P50, pt.PathwayLatency.P99, pt.EdgeLatency.P50, pt.EdgeLatency.P99,
+					pt.PayloadSize.P50, pt.PayloadSize.Max)
+			}
+			for _, backlog := range b.Backlogs {
+				fmt.Fprintf(tw, "  backlog %s: %d\n", strings.Join(backlog.Tags, ","), backlog.Value)
+			}
+			for _, tr := range b.Transactions {
+				fmt.Fprintf(tw, "  transaction %s at %s: %s\n", tr.ID, tr.Checkpoint, tr.Timestamp.Format(time.RFC3339Nano))
+			}
+			fmt.Fprintln(tw)
+		}
+	}
+}
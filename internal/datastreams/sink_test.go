@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datastreams
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSink struct {
+	payloads []StatsPayload
+}
+
+func (s *recordingSink) Send(p *StatsPayload) error {
+	s.payloads = append(s.payloads, *p)
+	return nil
+}
+
+// testPayload returns the payload flushed by a processor for a single checkpoint.
+func testPayload(t *testing.T, start time.Time) *StatsPayload {
+	p := NewProcessor(nil, "env", "service", "v1", &url.URL{Scheme: "http", Host: "agent-address"}, nil)
+	p.add(statsPoint{
+		serviceName:    "service",
+		edgeTags:       []string{"direction:in", "topic:orders", "type:kafka"},
+		hash:           2,
+		parentHash:     1,
+		timestamp:      start.UnixNano(),
+		pathwayLatency: (2 * time.Second).Nanoseconds(),
+		edgeLatency:    time.Second.Nanoseconds(),
+		payloadSize:    100,
+	})
+	payloads := p.flush(start.Add(2 * bucketDuration))
+	require.Contains(t, payloads, "service")
+	payload := payloads["service"]
+	return &payload
+}
+
+func TestProcessorSinks(t *testing.T) {
+	p := NewProcessor(nil, "env", "service", "v1", &url.URL{Scheme: "http", Host: "agent-address"}, &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})})
+	sink := &recordingSink{}
+	p.AddSink(sink)
+	p.send(map[string]StatsPayload{"service": *testPayload(t, time.Now())})
+	require.Len(t, sink.payloads, 1)
+	assert.Equal(t, "service", sink.payloads[0].Service)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestSummarize(t *testing.T) {
+	start := time.Now().Truncate(bucketDuration)
+	s := Summarize(testPayload(t, start))
+	assert.Equal(t, "service", s.Service)
+	assert.Equal(t, "env", s.Env)
+	require.Len(t, s.Buckets, 2)
+	for _, b := range s.Buckets {
+		require.Len(t, b.Points, 1)
+		point := b.Points[0]
+		assert.Equal(t, uint64(2), point.Hash)
+		assert.Equal(t, []string{"direction:in", "topic:orders", "type:kafka"}, point.EdgeTags)
+		assert.Equal(t, float64(1), point.EdgeLatency.Count)
+		assert.InDelta(t, 1, point.EdgeLatency.P50, 0.02)
+		assert.InDelta(t, 2, point.PathwayLatency.P99, 0.04)
+		assert.InDelta(t, 100, point.PayloadSize.Max, 2)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dsm.jsonl")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Send(testPayload(t, time.Now())))
+	require.NoError(t, sink.Send(testPayload(t, time.Now())))
+	require.NoError(t, sink.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var lines int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s PayloadSummary
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &s))
+		assert.Equal(t, "service", s.Service)
+		lines++
+	}
+	assert.Equal(t, 2, lines)
+}
+
+func TestInspector(t *testing.T) {
+	now := time.Now()
+	inspector := NewInspector()
+	inspector.now = func() time.Time { return now }
+
+	rec := httptest.NewRecorder()
+	inspector.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Contains(t, rec.Body.String(), "No Data Streams stats flushed yet")
+
+	require.NoError(t, inspector.Send(testPayload(t, now.Add(-time.Hour))))
+	require.NoError(t, inspector.Send(testPayload(t, now)))
+	// The payload older than the retention was dropped.
+	require.Len(t, inspector.Payloads(), 1)
+
+	rec = httptest.NewRecorder()
+	inspector.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Contains(t, rec.Body.String(), "service: service, env: env")
+	assert.Contains(t, rec.Body.String(), "direction:in,topic:orders,type:kafka")
+
+	rec = httptest.NewRecorder()
+	inspector.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?format=json", nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var payloads []PayloadSummary
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &payloads))
+	require.Len(t, payloads, 1)
+	assert.Equal(t, uint64(2), payloads[0].Buckets[0].Points[0].Hash)
+}
@@ -0,0 +1,143 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datastreams
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxTransactionsPerBucket bounds the number of transaction checkpoints kept by bucket, the
+	// checkpoints tracked beyond it are dropped.
+	maxTransactionsPerBucket = 10000
+	// maxTransactionCheckpoints bounds the number of checkpoint names by bucket, as they are encoded
+	// on a byte.
+	maxTransactionCheckpoints = math.MaxUint8
+	// maxTransactionIDLength is the maximum length of a transaction ID, longer IDs are truncated.
+	maxTransactionIDLength = math.MaxUint8
+	knuthFactor            = uint64(1111111111111111111)
+)
+
+// transaction is a checkpoint of a transaction.
+type transaction struct {
+	id         string
+	checkpoint string
+	timestamp  int64
+	service    string
+}
+
+// transactions holds the checkpoints of the transactions tracked in a bucket.
+type transactions struct {
+	count       int
+	data        []byte
+	checkpoints map[string]uint8
+	names       []byte
+}
+
+// add encodes a transaction checkpoint. It returns false if the bucket is full.
+func (t *transactions) add(tr transaction) bool {
+	if t.count >= maxTransactionsPerBucket {
+		return false
+	}
+	id, ok := t.checkpoints[tr.checkpoint]
+	if !ok {
+		if len(t.checkpoints) >= maxTransactionCheckpoints {
+			return false
+		}
+		if t.checkpoints == nil {
+			t.checkpoints = make(map[string]uint8)
+		}
+		id = uint8(len(t.checkpoints) + 1)
+		t.checkpoints[tr.checkpoint] = id
+		name := truncate(tr.checkpoint, math.MaxUint8)
+		t.names = append(t.names, id, uint8(len(name)))
+		t.names = append(t.names, name...)
+	}
+	txID := truncate(tr.id, maxTransactionIDLength)
+	t.data = append(t.data, id)
+	t.data = binary.BigEndian.AppendUint64(t.data, uint64(tr.timestamp))
+	t.data = append(t.data, uint8(len(txID)))
+	t.data = append(t.data, txID...)
+	t.count++
+	return true
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// decodeTransactions decodes the transaction checkpoints of a bucket.
+func decodeTransactions(data, names []byte) []transaction {
+	checkpoints := make(map[uint8]string)
+	for len(names) >= 2 && len(names) >= 2+int(names[1]) {
+		checkpoints[names[0]] = string(names[2 : 2+int(names[1])])
+		names = names[2+int(names[1]):]
+	}
+	var txs []transaction
+	for len(data) >= 10 && len(data) >= 10+int(data[9]) {
+		txs = append(txs, transaction{
+			checkpoint: checkpoints[data[0]],
+			timestamp:  int64(binary.BigEndian.Uint64(data[1:9])),
+			id:         string(data[10 : 10+int(data[9])]),
+		})
+		data = data[10+int(data[9]):]
+	}
+	return txs
+}
+
+// sampleTransaction returns whether the checkpoints of a transaction are tracked. The decision only
+// depends on the transaction ID, so that all the checkpoints of a sampled transaction are tracked, in all
+// the services using the same rate.
+func sampleTransaction(id string, rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	// The hash is multiplied by Knuth's factor, like trace IDs are in the trace sampler, as the high bits of
+	// FNV hashes of similar IDs are close.
+	return h.Sum64()*knuthFactor < uint64(rate*math.MaxUint64)
+}
+
+// TrackTransaction tracks a checkpoint of a transaction, e.g. the processing of an order identified by
+// its ID, so that the message can be followed across the pathways. The transactions are sampled with the
+// rate set by DD_DATA_STREAMS_TRANSACTION_SAMPLE_RATE, and bounded by bucket.
+func (p *Processor) TrackTransaction(transactionID string, checkpointName string) {
+	p.TrackTransactionAt(transactionID, checkpointName, p.time())
+}
+
+// TrackTransactionAt tracks a checkpoint of a transaction which happened at t.
+func (p *Processor) TrackTransactionAt(transactionID string, checkpointName string, t time.Time) {
+	p.trackTransaction(transaction{id: transactionID, checkpoint: checkpointName, timestamp: t.UnixNano(), service: p.service})
+}
+
+func (p *Processor) trackTransaction(tr transaction) {
+	if tr.id == "" || !sampleTransaction(tr.id, p.transactionSampleRate) {
+		return
+	}
+	dropped := p.in.push(&processorInput{typ: pointTypeTransaction, transaction: tr})
+	if dropped {
+		atomic.AddInt64(&p.stats.dropped, 1)
+	}
+}
+
+func (p *Processor) addTransaction(tr transaction) {
+	btime := alignTs(tr.timestamp, bucketDuration.Nanoseconds())
+	b := p.getBucket(btime, tr.service, p.tsTypeCurrentBuckets)
+	if !b.transactions.add(tr) {
+		atomic.AddInt64(&p.stats.droppedTransactions, 1)
+	}
+}
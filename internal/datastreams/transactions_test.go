@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datastreams
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/dd-trace-go/v2/datastreams/options"
+)
+
+func TestTransactionsEncoding(t *testing.T) {
+	var txs transactions
+	require.True(t, txs.add(transaction{id: "order-1", checkpoint: "ingest", timestamp: 1}))
+	require.True(t, txs.add(transaction{id: "order-2", checkpoint: "ingest", timestamp: 2}))
+	require.True(t, txs.add(transaction{id: "order-1", checkpoint: "ship", timestamp: 3}))
+
+	assert.Equal(t, []transaction{
+		{id: "order-1", checkpoint: "ingest", timestamp: 1},
+		{id: "order-2", checkpoint: "ingest", timestamp: 2},
+		{id: "order-1", checkpoint: "ship", timestamp: 3},
+	}, decodeTransactions(txs.data, txs.names))
+	assert.Equal(t, []byte{1, 6, 'i', 'n', 'g', 'e', 's', 't', 2, 4, 's', 'h', 'i', 'p'}, txs.names)
+}
+
+func TestTransactionsBounds(t *testing.T) {
+	var txs transactions
+	for i := 0; i < maxTransactionCheckpoints; i++ {
+		require.True(t, txs.add(transaction{id: "id", checkpoint: fmt.Sprint(i)}))
+	}
+	assert.False(t, txs.add(transaction{id: "id", checkpoint: "one too many"}))
+	assert.True(t, txs.add(transaction{id: "id", checkpoint: "0"}))
+	for txs.count < maxTransactionsPerBucket {
+		require.True(t, txs.add(transaction{id: "id", checkpoint: "0"}))
+	}
+	assert.False(t, txs.add(transaction{id: "id", checkpoint: "0"}))
+}
+
+func TestSampleTransaction(t *testing.T) {
+	assert.True(t, sampleTransaction("order-1", 1))
+	assert.False(t, sampleTransaction("order-1", 0))
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		id := fmt.Sprintf("order-%d", i)
+		s := sampleTransaction(id, 0.25)
+		// The decision only depends on the ID.
+		assert.Equal(t, s, sampleTransaction(id, 0.25))
+		if s {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 2500, sampled, 250)
+}
+
+func TestTrackTransaction(t *testing.T) {
+	p := NewProcessor(nil, "env", "service", "v1", &url.URL{Scheme: "http", Host: "agent-address"}, nil)
+	start := time.Now().Truncate(bucketDuration)
+	p.TrackTransactionAt("order-1", "ingest", start)
+	p.SetCheckpointWithParams(context.Background(), options.CheckpointParams{TransactionID: "order-1"}, "direction:out", "topic:orders", "type:kafka")
+	p.SetCheckpoint(context.Background(), "direction:out", "topic:orders", "type:kafka")
+	p.flushInput()
+
+	var got []TransactionSummary
+	for _, payload := range p.flush(time.Now().Add(2 * bucketDuration)) {
+		for _, b := range Summarize(&payload).Buckets {
+			got = append(got, b.Transactions...)
+		}
+	}
+	require.Len(t, got, 2)
+	assert.Equal(t, TransactionSummary{ID: "order-1", Checkpoint: "ingest", Timestamp: start.UTC()}, got[0])
+	assert.Equal(t, "order-1", got[1].ID)
+	assert.Equal(t, "direction:out,topic:orders,type:kafka", got[1].Checkpoint)
+}
+
+func TestTrackTransactionSampleRate(t *testing.T) {
+	t.Setenv("DD_DATA_STREAMS_TRANSACTION_SAMPLE_RATE", "0")
+	p := NewProcessor(nil, "env", "service", "v1", &url.URL{Scheme: "http", Host: "agent-address"}, nil)
+	p.TrackTransaction("order-1", "ingest")
+	assert.Nil(t, p.in.pop())
+}
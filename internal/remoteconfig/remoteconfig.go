@@ -155,6 +155,15 @@ var (
 	stopOnce  sync.Once
 )
 
+var (
+	// pendingMu guards pending and started
+	pendingMu sync.Mutex
+	// pending holds the products subscribed with SubscribeOnStart before the client started
+	pending map[string]ProductCallback
+	// started reports whether the client was started with Start
+	started bool
+)
+
 // newClient creates a new remoteconfig Client
 func newClient(config ClientConfig) (*Client, error) {
 	repo, err := rc.NewUnverifiedRepository()
@@ -188,6 +197,15 @@ func Start(config ClientConfig) error {
 		if err != nil {
 			return
 		}
+		pendingMu.Lock()
+		started = true
+		for product, callback := range pending {
+			if err := Subscribe(product, callback); err != nil {
+				log.Warn("remoteconfig: failed to subscribe to %s: %v", product, err)
+			}
+		}
+		pending = nil
+		pendingMu.Unlock()
 		if !internal.BoolEnv("DD_REMOTE_CONFIGURATION_ENABLED", true) {
 			// Don't start polling if the feature is disabled explicitly
 			return
@@ -236,6 +254,10 @@ func Stop() {
 // Reset destroys the client instance.
 // To be used only in tests to reset the state of the client.
 func Reset() {
+	pendingMu.Lock()
+	pending = nil
+	started = false
+	pendingMu.Unlock()
 	client = nil
 	startOnce = sync.Once{}
 	stopOnce = sync.Once{}
@@ -313,6 +335,38 @@ func Subscribe(product string, callback ProductCallback, capabilities ...Capabil
 	return nil
 }
 
+// SubscribeOnStart subscribes to a product like Subscribe, once the client is started by Start if it isn't
+// already, so that the products started before the tracer use the client it configures.
+func SubscribeOnStart(product string, callback ProductCallback) error {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	if started {
+		return Subscribe(product, callback)
+	}
+	if pending == nil {
+		pending = make(map[string]ProductCallback)
+	}
+	pending[product] = callback
+	return nil
+}
+
+// Unsubscribe removes a product subscribed with Subscribe or SubscribeOnStart, along with its callback.
+func Unsubscribe(product string) error {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	delete(pending, product)
+	if !started {
+		return nil
+	}
+	if client == nil {
+		return ErrClientNotStarted
+	}
+	client.productsWithCallbacksMu.Lock()
+	defer client.productsWithCallbacksMu.Unlock()
+	delete(client.productsWithCallbacks, product)
+	return nil
+}
+
 // RegisterCallback allows registering a callback that will be invoked when the client
 // receives configuration updates. It is up to that callback to then decide what to do
 // depending on the product related to the configuration update.
@@ -0,0 +1,43 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package traceprof
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// FinishedSpan describes a span finished by the tracer, as seen by the
+// profiler's span finish hook.
+type FinishedSpan struct {
+	Service  string
+	Resource string
+	TraceID  string // hex encoded 128-bit trace ID
+	SpanID   uint64
+	Duration time.Duration
+}
+
+var spanFinishHook atomic.Pointer[func(FinishedSpan)]
+
+// SetSpanFinishHook registers fn to be called by the tracer every time a span
+// finishes. A nil fn removes the hook. The hook is called synchronously from
+// the goroutine finishing the span, so it must return quickly.
+func SetSpanFinishHook(fn func(FinishedSpan)) {
+	if fn == nil {
+		spanFinishHook.Store(nil)
+		return
+	}
+	spanFinishHook.Store(&fn)
+}
+
+// SpanFinishHook returns the registered span finish hook, or nil if there is
+// none. This is almost zero-cost when no hook is registered.
+func SpanFinishHook() func(FinishedSpan) {
+	if fn := spanFinishHook.Load(); fn != nil {
+		return *fn
+	}
+	return nil
+}
@@ -0,0 +1,520 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package profiler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/dd-trace-go/v2/internal"
+	"github.com/DataDog/dd-trace-go/v2/internal/log"
+	"github.com/DataDog/dd-trace-go/v2/internal/remoteconfig"
+
+	"github.com/DataDog/datadog-agent/pkg/remoteconfig/state"
+)
+
+const (
+	// defaultCaptureDuration is the duration of an on-demand capture if the
+	// request doesn't specify one.
+	defaultCaptureDuration = 30 * time.Second
+	// maxCaptureDuration is the upper bound for the duration of an on-demand
+	// capture, to bound the overhead of the higher sampling rates.
+	maxCaptureDuration = 5 * time.Minute
+	// defaultCaptureCPUProfileRate is the CPU profiling rate, in Hz, of an
+	// on-demand capture if the request doesn't specify one.
+	defaultCaptureCPUProfileRate = 500
+	// maxCaptureCPUProfileRate is the upper bound for the CPU profiling rate
+	// of an on-demand capture.
+	maxCaptureCPUProfileRate = 1000
+	// defaultCaptureMinInterval is the default minimum amount of time between
+	// two on-demand captures.
+	defaultCaptureMinInterval = 5 * time.Minute
+
+	// captureRCProduct is the remote config product used to schedule
+	// on-demand captures.
+	captureRCProduct = "PROFILING_CAPTURE"
+)
+
+var (
+	errCaptureNotRunning = errors.New("profiler is not running")
+	errCaptureScheduled  = errors.New("an on-demand capture is already scheduled")
+	errCaptureThrottled  = errors.New("on-demand capture skipped: a capture was requested too recently")
+	// errCaptureRunning is returned by the periodic CPU profile when it's
+	// skipped because an on-demand capture owns the CPU profiler.
+	errCaptureRunning = errors.New("an on-demand capture is running")
+)
+
+// captureProfileTypes are the profile types supported by on-demand captures,
+// in upload order.
+var captureProfileTypes = []ProfileType{CPUProfile, BlockProfile, MutexProfile, GoroutineProfile}
+
+// CaptureRequest describes a one-off, high resolution profile capture. See
+// Capture.
+type CaptureRequest struct {
+	// ID identifies the capture. It is attached to the uploaded profile as the
+	// capture_id tag, and is optional.
+	ID string
+	// Duration of the capture. It defaults to 30 seconds and can't exceed 5
+	// minutes.
+	Duration time.Duration
+	// ProfileTypes to capture. Only CPUProfile, BlockProfile, MutexProfile
+	// and GoroutineProfile are supported, and all of them are captured if
+	// left empty.
+	ProfileTypes []ProfileType
+	// CPUProfileRate is the CPU profiling rate, in Hz. It defaults to 500 and
+	// can't exceed 1000.
+	CPUProfileRate int
+}
+
+// normalize applies defaults and bounds to r, and reports whether it asks
+// for unsupported profile types.
+func (r *CaptureRequest) normalize() error {
+	if r.Duration <= 0 {
+		r.Duration = defaultCaptureDuration
+	}
+	r.Duration = min(r.Duration, maxCaptureDuration)
+	if r.CPUProfileRate <= 0 {
+		r.CPUProfileRate = defaultCaptureCPUProfileRate
+	}
+	r.CPUProfileRate = min(r.CPUProfileRate, maxCaptureCPUProfileRate)
+	if len(r.ProfileTypes) == 0 {
+		r.ProfileTypes = captureProfileTypes
+		return nil
+	}
+	for _, t := range r.ProfileTypes {
+		if !isCaptureProfileType(t) {
+			return fmt.Errorf("profile type %s is not supported by on-demand captures", t)
+		}
+	}
+	return nil
+}
+
+func (r *CaptureRequest) wants(t ProfileType) bool {
+	for _, rt := range r.ProfileTypes {
+		if rt == t {
+			return true
+		}
+	}
+	return false
+}
+
+func isCaptureProfileType(t ProfileType) bool {
+	for _, ct := range captureProfileTypes {
+		if ct == t {
+			return true
+		}
+	}
+	return false
+}
+
+// captureConfig controls on-demand captures.
+type captureConfig struct {
+	// remoteEnabled indicates whether captures can be scheduled through
+	// remote configuration.
+	remoteEnabled bool
+	// minInterval is the minimum amount of time between two captures.
+	minInterval time.Duration
+}
+
+// captureConfigFromEnv returns the on-demand capture configuration from the
+// environment, applying defaults as needed.
+func captureConfigFromEnv() captureConfig {
+	return captureConfig{
+		remoteEnabled: internal.BoolEnv("DD_PROFILING_CAPTURE_REMOTE_ENABLED", false),
+		minInterval:   internal.DurationEnv("DD_PROFILING_CAPTURE_MIN_INTERVAL", defaultCaptureMinInterval),
+	}
+}
+
+// captureScheduler holds the on-demand capture waiting to be run by the
+// profiler.
+type captureScheduler struct {
+	pending chan CaptureRequest
+
+	mu          sync.Mutex // guards lastCapture
+	lastCapture time.Time
+}
+
+func newCaptureScheduler() *captureScheduler {
+	return &captureScheduler{pending: make(chan CaptureRequest, 1)}
+}
+
+// schedule queues r to be run by the capture goroutine of the profiler.
+func (s *captureScheduler) schedule(r CaptureRequest, minInterval time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if !s.lastCapture.IsZero() && now.Sub(s.lastCapture) < minInterval {
+		return errCaptureThrottled
+	}
+	select {
+	case s.pending <- r:
+	default:
+		return errCaptureScheduled
+	}
+	s.lastCapture = now
+	return nil
+}
+
+// Capture schedules a one-off, high resolution profile capture. The capture
+// runs alongside the periodic profiles and is uploaded separately, tagged
+// with profile_trigger:manual. As a program can only run one CPU profile at a
+// time, the capture starts once the running periodic CPU profile completes,
+// and the periodic CPU profiles are skipped until the capture ends. During the capture, CPU is sampled at a higher
+// rate and every blocking and mutex contention event is recorded; a goroutine
+// dump with stacks is taken at its end.
+//
+// To bound the overhead, only one capture can be scheduled at a time, and
+// captures can't be requested more often than every five minutes by default.
+// The DD_PROFILING_CAPTURE_MIN_INTERVAL environment variable changes this
+// interval.
+//
+// Capture returns an error if the profiler is not running, if the request is
+// invalid, or if a capture can't be scheduled now.
+func Capture(r CaptureRequest) error {
+	if err := r.normalize(); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if activeProfiler == nil {
+		return errCaptureNotRunning
+	}
+	return activeProfiler.captures.schedule(r, activeProfiler.cfg.capture.minInterval)
+}
+
+// runCaptures runs the scheduled on-demand captures until the profiler is
+// stopped. Captures can last several minutes, so they run on their own
+// goroutine rather than delaying the periodic profiles, and are uploaded as
+// soon as they complete.
+func (p *profiler) runCaptures() {
+	for {
+		select {
+		case <-p.exit:
+			return
+		case r := <-p.captures.pending:
+			bat, err := p.runCapture(r)
+			if err != nil {
+				if err != errProfilerStopped {
+					log.Error("On-demand profile capture failed: %v", err)
+				}
+				continue
+			}
+			p.cfg.statsd.Count("datadog.profiling.go.capture", 1, p.cfg.tags.Slice(), 1)
+			if err := p.outputDir(bat); err != nil {
+				log.Error("Failed to output profile to dir: %v", err)
+			}
+			if err := p.uploadFunc(bat); err != nil {
+				log.Error("Failed to upload on-demand profile capture: %v", err)
+			}
+		}
+	}
+}
+
+// runCapture collects the profiles of an on-demand capture.
+func (p *profiler) runCapture(r CaptureRequest) (batch, error) {
+	if r.wants(CPUProfile) {
+		// Wait for the periodic CPU profile to complete. The next ones are
+		// skipped until the capture ends, see errCaptureRunning.
+		p.cpuProfileMu.Lock()
+		defer p.cpuProfileMu.Unlock()
+	}
+	bat := batch{
+		seq:   p.seq.Add(1) - 1,
+		host:  p.cfg.hostname,
+		start: now(),
+		extraTags: []string{
+			"profile_trigger:manual",
+			fmt.Sprintf("_dd.profiler.go_execution_trace_enabled:%v", p.traceEnabled.Load()),
+			pgoTag(),
+		},
+		customAttributes: p.cfg.customProfilerLabels,
+	}
+	if r.ID != "" {
+		bat.extraTags = append(bat.extraTags, "capture_id:"+r.ID)
+	}
+
+	// Block and mutex profiles are cumulative, so we use the delta between
+	// the start and the end of the capture. The periodic delta profilers are
+	// used concurrently by the collect loop, so the capture has its own. As
+	// a result, the periodic block and mutex profiles covering the capture
+	// include the events recorded at its higher rates.
+	contention := map[ProfileType]*fastDeltaProfiler{}
+	for _, t := range []ProfileType{BlockProfile, MutexProfile} {
+		if !r.wants(t) {
+			continue
+		}
+		dp := newFastDeltaProfiler(profileTypes[t].DeltaValues...)
+		var buf bytes.Buffer
+		if err := p.lookupProfile(t.String(), &buf, 0); err != nil {
+			return bat, err
+		}
+		if _, err := dp.Delta(buf.Bytes()); err != nil {
+			return bat, fmt.Errorf("delta profile error: %s", err)
+		}
+		contention[t] = dp
+	}
+	restore := p.setCaptureRates(r)
+	defer restore()
+
+	var cpu bytes.Buffer
+	if r.wants(CPUProfile) {
+		runtime.SetCPUProfileRate(r.CPUProfileRate)
+		if err := p.startCPUProfile(&cpu); err != nil {
+			return bat, err
+		}
+	}
+	interrupted := p.interruptibleSleep(r.Duration)
+	if r.wants(CPUProfile) {
+		p.stopCPUProfile()
+	}
+	if interrupted {
+		return bat, errProfilerStopped
+	}
+
+	for _, t := range r.ProfileTypes {
+		prof, err := p.collectCaptureProfile(t, &cpu, contention[t])
+		if err != nil {
+			log.Error("Error getting %s profile for on-demand capture: %v; skipping.", t, err)
+			continue
+		}
+		bat.addProfile(prof)
+	}
+	bat.end = now()
+	return bat, nil
+}
+
+// setCaptureRates records every blocking and mutex contention event for the
+// duration of an on-demand capture. It returns a function restoring the
+// profiler's rates.
+func (p *profiler) setCaptureRates(r CaptureRequest) (restore func()) {
+	mutexFraction, blockRate := -1, -1
+	if r.wants(MutexProfile) {
+		mutexFraction = runtime.SetMutexProfileFraction(1)
+	}
+	if r.wants(BlockProfile) {
+		runtime.SetBlockProfileRate(1)
+		blockRate = 0
+		if _, ok := p.cfg.types[BlockProfile]; ok {
+			blockRate = p.cfg.blockRate
+		}
+	}
+	return func() {
+		if mutexFraction >= 0 {
+			runtime.SetMutexProfileFraction(mutexFraction)
+		}
+		if blockRate >= 0 {
+			runtime.SetBlockProfileRate(blockRate)
+		}
+	}
+}
+
+// collectCaptureProfile returns the profile of type t at the end of an
+// on-demand capture.
+func (p *profiler) collectCaptureProfile(t ProfileType, cpu *bytes.Buffer, dp *fastDeltaProfiler) (*profile, error) {
+	switch t {
+	case CPUProfile:
+		return &profile{name: t.Filename(), pt: t, data: cpu.Bytes()}, nil
+	case BlockProfile, MutexProfile:
+		var buf bytes.Buffer
+		if err := p.lookupProfile(t.String(), &buf, 0); err != nil {
+			return nil, err
+		}
+		delta, err := dp.Delta(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("delta profile error: %s", err)
+		}
+		return &profile{name: "delta-" + t.Filename(), pt: t, data: delta}, nil
+	case GoroutineProfile:
+		// Include wait durations and goroutine states, unless stopping the
+		// world to get them would take too long.
+		if runtime.NumGoroutine() <= p.cfg.maxGoroutinesWait {
+			var text, pprof bytes.Buffer
+			if err := p.lookupProfile("goroutine", &text, 2); err != nil {
+				return nil, err
+			}
+			err := goroutineDebug2ToPprof(&text, &pprof, now())
+			return &profile{name: expGoroutineWaitProfile.Filename(), pt: expGoroutineWaitProfile, data: pprof.Bytes()}, err
+		}
+		var buf bytes.Buffer
+		err := p.lookupProfile("goroutine", &buf, 0)
+		return &profile{name: t.Filename(), pt: t, data: buf.Bytes()}, err
+	}
+	return nil, fmt.Errorf("profile type %s is not supported by on-demand captures", t)
+}
+
+// captureParams is the JSON representation of a CaptureRequest, used by
+// remote configuration and CaptureHandler.
+type captureParams struct {
+	ID              string   `json:"id"`
+	DurationSeconds float64  `json:"duration_seconds"`
+	ProfileTypes    []string `json:"profile_types"`
+	CPUProfileRate  int      `json:"cpu_profile_rate"`
+}
+
+func (c captureParams) request() (CaptureRequest, error) {
+	r := CaptureRequest{
+		ID:             c.ID,
+		Duration:       time.Duration(c.DurationSeconds * float64(time.Second)),
+		CPUProfileRate: c.CPUProfileRate,
+	}
+	for _, name := range c.ProfileTypes {
+		t, ok := captureProfileTypeByName(name)
+		if !ok {
+			return r, fmt.Errorf("unknown profile type %q", name)
+		}
+		r.ProfileTypes = append(r.ProfileTypes, t)
+	}
+	return r, nil
+}
+
+func captureProfileTypeByName(name string) (ProfileType, bool) {
+	for _, t := range captureProfileTypes {
+		if strings.EqualFold(t.String(), strings.TrimSpace(name)) {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// CaptureHandler returns an http.Handler scheduling on-demand captures (see
+// Capture) on POST requests. It's meant to be registered on a local or
+// otherwise protected endpoint of the application, e.g.
+//
+//	mux.Handle("/debug/datadog/capture", profiler.CaptureHandler())
+//
+// The capture is described with the following optional query parameters:
+// id, duration (a Go duration, e.g. 1m), types (a comma-separated list of
+// cpu, block, mutex and goroutine) and cpu_profile_rate (in Hz). The handler
+// responds with 202 Accepted once the capture is scheduled, or with 429 Too
+// Many Requests if it can't be scheduled now.
+func CaptureHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		q := req.URL.Query()
+		r := CaptureRequest{ID: q.Get("id")}
+		if v := q.Get("duration"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				http.Error(w, "invalid duration: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			r.Duration = d
+		}
+		if v := q.Get("cpu_profile_rate"); v != "" {
+			rate, err := strconv.Atoi(v)
+			if err != nil {
+				http.Error(w, "invalid cpu_profile_rate: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			r.CPUProfileRate = rate
+		}
+		if v := q.Get("types"); v != "" {
+			for _, name := range strings.Split(v, ",") {
+				t, ok := captureProfileTypeByName(name)
+				if !ok {
+					http.Error(w, fmt.Sprintf("unknown profile type %q", name), http.StatusBadRequest)
+					return
+				}
+				r.ProfileTypes = append(r.ProfileTypes, t)
+			}
+		}
+		switch err := Capture(r); err {
+		case nil:
+			w.WriteHeader(http.StatusAccepted)
+		case errCaptureScheduled, errCaptureThrottled:
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		case errCaptureNotRunning:
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		default:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	})
+}
+
+var (
+	// remoteCapturesMu guards remoteCaptures
+	remoteCapturesMu sync.Mutex
+	// remoteCaptures records the IDs of the captures already scheduled
+	// through remote configuration, by configuration path, so that
+	// configurations delivered again (e.g. after an agent restart) don't
+	// trigger them twice. The entries are removed along with the
+	// configurations.
+	remoteCaptures = map[string]string{}
+)
+
+// startRemoteCaptures subscribes to the remote configuration product used to
+// schedule on-demand captures. The remote config client is the one started by
+// the tracer, with its configuration: when the profiler is started first, the
+// subscription is made once the tracer starts it.
+func (p *profiler) startRemoteCaptures() error {
+	return remoteconfig.SubscribeOnStart(captureRCProduct, onCaptureRCUpdate)
+}
+
+// stopRemoteCaptures removes the subscription made by startRemoteCaptures, so
+// that the updates delivered after the profiler stopped aren't reported as
+// errors.
+func (p *profiler) stopRemoteCaptures() {
+	if err := remoteconfig.Unsubscribe(captureRCProduct); err != nil {
+		log.Debug("Failed to unsubscribe from remotely triggered profile captures: %v", err)
+	}
+}
+
+// onCaptureRCUpdate schedules the captures found in a remote configuration
+// update. Removed configurations are acknowledged and forgotten.
+func onCaptureRCUpdate(u remoteconfig.ProductUpdate) map[string]state.ApplyStatus {
+	statuses := make(map[string]state.ApplyStatus, len(u))
+	for path, raw := range u {
+		if err := scheduleRemoteCapture(path, raw); err != nil {
+			log.Warn("Failed to schedule on-demand profile capture from remote config %s: %v", path, err)
+			statuses[path] = state.ApplyStatus{State: state.ApplyStateError, Error: err.Error()}
+			continue
+		}
+		statuses[path] = state.ApplyStatus{State: state.ApplyStateAcknowledged}
+	}
+	return statuses
+}
+
+func scheduleRemoteCapture(path string, raw []byte) error {
+	if raw == nil {
+		remoteCapturesMu.Lock()
+		defer remoteCapturesMu.Unlock()
+		delete(remoteCaptures, path)
+		return nil
+	}
+	var params captureParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return err
+	}
+	if params.ID == "" {
+		params.ID = path
+	}
+	r, err := params.request()
+	if err != nil {
+		return err
+	}
+	remoteCapturesMu.Lock()
+	defer remoteCapturesMu.Unlock()
+	if id, ok := remoteCaptures[path]; ok && id == r.ID {
+		return nil
+	}
+	if err := Capture(r); err != nil {
+		return err
+	}
+	remoteCaptures[path] = r.ID
+	return nil
+}
@@ -0,0 +1,189 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package profiler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/remoteconfig/state"
+	"github.com/DataDog/dd-trace-go/v2/internal/remoteconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureRequestNormalize(t *testing.T) {
+	r := CaptureRequest{}
+	require.NoError(t, r.normalize())
+	assert.Equal(t, defaultCaptureDuration, r.Duration)
+	assert.Equal(t, defaultCaptureCPUProfileRate, r.CPUProfileRate)
+	assert.Equal(t, captureProfileTypes, r.ProfileTypes)
+
+	r = CaptureRequest{Duration: time.Hour, CPUProfileRate: 100000, ProfileTypes: []ProfileType{CPUProfile}}
+	require.NoError(t, r.normalize())
+	assert.Equal(t, maxCaptureDuration, r.Duration)
+	assert.Equal(t, maxCaptureCPUProfileRate, r.CPUProfileRate)
+
+	r = CaptureRequest{ProfileTypes: []ProfileType{HeapProfile}}
+	assert.Error(t, r.normalize())
+}
+
+// waitForCapture returns the first uploaded on-demand capture.
+func waitForCapture(t *testing.T, profiles <-chan profileMeta) profileMeta {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case m := <-profiles:
+			if slices.Contains(m.tags, "profile_trigger:manual") {
+				return m
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for the capture")
+		}
+	}
+}
+
+func TestCapture(t *testing.T) {
+	t.Run("not-running", func(t *testing.T) {
+		assert.Equal(t, errCaptureNotRunning, Capture(CaptureRequest{}))
+	})
+
+	t.Run("capture", func(t *testing.T) {
+		profiles := startTestProfiler(t, 10,
+			WithProfileTypes(),
+			WithPeriod(time.Hour),
+		)
+		require.NoError(t, Capture(CaptureRequest{ID: "abc", Duration: 50 * time.Millisecond}))
+		assert.Equal(t, errCaptureThrottled, Capture(CaptureRequest{}))
+
+		m := waitForCapture(t, profiles)
+		assert.Contains(t, m.tags, "capture_id:abc")
+		for _, name := range []string{"cpu.pprof", "delta-block.pprof", "delta-mutex.pprof", "goroutineswait.pprof"} {
+			assert.Contains(t, m.attachments, name)
+		}
+		assert.NotContains(t, m.attachments, "heap.pprof")
+	})
+
+	t.Run("profile-types", func(t *testing.T) {
+		t.Setenv("DD_PROFILING_CAPTURE_MIN_INTERVAL", "0")
+		profiles := startTestProfiler(t, 10,
+			WithProfileTypes(),
+			WithPeriod(time.Hour),
+		)
+		require.NoError(t, Capture(CaptureRequest{Duration: 10 * time.Millisecond, ProfileTypes: []ProfileType{MutexProfile}}))
+		m := waitForCapture(t, profiles)
+		assert.Contains(t, m.attachments, "delta-mutex.pprof")
+		assert.NotContains(t, m.attachments, "cpu.pprof")
+	})
+
+	t.Run("periodic", func(t *testing.T) {
+		profiles := startTestProfiler(t, 10,
+			WithProfileTypes(CPUProfile, HeapProfile),
+			WithPeriod(10*time.Millisecond),
+		)
+		require.NoError(t, Capture(CaptureRequest{Duration: time.Minute, ProfileTypes: []ProfileType{CPUProfile}}))
+
+		// The periodic profiles are still uploaded while the capture runs,
+		// without their CPU profile as the capture owns the CPU profiler.
+		timeout := time.After(10 * time.Second)
+		for skipped := 0; skipped < 3; {
+			select {
+			case m := <-profiles:
+				require.NotContains(t, m.tags, "profile_trigger:manual")
+				assert.Contains(t, m.attachments, "delta-heap.pprof")
+				if _, ok := m.attachments["cpu.pprof"]; !ok {
+					skipped++
+				}
+			case <-timeout:
+				t.Fatal("timed out waiting for the periodic profiles")
+			}
+		}
+	})
+}
+
+func TestCaptureHandler(t *testing.T) {
+	t.Setenv("DD_PROFILING_CAPTURE_MIN_INTERVAL", "1h")
+	profiles := startTestProfiler(t, 10,
+		WithProfileTypes(),
+		WithPeriod(time.Hour),
+	)
+	h := CaptureHandler()
+	serve := func(method, target string) int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve("GET", "/"))
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/?types=heap"))
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/?duration=soon"))
+	assert.Equal(t, http.StatusAccepted, serve("POST", "/?id=local&duration=10ms&types=cpu,goroutine"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("POST", "/"))
+
+	m := waitForCapture(t, profiles)
+	assert.Contains(t, m.tags, "capture_id:local")
+	assert.Contains(t, m.attachments, "cpu.pprof")
+	assert.Contains(t, m.attachments, "goroutineswait.pprof")
+	assert.NotContains(t, m.attachments, "delta-block.pprof")
+}
+
+func TestCaptureRemoteConfig(t *testing.T) {
+	t.Setenv("DD_PROFILING_CAPTURE_MIN_INTERVAL", "0")
+	profiles := startTestProfiler(t, 10,
+		WithProfileTypes(),
+		WithPeriod(time.Hour),
+	)
+
+	statuses := onCaptureRCUpdate(map[string][]byte{
+		"datadog/2/PROFILING_CAPTURE/remote/config":  []byte(`{"id":"remote","duration_seconds":0.01,"profile_types":["block"]}`),
+		"datadog/2/PROFILING_CAPTURE/invalid/config": []byte(`{"profile_types":["heap"]}`),
+		"datadog/2/PROFILING_CAPTURE/removed/config": nil,
+	})
+	assert.Equal(t, state.ApplyStateAcknowledged, statuses["datadog/2/PROFILING_CAPTURE/remote/config"].State)
+	assert.Equal(t, state.ApplyStateError, statuses["datadog/2/PROFILING_CAPTURE/invalid/config"].State)
+	assert.Equal(t, state.ApplyStateAcknowledged, statuses["datadog/2/PROFILING_CAPTURE/removed/config"].State)
+
+	m := waitForCapture(t, profiles)
+	assert.Contains(t, m.tags, "capture_id:remote")
+	assert.Contains(t, m.attachments, "delta-block.pprof")
+
+	// The same configuration delivered again doesn't trigger another capture.
+	require.NoError(t, scheduleRemoteCapture("datadog/2/PROFILING_CAPTURE/remote/config", []byte(`{"id":"remote"}`)))
+	assert.Empty(t, activeProfiler.captures.pending)
+
+	// Removed configurations are forgotten.
+	onCaptureRCUpdate(map[string][]byte{"datadog/2/PROFILING_CAPTURE/remote/config": nil})
+	remoteCapturesMu.Lock()
+	assert.NotContains(t, remoteCaptures, "datadog/2/PROFILING_CAPTURE/remote/config")
+	remoteCapturesMu.Unlock()
+}
+
+func TestCaptureRemoteConfigSubscription(t *testing.T) {
+	remoteconfig.Reset()
+	defer remoteconfig.Reset()
+	t.Setenv("DD_REMOTE_CONFIGURATION_ENABLED", "false")
+
+	// The profiler is started before the tracer: it doesn't start the remote config client itself.
+	startTestProfiler(t, 10, WithProfileTypes(), WithPeriod(10*time.Millisecond), WithRemoteCapture(true))
+	_, err := remoteconfig.HasProduct(captureRCProduct)
+	assert.Equal(t, remoteconfig.ErrClientNotStarted, err)
+
+	// The subscription is made once the tracer starts it.
+	require.NoError(t, remoteconfig.Start(remoteconfig.DefaultClientConfig()))
+	found, err := remoteconfig.HasProduct(captureRCProduct)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	// The subscription is removed when the profiler stops.
+	Stop()
+	found, err = remoteconfig.HasProduct(captureRCProduct)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
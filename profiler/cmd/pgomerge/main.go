@@ -0,0 +1,195 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+// Command pgomerge merges CPU profiles collected by the profiler into a single
+// profile suitable for profile-guided optimization (PGO), i.e. a default.pgo
+// file to be placed in the main package of a program.
+//
+// Inputs are directories written by the profiler when configured with
+// WithOutputDir or DD_PROFILING_OUTPUT_DIR (every cpu.pprof file found in them
+// is used), individual profile files, or URLs, e.g. of an endpoint served by
+// profiler.LatestProfilesHandler:
+//
+//	pgomerge -o ./cmd/server/default.pgo -since 24h /var/lib/profiles
+//	pgomerge -o default.pgo 'http://localhost:8080/debug/datadog/profiles?name=cpu.pprof'
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DataDog/dd-trace-go/v2/profiler/internal/pprofutils"
+
+	"github.com/google/pprof/profile"
+)
+
+// cpuSampleTypes are the sample types of the CPU profiles produced by the Go
+// runtime, which are the ones the compiler uses for PGO.
+var cpuSampleTypes = []pprofutils.ValueType{
+	{Type: "samples", Unit: "count"},
+	{Type: "cpu", Unit: "nanoseconds"},
+}
+
+// cpuProfileName is the name of the CPU profiles written by the profiler.
+const cpuProfileName = "cpu.pprof"
+
+func main() {
+	var (
+		output = flag.String("o", "default.pgo", "Path of the merged profile")
+		since  = flag.Duration("since", 0, "Only merge profiles found in directories and modified within this duration (0 means no limit)")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: pgomerge [flags] <dir|file|url>...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var minModTime time.Time
+	if *since > 0 {
+		minModTime = time.Now().Add(-*since)
+	}
+	merged, n, err := merge(flag.Args(), minModTime)
+	if err != nil {
+		log.Fatal(err)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := merged.Write(f); err != nil {
+		f.Close()
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("merged %d CPU profiles into %s", n, *output)
+}
+
+// merge parses the CPU profiles found in inputs and merges them. Profiles
+// found in directories and modified before minModTime are ignored. It returns
+// the merged profile and the number of profiles it was merged from.
+func merge(inputs []string, minModTime time.Time) (*profile.Profile, int, error) {
+	var profiles []*profile.Profile
+	for _, in := range inputs {
+		data, err := readInput(in, minModTime)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, d := range data {
+			p, err := profile.Parse(bytes.NewReader(d.data))
+			if err != nil {
+				return nil, 0, fmt.Errorf("parsing %s: %v", d.name, err)
+			}
+			if !isCPUProfile(p) {
+				return nil, 0, fmt.Errorf("%s is not a CPU profile", d.name)
+			}
+			if len(p.Sample) == 0 {
+				continue
+			}
+			// The compiler ignores labels, and they would prevent samples
+			// with the same stack from being merged.
+			for _, s := range p.Sample {
+				s.Label = nil
+				s.NumLabel = nil
+				s.NumUnit = nil
+			}
+			profiles = append(profiles, p)
+		}
+	}
+	if len(profiles) == 0 {
+		return nil, 0, fmt.Errorf("no CPU profile with samples found")
+	}
+	merged, err := profile.Merge(profiles)
+	if err != nil {
+		return nil, 0, err
+	}
+	return merged, len(profiles), nil
+}
+
+func isCPUProfile(p *profile.Profile) bool {
+	if len(p.SampleType) != len(cpuSampleTypes) {
+		return false
+	}
+	for i, st := range p.SampleType {
+		if st.Type != cpuSampleTypes[i].Type || st.Unit != cpuSampleTypes[i].Unit {
+			return false
+		}
+	}
+	return true
+}
+
+type inputData struct {
+	name string
+	data []byte
+}
+
+// readInput returns the profiles designated by in, which is either a URL, a
+// directory or a file.
+func readInput(in string, minModTime time.Time) ([]inputData, error) {
+	if strings.HasPrefix(in, "http://") || strings.HasPrefix(in, "https://") {
+		data, err := fetch(in)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s: %v", in, err)
+		}
+		return []inputData{{name: in, data: data}}, nil
+	}
+	info, err := os.Stat(in)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(in)
+		return []inputData{{name: in, data: data}}, err
+	}
+	var profiles []inputData
+	err = filepath.WalkDir(in, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != cpuProfileName {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().Before(minModTime) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		profiles = append(profiles, inputData{name: path, data: data})
+		return nil
+	})
+	return profiles, err
+}
+
+func fetch(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
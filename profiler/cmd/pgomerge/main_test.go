@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeProfile writes a profile with a single sample of the given function to
+// path, and returns path.
+func writeProfile(t *testing.T, path, fn string, sampleTypes ...*profile.ValueType) string {
+	t.Helper()
+	if len(sampleTypes) == 0 {
+		sampleTypes = []*profile.ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}}
+	}
+	f := &profile.Function{ID: 1, Name: fn}
+	loc := &profile.Location{ID: 1, Line: []profile.Line{{Function: f}}}
+	p := &profile.Profile{
+		SampleType: sampleTypes,
+		PeriodType: &profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:     10000000,
+		Function:   []*profile.Function{f},
+		Location:   []*profile.Location{loc},
+		Sample: []*profile.Sample{{
+			Location: []*profile.Location{loc},
+			Value:    make([]int64, len(sampleTypes)),
+			Label:    map[string][]string{"span id": {"1234"}},
+		}},
+	}
+	for i := range p.Sample[0].Value {
+		p.Sample[0].Value[i] = 1
+	}
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	out, err := os.Create(path)
+	require.NoError(t, err)
+	defer out.Close()
+	require.NoError(t, p.Write(out))
+	return path
+}
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	writeProfile(t, filepath.Join(dir, "20250102T030405Z-000000", "cpu.pprof"), "main.a")
+	writeProfile(t, filepath.Join(dir, "20250102T030505Z-000001", "cpu.pprof"), "main.a")
+	old := writeProfile(t, filepath.Join(dir, "20250101T030505Z-000000", "cpu.pprof"), "main.old")
+	writeProfile(t, filepath.Join(dir, "20250102T030505Z-000001", "delta-heap.pprof"), "main.heap")
+	single := writeProfile(t, filepath.Join(t.TempDir(), "extra.pprof"), "main.b")
+
+	t.Run("all", func(t *testing.T) {
+		merged, n, err := merge([]string{dir, single}, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, 4, n)
+		values := map[string]int64{}
+		for _, s := range merged.Sample {
+			assert.Empty(t, s.Label)
+			values[s.Location[0].Line[0].Function.Name] += s.Value[0]
+		}
+		assert.Equal(t, map[string]int64{"main.a": 2, "main.old": 1, "main.b": 1}, values)
+	})
+
+	t.Run("since", func(t *testing.T) {
+		past := time.Now().Add(-48 * time.Hour)
+		require.NoError(t, os.Chtimes(old, past, past))
+		_, n, err := merge([]string{dir}, time.Now().Add(-24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("not-cpu", func(t *testing.T) {
+		heap := writeProfile(t, filepath.Join(t.TempDir(), "heap.pprof"), "main.heap", &profile.ValueType{Type: "alloc_space", Unit: "bytes"})
+		_, _, err := merge([]string{heap}, time.Time{})
+		assert.Error(t, err)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, _, err := merge([]string{t.TempDir()}, time.Time{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package profiler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// defaultOutputDirMaxBatches is the default number of profile batches kept in
+// the output directory, see WithOutputDirMaxBatches. With the default profiling
+// period, this is roughly two hours of profiles.
+const defaultOutputDirMaxBatches = 120
+
+// outputDirBatchName matches the names of the directories written by
+// (*profiler).outputDir. Only these directories are removed by the rotation.
+var outputDirBatchName = regexp.MustCompile(`^\d{8}T\d{6}Z(-\d+)?$`)
+
+// outputDir writes the profiles of bat to their own subdirectory of the
+// configured output directory, and removes the oldest batches exceeding the
+// configured limit.
+func (p *profiler) outputDir(bat batch) error {
+	if p.cfg.outputDir == "" {
+		return nil
+	}
+	// Batches are written from the upload goroutine as well as from the
+	// flight recorder, so make sure the rotation doesn't race with writes.
+	p.outputMu.Lock()
+	defer p.outputMu.Unlock()
+
+	// Basic ISO 8601 Format in UTC followed by the sequence number as the
+	// name for the directories, so that batches ending within the same second
+	// don't overwrite each other, and lexical order is chronological order.
+	dir := fmt.Sprintf("%s-%06d", bat.end.UTC().Format("20060102T150405Z"), bat.seq)
+	dirPath := filepath.Join(p.cfg.outputDir, dir)
+	// 0755 is what mkdir does, should be reasonable for the use cases here.
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return err
+	}
+
+	for _, prof := range bat.profiles {
+		filePath := filepath.Join(dirPath, prof.name)
+		// 0644 is what touch does, should be reasonable for the use cases here.
+		if err := os.WriteFile(filePath, prof.data, 0644); err != nil {
+			return err
+		}
+	}
+	return p.rotateOutputDir()
+}
+
+// rotateOutputDir removes the oldest batches of the output directory beyond
+// the configured maximum number of batches.
+func (p *profiler) rotateOutputDir() error {
+	if p.cfg.outputDirMaxBatches <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(p.cfg.outputDir)
+	if err != nil {
+		return err
+	}
+	var batches []string
+	for _, e := range entries {
+		if e.IsDir() && outputDirBatchName.MatchString(e.Name()) {
+			batches = append(batches, e.Name())
+		}
+	}
+	if len(batches) <= p.cfg.outputDirMaxBatches {
+		return nil
+	}
+	sort.Strings(batches)
+	for _, name := range batches[:len(batches)-p.cfg.outputDirMaxBatches] {
+		if err := os.RemoveAll(filepath.Join(p.cfg.outputDir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// latestProfiles describes the most recent batch of periodic profiles, as
+// served by LatestProfilesHandler.
+type latestProfiles struct {
+	Seq      uint64    `json:"seq"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Profiles []string  `json:"profiles"`
+}
+
+// LatestProfilesHandler returns an http.Handler serving the most recent batch
+// of periodic profiles collected by the running profiler. Heap, block and
+// mutex profiles are delta profiles covering the last profiling period (unless
+// delta profiles are disabled, see WithDeltaProfiles), which makes them
+// directly usable with go tool pprof, e.g.
+//
+//	mux.Handle("/debug/datadog/profiles", profiler.LatestProfilesHandler())
+//	go tool pprof http://localhost:8080/debug/datadog/profiles?name=delta-heap.pprof
+//
+// Without the name query parameter, the handler responds with a JSON document
+// listing the profiles of the batch, along with its start and end times. The
+// handler should only be registered on a local or otherwise protected
+// endpoint, since profiles reveal details about the application.
+func LatestProfilesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		mu.Lock()
+		var bat *batch
+		if activeProfiler != nil {
+			bat = activeProfiler.latest.Load()
+		}
+		running := activeProfiler != nil
+		mu.Unlock()
+		if !running {
+			http.Error(w, "profiler is not running", http.StatusServiceUnavailable)
+			return
+		}
+		if bat == nil {
+			http.Error(w, "no profiles collected yet", http.StatusNotFound)
+			return
+		}
+
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			index := latestProfiles{Seq: bat.seq, Start: bat.start, End: bat.end, Profiles: []string{}}
+			for _, prof := range bat.profiles {
+				index.Profiles = append(index.Profiles, prof.name)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(index)
+			return
+		}
+		for _, prof := range bat.profiles {
+			if prof.name != name {
+				continue
+			}
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", prof.name))
+			w.Write(prof.data)
+			return
+		}
+		http.Error(w, fmt.Sprintf("profile %q not found", name), http.StatusNotFound)
+	})
+}
@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package profiler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputDirRotation(t *testing.T) {
+	dir := t.TempDir()
+	// Directories not written by the profiler are left alone.
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "keep"), 0755))
+
+	p, err := unstartedProfiler(WithOutputDir(dir), WithOutputDirMaxBatches(2))
+	require.NoError(t, err)
+	end := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	for seq := uint64(0); seq < 4; seq++ {
+		bat := batch{
+			seq: seq,
+			// Two batches end within the same second.
+			end:      end.Add(time.Duration(seq/2) * time.Second),
+			profiles: []*profile{{name: "cpu.pprof", data: []byte("cpu")}},
+		}
+		require.NoError(t, p.outputDir(bat))
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"20250102T030406Z-000002", "20250102T030406Z-000003", "keep"}, names)
+}
+
+func TestOutputDirMaxBatches(t *testing.T) {
+	p, err := unstartedProfiler(WithOutputDir(t.TempDir()))
+	require.NoError(t, err)
+	assert.Equal(t, defaultOutputDirMaxBatches, p.cfg.outputDirMaxBatches)
+
+	t.Setenv("DD_PROFILING_OUTPUT_DIR_MAX_BATCHES", "5")
+	p, err = unstartedProfiler(WithOutputDir(t.TempDir()))
+	require.NoError(t, err)
+	assert.Equal(t, 5, p.cfg.outputDirMaxBatches)
+
+	p, err = unstartedProfiler(WithOutputDir(t.TempDir()), WithOutputDirMaxBatches(0))
+	require.NoError(t, err)
+	assert.Equal(t, 0, p.cfg.outputDirMaxBatches)
+}
+
+func TestLatestProfilesHandler(t *testing.T) {
+	h := LatestProfilesHandler()
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+		return rec
+	}
+
+	t.Run("not-running", func(t *testing.T) {
+		assert.Equal(t, http.StatusServiceUnavailable, get("/").Code)
+	})
+
+	t.Run("running", func(t *testing.T) {
+		profiles := startTestProfiler(t, 1,
+			WithProfileTypes(HeapProfile),
+			WithPeriod(10*time.Millisecond),
+		)
+		m := <-profiles
+
+		rec := get("/")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var index latestProfiles
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &index))
+		assert.Contains(t, index.Profiles, "delta-heap.pprof")
+
+		rec = get("/?name=delta-heap.pprof")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.NotEmpty(t, rec.Body.Bytes())
+		if index.Seq == 0 {
+			// The handler serves the batch that was just uploaded.
+			assert.Equal(t, m.attachments["delta-heap.pprof"], rec.Body.Bytes())
+		}
+
+		assert.Equal(t, http.StatusNotFound, get("/?name=cpu.pprof").Code)
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("POST", "/", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
@@ -0,0 +1,269 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package profiler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/dd-trace-go/v2/internal"
+	"github.com/DataDog/dd-trace-go/v2/internal/log"
+	"github.com/DataDog/dd-trace-go/v2/internal/traceprof"
+)
+
+const (
+	// defaultFlightRecorderWindow is the default approximate duration of
+	// execution trace data kept in memory by the flight recorder.
+	defaultFlightRecorderWindow = 10 * time.Second
+	// defaultFlightRecorderMinInterval is the default minimum amount of time
+	// between two flight recorder snapshots. Snapshots stop the world for a
+	// short time and are uploaded as individual profiles, so we don't want to
+	// take one for every slow span of a service having a bad time.
+	defaultFlightRecorderMinInterval = time.Minute
+)
+
+var (
+	errFlightRecorderNotRunning = errors.New("flight recorder is not running")
+	errFlightRecorderThrottled  = errors.New("flight recorder snapshot skipped: a snapshot was taken too recently")
+)
+
+// FlightRecorderRule describes which finished spans trigger an execution
+// trace snapshot of the flight recorder. See WithFlightRecorder.
+type FlightRecorderRule struct {
+	// Service is a glob pattern matched against the span service. An empty
+	// pattern matches every service.
+	Service string
+	// Resource is a glob pattern matched against the span resource. An empty
+	// pattern matches every resource.
+	Resource string
+	// MinDuration is the span duration above which a snapshot is taken.
+	MinDuration time.Duration
+}
+
+// flightRecorderConfig controls the execution trace flight recorder.
+type flightRecorderConfig struct {
+	// enabled indicates whether the flight recorder is enabled.
+	enabled bool
+	// rules are the slow span rules triggering snapshots.
+	rules []FlightRecorderRule
+	// window is the approximate duration of the in-memory trace window.
+	window time.Duration
+	// minInterval is the minimum amount of time between two snapshots.
+	minInterval time.Duration
+}
+
+// compiledFlightRecorderRule is a FlightRecorderRule with its patterns
+// compiled. A nil pattern matches everything.
+type compiledFlightRecorderRule struct {
+	service     *regexp.Regexp
+	resource    *regexp.Regexp
+	minDuration time.Duration
+}
+
+func (r *compiledFlightRecorderRule) match(s traceprof.FinishedSpan) bool {
+	if s.Duration < r.minDuration {
+		return false
+	}
+	if r.service != nil && !r.service.MatchString(s.Service) {
+		return false
+	}
+	return r.resource == nil || r.resource.MatchString(s.Resource)
+}
+
+// globMatch compiles pattern string into glob format, i.e. regular expressions
+// with only '?' and '*' treated as regex metacharacters.
+func globMatch(pattern string) *regexp.Regexp {
+	if pattern == "" || pattern == "*" {
+		return nil
+	}
+	pattern = regexp.QuoteMeta(pattern)
+	pattern = strings.ReplaceAll(pattern, "\\?", ".")
+	pattern = strings.ReplaceAll(pattern, "\\*", ".*")
+	return regexp.MustCompile(fmt.Sprintf("(?i)^%s$", pattern))
+}
+
+// traceFlightRecorder keeps a moving window over the most recent execution
+// trace data. Its implementation depends on the Go version.
+type traceFlightRecorder interface {
+	Start() error
+	Stop() error
+	Snapshot(w io.Writer) error
+}
+
+// flightRecorder keeps a rolling window of execution trace data in memory and
+// uploads a snapshot of it when a slow span finishes or when explicitly asked
+// to with SnapshotExecutionTrace.
+type flightRecorder struct {
+	p     *profiler
+	fr    traceFlightRecorder
+	rules []compiledFlightRecorderRule
+
+	mu           sync.Mutex // guards lastSnapshot and stopped
+	lastSnapshot time.Time
+	// stopped is set once the profiler is stopping. No snapshot is started
+	// afterwards, as the profiler wait group must not be added to while it's
+	// being waited for.
+	stopped bool
+}
+
+func newFlightRecorder(p *profiler) *flightRecorder {
+	f := &flightRecorder{
+		p:  p,
+		fr: newTraceFlightRecorder(p.cfg.flightRecorder.window, p.cfg.traceConfig.Limit),
+	}
+	for _, r := range p.cfg.flightRecorder.rules {
+		f.rules = append(f.rules, compiledFlightRecorderRule{
+			service:     globMatch(r.Service),
+			resource:    globMatch(r.Resource),
+			minDuration: r.MinDuration,
+		})
+	}
+	return f
+}
+
+// start starts the flight recorder and registers the span finish hook used to
+// detect slow spans.
+func (f *flightRecorder) start() error {
+	if err := f.fr.Start(); err != nil {
+		return err
+	}
+	if len(f.rules) > 0 {
+		traceprof.SetSpanFinishHook(f.onSpanFinish)
+	}
+	return nil
+}
+
+// halt unregisters the span finish hook and prevents new snapshots from being
+// started. The hook may still be running on other goroutines, so it's not
+// enough to unregister it: once halt returns, the snapshots already started
+// are the only ones tracked by the profiler wait group.
+func (f *flightRecorder) halt() {
+	traceprof.SetSpanFinishHook(nil)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stopped = true
+}
+
+// stop unregisters the span finish hook and stops the flight recorder.
+func (f *flightRecorder) stop() {
+	traceprof.SetSpanFinishHook(nil)
+	if err := f.fr.Stop(); err != nil {
+		log.Warn("Stopping the execution trace flight recorder: %v", err)
+	}
+}
+
+func (f *flightRecorder) onSpanFinish(s traceprof.FinishedSpan) {
+	for i := range f.rules {
+		if !f.rules[i].match(s) {
+			continue
+		}
+		if err := f.startSnapshot("slow_span", s.TraceID, s.SpanID); err != nil {
+			log.Debug("Not taking a flight recorder snapshot for a slow span: %v", err)
+		}
+		return
+	}
+}
+
+// startSnapshot takes a snapshot in the background, tracked by the profiler
+// wait group, unless the flight recorder is stopping or a snapshot was taken
+// too recently.
+func (f *flightRecorder) startSnapshot(trigger, traceID string, spanID uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stopped {
+		return errFlightRecorderNotRunning
+	}
+	now := time.Now()
+	if !f.lastSnapshot.IsZero() && now.Sub(f.lastSnapshot) < f.p.cfg.flightRecorder.minInterval {
+		return errFlightRecorderThrottled
+	}
+	f.lastSnapshot = now
+	// The wait group is added to under f.mu, so that it can't happen after
+	// halt returned and the profiler started waiting.
+	f.p.wg.Add(1)
+	go func() {
+		defer f.p.wg.Done()
+		if err := f.snapshot(trigger, traceID, spanID); err != nil {
+			log.Warn("Failed to snapshot the execution trace flight recorder: %v", err)
+		}
+	}()
+	return nil
+}
+
+// snapshot writes the current flight recorder window and uploads it as an
+// execution trace, tagged with the trigger and the triggering trace and span
+// IDs, if any.
+func (f *flightRecorder) snapshot(trigger, traceID string, spanID uint64) error {
+	end := now()
+	var buf bytes.Buffer
+	if err := f.fr.Snapshot(&buf); err != nil {
+		return err
+	}
+	bat := batch{
+		seq:   f.p.seq.Add(1) - 1,
+		host:  f.p.cfg.hostname,
+		start: end.Add(-f.p.cfg.flightRecorder.window),
+		end:   end,
+		extraTags: []string{
+			"go_execution_traced:yes",
+			"_dd.profiler.go_execution_trace_enabled:true",
+			"flight_recorder_trigger:" + trigger,
+			pgoTag(),
+		},
+		customAttributes: f.p.cfg.customProfilerLabels,
+	}
+	if traceID != "" {
+		bat.extraTags = append(bat.extraTags, "trigger_trace_id:"+traceID)
+	}
+	if spanID != 0 {
+		bat.extraTags = append(bat.extraTags, fmt.Sprintf("trigger_span_id:%d", spanID))
+	}
+	bat.addProfile(&profile{
+		name: executionTrace.Filename(),
+		pt:   executionTrace,
+		data: buf.Bytes(),
+	})
+	tags := append(f.p.cfg.tags.Slice(), "flight_recorder_trigger:"+trigger)
+	f.p.cfg.statsd.Count("datadog.profiling.go.flight_recorder.snapshot", 1, tags, 1)
+	if err := f.p.outputDir(bat); err != nil {
+		log.Error("Failed to output profile to dir: %v", err)
+	}
+	return f.p.uploadFunc(bat)
+}
+
+// SnapshotExecutionTrace uploads the execution trace data currently held in
+// memory by the flight recorder, tagged with the given trace and span IDs
+// (either may be left empty). It returns an error if the profiler is not
+// running with the flight recorder enabled (see WithFlightRecorder), or if a
+// snapshot was taken too recently. The minimum interval between snapshots is
+// one minute by default, and can be changed with the
+// DD_PROFILING_FLIGHT_RECORDER_MIN_INTERVAL environment variable.
+//
+// The snapshot is taken and uploaded in the background.
+func SnapshotExecutionTrace(traceID string, spanID uint64) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if activeProfiler == nil || activeProfiler.flightRecorder == nil {
+		return errFlightRecorderNotRunning
+	}
+	return activeProfiler.flightRecorder.startSnapshot("manual", traceID, spanID)
+}
+
+// flightRecorderConfigFromEnv returns the flight recorder window and minimum
+// snapshot interval from the environment, applying defaults as needed.
+func flightRecorderConfigFromEnv() flightRecorderConfig {
+	return flightRecorderConfig{
+		enabled:     internal.BoolEnv("DD_PROFILING_FLIGHT_RECORDER_ENABLED", false),
+		window:      internal.DurationEnv("DD_PROFILING_FLIGHT_RECORDER_WINDOW", defaultFlightRecorderWindow),
+		minInterval: internal.DurationEnv("DD_PROFILING_FLIGHT_RECORDER_MIN_INTERVAL", defaultFlightRecorderMinInterval),
+	}
+}
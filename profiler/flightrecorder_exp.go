@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+//go:build !go1.25
+
+package profiler
+
+import (
+	"io"
+	"time"
+
+	exptrace "golang.org/x/exp/trace"
+)
+
+// expFlightRecorder uses the experimental flight recorder of the
+// golang.org/x/exp/trace package for Go versions older than 1.25, which don't
+// have one in the runtime/trace package.
+type expFlightRecorder struct {
+	fr *exptrace.FlightRecorder
+}
+
+func newTraceFlightRecorder(window time.Duration, size int) traceFlightRecorder {
+	fr := exptrace.NewFlightRecorder()
+	fr.SetPeriod(window)
+	if size > 0 {
+		fr.SetSize(size)
+	}
+	return &expFlightRecorder{fr: fr}
+}
+
+func (r *expFlightRecorder) Start() error { return r.fr.Start() }
+
+func (r *expFlightRecorder) Stop() error { return r.fr.Stop() }
+
+func (r *expFlightRecorder) Snapshot(w io.Writer) error {
+	_, err := r.fr.WriteTo(w)
+	return err
+}
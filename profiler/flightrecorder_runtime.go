@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+//go:build go1.25
+
+package profiler
+
+import (
+	"io"
+	"runtime/trace"
+	"time"
+)
+
+// runtimeFlightRecorder uses the flight recorder of the runtime/trace package,
+// available as of Go 1.25.
+type runtimeFlightRecorder struct {
+	fr *trace.FlightRecorder
+}
+
+func newTraceFlightRecorder(window time.Duration, size int) traceFlightRecorder {
+	cfg := trace.FlightRecorderConfig{MinAge: window}
+	if size > 0 {
+		cfg.MaxBytes = uint64(size)
+	}
+	return &runtimeFlightRecorder{fr: trace.NewFlightRecorder(cfg)}
+}
+
+func (r *runtimeFlightRecorder) Start() error { return r.fr.Start() }
+
+func (r *runtimeFlightRecorder) Stop() error {
+	r.fr.Stop()
+	return nil
+}
+
+func (r *runtimeFlightRecorder) Snapshot(w io.Writer) error {
+	_, err := r.fr.WriteTo(w)
+	return err
+}
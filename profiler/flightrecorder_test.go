@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package profiler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/dd-trace-go/v2/internal/traceprof"
+)
+
+func TestFlightRecorderRuleMatch(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		rule  FlightRecorderRule
+		span  traceprof.FinishedSpan
+		match bool
+	}{
+		{
+			name:  "duration-only",
+			rule:  FlightRecorderRule{MinDuration: time.Second},
+			span:  traceprof.FinishedSpan{Service: "svc", Resource: "GET /", Duration: 2 * time.Second},
+			match: true,
+		},
+		{
+			name: "too-fast",
+			rule: FlightRecorderRule{MinDuration: time.Second},
+			span: traceprof.FinishedSpan{Service: "svc", Resource: "GET /", Duration: time.Millisecond},
+		},
+		{
+			name:  "glob",
+			rule:  FlightRecorderRule{Service: "web-*", Resource: "GET /users/*", MinDuration: time.Second},
+			span:  traceprof.FinishedSpan{Service: "web-store", Resource: "GET /users/:id", Duration: time.Second},
+			match: true,
+		},
+		{
+			name: "other-service",
+			rule: FlightRecorderRule{Service: "web-*", MinDuration: time.Second},
+			span: traceprof.FinishedSpan{Service: "worker", Resource: "GET /", Duration: time.Second},
+		},
+		{
+			name: "other-resource",
+			rule: FlightRecorderRule{Resource: "GET /users/*", MinDuration: time.Second},
+			span: traceprof.FinishedSpan{Service: "web", Resource: "POST /users/1", Duration: time.Second},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := compiledFlightRecorderRule{
+				service:     globMatch(tc.rule.Service),
+				resource:    globMatch(tc.rule.Resource),
+				minDuration: tc.rule.MinDuration,
+			}
+			assert.Equal(t, tc.match, r.match(tc.span))
+		})
+	}
+}
+
+func TestFlightRecorderSlowSpan(t *testing.T) {
+	profiles := startTestProfiler(t, 1,
+		WithProfileTypes(),
+		WithPeriod(time.Hour),
+		WithFlightRecorder(FlightRecorderRule{Resource: "GET /slow", MinDuration: 100 * time.Millisecond}),
+	)
+	hook := traceprof.SpanFinishHook()
+	require.NotNil(t, hook)
+
+	// Spans that don't match any rule don't trigger a snapshot.
+	hook(traceprof.FinishedSpan{Resource: "GET /slow", TraceID: "1", SpanID: 1, Duration: time.Millisecond})
+	hook(traceprof.FinishedSpan{Resource: "GET /fast", TraceID: "2", SpanID: 2, Duration: time.Second})
+	hook(traceprof.FinishedSpan{Resource: "GET /slow", TraceID: "abc123", SpanID: 42, Duration: time.Second})
+
+	m := <-profiles
+	assert.Contains(t, m.tags, "flight_recorder_trigger:slow_span")
+	assert.Contains(t, m.tags, "trigger_trace_id:abc123")
+	assert.Contains(t, m.tags, "trigger_span_id:42")
+	assert.Contains(t, m.tags, "go_execution_traced:yes")
+	assert.NotEmpty(t, m.attachments["go.trace"])
+
+	// The next slow span is throttled.
+	f := activeProfiler.flightRecorder
+	require.Equal(t, errFlightRecorderThrottled, f.startSnapshot("slow_span", "", 0))
+
+	Stop()
+	assert.Nil(t, traceprof.SpanFinishHook())
+	// A span finishing while the profiler stops doesn't start a snapshot.
+	f.lastSnapshot = time.Time{}
+	assert.Equal(t, errFlightRecorderNotRunning, f.startSnapshot("slow_span", "", 0))
+}
+
+func TestSnapshotExecutionTrace(t *testing.T) {
+	t.Run("not-running", func(t *testing.T) {
+		assert.Equal(t, errFlightRecorderNotRunning, SnapshotExecutionTrace("", 0))
+	})
+
+	t.Run("manual", func(t *testing.T) {
+		t.Setenv("DD_PROFILING_FLIGHT_RECORDER_ENABLED", "true")
+		profiles := startTestProfiler(t, 1,
+			WithProfileTypes(),
+			WithPeriod(time.Hour),
+		)
+		// Without rules, no span finish hook is registered.
+		assert.Nil(t, traceprof.SpanFinishHook())
+
+		require.NoError(t, SnapshotExecutionTrace("abc123", 42))
+		assert.Equal(t, errFlightRecorderThrottled, SnapshotExecutionTrace("abc123", 42))
+
+		m := <-profiles
+		assert.Contains(t, m.tags, "flight_recorder_trigger:manual")
+		assert.Contains(t, m.tags, "trigger_trace_id:abc123")
+		assert.NotEmpty(t, m.attachments["go.trace"])
+	})
+}
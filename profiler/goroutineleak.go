@@ -0,0 +1,329 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package profiler
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/dd-trace-go/v2/internal"
+	"github.com/DataDog/dd-trace-go/v2/internal/log"
+
+	pprofile "github.com/google/pprof/profile"
+)
+
+const (
+	// defaultGoroutineLeakGrowthCycles is the default number of consecutive
+	// profiling cycles during which the number of goroutines with the same
+	// stack has to grow for them to be reported as a possible leak.
+	defaultGoroutineLeakGrowthCycles = 5
+	// defaultGoroutineLeakBlockedThreshold is the default duration after
+	// which blocked goroutines are reported as a possible leak.
+	defaultGoroutineLeakBlockedThreshold = 10 * time.Minute
+	// goroutineLeakMinBlocked is the minimum number of goroutines blocked
+	// with the same stack for longer than the threshold to report them. A
+	// few long-lived goroutines waiting for e.g. a shutdown signal are
+	// expected in most programs, whereas leaks come in numbers.
+	goroutineLeakMinBlocked = 10
+)
+
+// goroutineLeakConfig controls the goroutine leak detector.
+type goroutineLeakConfig struct {
+	// enabled indicates whether the goroutine leak detector is enabled.
+	enabled bool
+	// growthCycles is the number of consecutive profiling cycles a stack
+	// count has to grow for to be reported.
+	growthCycles int
+	// blockedThreshold is the duration after which blocked goroutines are
+	// reported.
+	blockedThreshold time.Duration
+}
+
+// goroutineLeakConfigFromEnv returns the goroutine leak detector
+// configuration from the environment, applying defaults as needed.
+func goroutineLeakConfigFromEnv() goroutineLeakConfig {
+	return goroutineLeakConfig{
+		enabled:          internal.BoolEnv("DD_PROFILING_GOROUTINE_LEAK_DETECTION_ENABLED", false),
+		growthCycles:     internal.IntEnv("DD_PROFILING_GOROUTINE_LEAK_GROWTH_CYCLES", defaultGoroutineLeakGrowthCycles),
+		blockedThreshold: internal.DurationEnv("DD_PROFILING_GOROUTINE_LEAK_BLOCKED_THRESHOLD", defaultGoroutineLeakBlockedThreshold),
+	}
+}
+
+// goroutineLeak describes goroutines sharing the same stack which are
+// suspected to be leaking.
+type goroutineLeak struct {
+	kind  string // growth or blocked
+	stack string // function names, leaf first, separated by ";"
+	count int64
+}
+
+// function returns the most relevant function of the leaking stack for log
+// messages, i.e. the first one outside of the runtime and the standard
+// library synchronization primitives.
+func (l goroutineLeak) function() string {
+	funcs := strings.Split(l.stack, ";")
+	for _, fn := range funcs {
+		if !strings.HasPrefix(fn, "runtime.") && !strings.HasPrefix(fn, "sync.") && !strings.HasPrefix(fn, "internal/") {
+			return fn
+		}
+	}
+	return funcs[0]
+}
+
+// goroutineLeakDetector diffs the goroutine snapshots taken at the end of
+// every profiling cycle to find stacks whose goroutine count keeps growing,
+// and groups of goroutines blocked for longer than a threshold.
+type goroutineLeakDetector struct {
+	p *profiler
+	// history holds the goroutine count of every stack for the most recent
+	// profiling cycles, oldest first. Stacks disappearing from a snapshot
+	// are dropped.
+	history map[string][]int64
+	// reported holds the leaks which have already been logged, so that a
+	// persisting leak doesn't produce a warning every profiling cycle.
+	reported map[goroutineLeak]struct{}
+	// blocked holds the blocked goroutines found by the last goroutine dump
+	// taken by the detector, and lastDump the time it was taken, see
+	// dumpBlocked.
+	blocked  []goroutineLeak
+	lastDump time.Time
+}
+
+func newGoroutineLeakDetector(p *profiler) *goroutineLeakDetector {
+	return &goroutineLeakDetector{
+		p:        p,
+		history:  make(map[string][]int64),
+		reported: make(map[goroutineLeak]struct{}),
+	}
+}
+
+// check finds the suspected leaks in the goroutine profiles of a profiling
+// cycle, reports them as log warnings and metrics, and returns the tags to
+// attach to the next profile batch. Snapshots stop the world, so the goroutine and
+// goroutine wait profiles of the cycle are used when they're enabled rather
+// than taking new ones.
+func (d *goroutineLeakDetector) check(profiles []*profile) []string {
+	var goroutines, goroutinesWait []byte
+	for _, prof := range profiles {
+		switch prof.pt {
+		case GoroutineProfile:
+			goroutines = prof.data
+		case expGoroutineWaitProfile:
+			goroutinesWait = prof.data
+		}
+	}
+	if goroutines == nil {
+		var buf bytes.Buffer
+		if err := d.p.lookupProfile("goroutine", &buf, 0); err != nil {
+			log.Debug("Goroutine leak detection: %v", err)
+			return nil
+		}
+		goroutines = buf.Bytes()
+	}
+	counts, err := goroutineStackCounts(bytes.NewReader(goroutines))
+	if err != nil {
+		log.Debug("Goroutine leak detection: %v", err)
+		return nil
+	}
+	leaks := d.observe(counts)
+
+	if goroutinesWait != nil {
+		d.blocked = blockedGoroutines(bytes.NewReader(goroutinesWait), d.p.cfg.goroutineLeak.blockedThreshold)
+	} else {
+		d.dumpBlocked(counts)
+	}
+	leaks = append(leaks, d.blocked...)
+	return d.report(leaks)
+}
+
+// dumpBlocked updates the blocked goroutines when the goroutine wait profile
+// isn't collected. Wait durations are only available from the goroutine dump,
+// which stops the world for a time proportional to the number of goroutines,
+// so the dump is only taken if enough goroutines share a stack to be reported,
+// and at most once per blocked threshold: the goroutines it finds are still
+// reported in between.
+func (d *goroutineLeakDetector) dumpBlocked(counts map[string]int64) {
+	threshold := d.p.cfg.goroutineLeak.blockedThreshold
+	if !d.lastDump.IsZero() && time.Since(d.lastDump) < threshold {
+		return
+	}
+	d.blocked = nil
+	candidates := false
+	for _, n := range counts {
+		if n >= goroutineLeakMinBlocked {
+			candidates = true
+			break
+		}
+	}
+	if !candidates || runtime.NumGoroutine() > d.p.cfg.maxGoroutinesWait {
+		return
+	}
+	d.lastDump = time.Now()
+	var text, pprof bytes.Buffer
+	if err := d.p.lookupProfile("goroutine", &text, 2); err != nil {
+		log.Debug("Goroutine leak detection: %v", err)
+		return
+	}
+	if err := goroutineDebug2ToPprof(&text, &pprof, now()); err != nil {
+		log.Debug("Goroutine leak detection: %v", err)
+		return
+	}
+	d.blocked = blockedGoroutines(&pprof, threshold)
+}
+
+// observe records the goroutine counts of a new snapshot, and returns the
+// stacks whose count grew during each of the last growthCycles cycles.
+func (d *goroutineLeakDetector) observe(counts map[string]int64) []goroutineLeak {
+	cycles := max(d.p.cfg.goroutineLeak.growthCycles, 1)
+	var leaks []goroutineLeak
+	for stack, n := range counts {
+		h := append(d.history[stack], n)
+		if len(h) > cycles+1 {
+			h = h[len(h)-cycles-1:]
+		}
+		d.history[stack] = h
+		if len(h) <= cycles {
+			continue
+		}
+		growing := true
+		for i := 1; i < len(h); i++ {
+			if h[i] <= h[i-1] {
+				growing = false
+				break
+			}
+		}
+		if growing {
+			leaks = append(leaks, goroutineLeak{kind: "growth", stack: stack, count: n})
+		}
+	}
+	for stack := range d.history {
+		if _, ok := counts[stack]; !ok {
+			delete(d.history, stack)
+		}
+	}
+	return leaks
+}
+
+// report logs the leaks which weren't reported yet, counts all of them, and
+// returns the profile tags describing them.
+func (d *goroutineLeakDetector) report(leaks []goroutineLeak) []string {
+	sort.Slice(leaks, func(i, j int) bool { return leaks[i].count > leaks[j].count })
+	current := make(map[goroutineLeak]struct{}, len(leaks))
+	kinds := map[string]int64{}
+	for _, l := range leaks {
+		kinds[l.kind]++
+		key := goroutineLeak{kind: l.kind, stack: l.stack}
+		current[key] = struct{}{}
+		if _, ok := d.reported[key]; ok {
+			continue
+		}
+		switch l.kind {
+		case "growth":
+			log.Warn("Possible goroutine leak: the number of goroutines in %s grew during the last %d profiling cycles (%d goroutines, stack: %s)",
+				l.function(), d.p.cfg.goroutineLeak.growthCycles, l.count, l.stack)
+		case "blocked":
+			log.Warn("Possible goroutine leak: %d goroutines in %s have been blocked for more than %s (stack: %s)",
+				l.count, l.function(), d.p.cfg.goroutineLeak.blockedThreshold, l.stack)
+		}
+	}
+	// Forget the leaks which went away, so they are logged again if they
+	// come back.
+	d.reported = current
+
+	var tags []string
+	for _, kind := range []string{"growth", "blocked"} {
+		n, ok := kinds[kind]
+		if !ok {
+			continue
+		}
+		statsTags := append(d.p.cfg.tags.Slice(), "leak_kind:"+kind)
+		d.p.cfg.statsd.Count("datadog.profiling.go.goroutine_leak.suspected", n, statsTags, 1)
+		tags = append(tags, "goroutine_leak:"+kind)
+	}
+	return tags
+}
+
+// goroutineStackCounts returns the number of goroutines of every stack of a
+// goroutine profile in pprof format. Stacks are identified by their function
+// names, leaf first, separated by ";".
+func goroutineStackCounts(r io.Reader) (map[string]int64, error) {
+	prof, err := pprofile.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64)
+	var funcs []string
+	for _, s := range prof.Sample {
+		funcs = funcs[:0]
+		for _, loc := range s.Location {
+			for _, line := range loc.Line {
+				if line.Function != nil {
+					funcs = append(funcs, line.Function.Name)
+				}
+			}
+		}
+		if len(s.Value) > 0 {
+			// Samples with the same stack but different labels are merged.
+			counts[strings.Join(funcs, ";")] += s.Value[0]
+		}
+	}
+	return counts, nil
+}
+
+// blockedGoroutines returns the groups of at least goroutineLeakMinBlocked
+// goroutines with the same stack which have been blocked for longer than
+// threshold, according to a goroutine wait profile, see
+// goroutineDebug2ToPprof.
+func blockedGoroutines(r io.Reader, threshold time.Duration) []goroutineLeak {
+	prof, err := pprofile.Parse(r)
+	if err != nil {
+		log.Debug("Goroutine leak detection: %v", err)
+		return nil
+	}
+	counts := map[string]int64{}
+	var funcs []string
+	for _, s := range prof.Sample {
+		if len(s.Value) == 0 || time.Duration(s.Value[0]) < threshold {
+			continue
+		}
+		if state := s.Label["state"]; len(state) == 0 || !isBlockedState(state[0]) {
+			continue
+		}
+		funcs = funcs[:0]
+		for _, loc := range s.Location {
+			for _, line := range loc.Line {
+				if line.Function != nil {
+					funcs = append(funcs, line.Function.Name)
+				}
+			}
+		}
+		counts[strings.Join(funcs, ";")]++
+	}
+	var leaks []goroutineLeak
+	for stack, n := range counts {
+		if n >= goroutineLeakMinBlocked {
+			leaks = append(leaks, goroutineLeak{kind: "blocked", stack: stack, count: n})
+		}
+	}
+	return leaks
+}
+
+// isBlockedState reports whether a goroutine in the given state is blocked on
+// a channel or a synchronization primitive. Goroutines waiting for network
+// I/O or sleeping are not considered blocked, since idle servers have plenty
+// of those.
+func isBlockedState(state string) bool {
+	for _, prefix := range []string{"chan ", "select", "semacquire", "sync.", "sync "} {
+		if strings.HasPrefix(state, prefix) {
+			return true
+		}
+	}
+	return false
+}
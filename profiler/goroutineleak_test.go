@@ -0,0 +1,148 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025 Datadog, Inc.
+
+package profiler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoroutineLeakObserve(t *testing.T) {
+	p, err := unstartedProfiler()
+	require.NoError(t, err)
+	p.cfg.goroutineLeak.growthCycles = 3
+	d := newGoroutineLeakDetector(p)
+
+	leak := "runtime.gopark;runtime.chanrecv1;main.leak"
+	for i, want := range []bool{false, false, false, true, true, false} {
+		counts := map[string]int64{
+			leak:        []int64{1, 2, 3, 4, 5, 5}[i],
+			"main.main": 1,
+		}
+		leaks := d.observe(counts)
+		if !want {
+			assert.Empty(t, leaks, "cycle %d", i)
+			continue
+		}
+		require.Len(t, leaks, 1, "cycle %d", i)
+		assert.Equal(t, leak, leaks[0].stack)
+		assert.Equal(t, "main.leak", leaks[0].function())
+	}
+
+	// Stacks which disappear are forgotten.
+	d.observe(map[string]int64{})
+	assert.Empty(t, d.history)
+}
+
+func TestBlockedGoroutines(t *testing.T) {
+	var dump strings.Builder
+	writeGoroutines := func(n int, state, fn string) {
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&dump, "goroutine %d [%s]:\n%s(...)\n\t/app/main.go:10 +0x25\ncreated by main.main in goroutine 1\n\t/app/main.go:20 +0x45\n\n", dump.Len(), state, fn)
+		}
+	}
+	writeGoroutines(goroutineLeakMinBlocked, "chan receive, 15 minutes", "main.leak")
+	writeGoroutines(goroutineLeakMinBlocked, "chan receive, 2 minutes", "main.recent")
+	writeGoroutines(goroutineLeakMinBlocked, "IO wait, 30 minutes", "main.serve")
+	writeGoroutines(goroutineLeakMinBlocked-1, "select, 30 minutes", "main.few")
+
+	var pprof bytes.Buffer
+	require.NoError(t, goroutineDebug2ToPprof(strings.NewReader(dump.String()), &pprof, time.Now()))
+	leaks := blockedGoroutines(&pprof, 10*time.Minute)
+	require.Len(t, leaks, 1)
+	assert.Equal(t, "blocked", leaks[0].kind)
+	assert.Equal(t, "main.leak", leaks[0].function())
+	assert.Equal(t, int64(goroutineLeakMinBlocked), leaks[0].count)
+}
+
+func TestGoroutineLeakDetection(t *testing.T) {
+	t.Setenv("DD_PROFILING_GOROUTINE_LEAK_GROWTH_CYCLES", "2")
+	done := make(chan struct{})
+	defer close(done)
+	// Leak a goroutine every millisecond, so that their number grows every
+	// profiling cycle.
+	go func() {
+		tick := time.NewTicker(time.Millisecond)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+				go func() { <-done }()
+			}
+		}
+	}()
+
+	profiles := startTestProfiler(t, 10,
+		WithProfileTypes(),
+		WithPeriod(10*time.Millisecond),
+		WithGoroutineLeakDetection(true),
+	)
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case m := <-profiles:
+			if slices.Contains(m.tags, "goroutine_leak:growth") {
+				return
+			}
+		case <-timeout:
+			t.Fatal("the goroutine leak wasn't detected")
+		}
+	}
+}
+
+func TestGoroutineLeakCheckSnapshots(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	for i := 0; i < goroutineLeakMinBlocked; i++ {
+		go func() { <-done }()
+	}
+	snapshot := func(debug int) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, pprof.Lookup("goroutine").WriteTo(&buf, debug))
+		return buf.Bytes()
+	}
+
+	p, err := unstartedProfiler()
+	require.NoError(t, err)
+	lookups := map[int]int{}
+	p.testHooks.lookupProfile = func(name string, w io.Writer, debug int) error {
+		lookups[debug]++
+		_, err := w.Write(snapshot(debug))
+		return err
+	}
+	d := newGoroutineLeakDetector(p)
+
+	t.Run("profiles", func(t *testing.T) {
+		// The goroutine profiles of the cycle are used.
+		var wait bytes.Buffer
+		require.NoError(t, goroutineDebug2ToPprof(bytes.NewReader(snapshot(2)), &wait, time.Now()))
+		d.check([]*profile{
+			{pt: GoroutineProfile, data: snapshot(0)},
+			{pt: expGoroutineWaitProfile, data: wait.Bytes()},
+		})
+		assert.Empty(t, lookups)
+	})
+
+	t.Run("dump", func(t *testing.T) {
+		// Without them, the goroutine dump is only taken once per blocked
+		// threshold.
+		for i := 0; i < 3; i++ {
+			d.check(nil)
+		}
+		assert.Equal(t, map[int]int{0: 3, 2: 1}, lookups)
+	})
+}
@@ -107,9 +107,13 @@ type config struct {
 	mutexFraction        int
 	blockRate            int
 	outputDir            string
+	outputDirMaxBatches  int
 	deltaProfiles        bool
 	logStartup           bool
 	traceConfig          executionTraceConfig
+	flightRecorder       flightRecorderConfig
+	capture              captureConfig
+	goroutineLeak        goroutineLeakConfig
 	endpointCountEnabled bool
 	enabled              bool
 	flushOnExit          bool
@@ -143,9 +147,15 @@ func logStartup(c *config) {
 		"mutex_profile_fraction":     c.mutexFraction,
 		"max_goroutines_wait":        c.maxGoroutinesWait,
 		"upload_timeout":             c.uploadTimeout.String(),
+		"output_dir":                 c.outputDir,
+		"output_dir_max_batches":     c.outputDirMaxBatches,
 		"execution_trace_enabled":    c.traceConfig.Enabled,
 		"execution_trace_period":     c.traceConfig.Period.String(),
 		"execution_trace_size_limit": c.traceConfig.Limit,
+		"flight_recorder_enabled":    c.flightRecorder.enabled,
+		"flight_recorder_window":     c.flightRecorder.window.String(),
+		"remote_capture_enabled":     c.capture.remoteEnabled,
+		"goroutine_leak_detection":   c.goroutineLeak.enabled,
 		"endpoint_count_enabled":     c.endpointCountEnabled,
 		"custom_profiler_label_keys": c.customProfilerLabels,
 		"enabled":                    c.enabled,
@@ -272,10 +282,10 @@ func defaultConfig() (*config, error) {
 	if v := os.Getenv("DD_PROFILING_URL"); v != "" {
 		WithURL(v)(&c)
 	}
-	// not for public use
 	if v := os.Getenv("DD_PROFILING_OUTPUT_DIR"); v != "" {
-		withOutputDir(v)(&c)
+		WithOutputDir(v)(&c)
 	}
+	c.outputDirMaxBatches = internal.IntEnv("DD_PROFILING_OUTPUT_DIR_MAX_BATCHES", defaultOutputDirMaxBatches)
 	if v := os.Getenv("DD_PROFILING_WAIT_PROFILE_MAX_GOROUTINES"); v != "" {
 		n, err := strconv.Atoi(v)
 		if err != nil {
@@ -286,6 +296,9 @@ func defaultConfig() (*config, error) {
 
 	// Experimental feature: Go execution trace (runtime/trace) recording.
 	c.traceConfig.Refresh()
+	c.flightRecorder = flightRecorderConfigFromEnv()
+	c.capture = captureConfigFromEnv()
+	c.goroutineLeak = goroutineLeakConfigFromEnv()
 	return &c, nil
 }
 
@@ -472,15 +485,30 @@ func WithUDS(socketPath string) Option {
 	}
 }
 
-// withOutputDir writes a copy of all uploaded profiles to the given
-// directory. This is intended for local development or debugging uploading
-// issues. The directory will keep growing, no cleanup is performed.
-func withOutputDir(dir string) Option {
+// WithOutputDir writes a copy of every uploaded profile batch to its own
+// subdirectory of dir, named after the end time and sequence number of the
+// batch. Only the most recent batches are kept, see WithOutputDirMaxBatches.
+//
+// The DD_PROFILING_OUTPUT_DIR environment variable can be used instead. The
+// collected CPU profiles can be merged into a profile for profile-guided
+// optimization with the pgomerge command found in profiler/cmd/pgomerge.
+func WithOutputDir(dir string) Option {
 	return func(cfg *config) {
 		cfg.outputDir = dir
 	}
 }
 
+// WithOutputDirMaxBatches sets the number of profile batches kept in the
+// directory set with WithOutputDir. Older batches are removed as new ones are
+// written. A value of 0 or less keeps every batch. It defaults to 120, i.e.
+// two hours of profiles with the default period, and can be set with the
+// DD_PROFILING_OUTPUT_DIR_MAX_BATCHES environment variable as well.
+func WithOutputDirMaxBatches(n int) Option {
+	return func(cfg *config) {
+		cfg.outputDirMaxBatches = n
+	}
+}
+
 // WithLogStartup toggles logging the configuration of the profiler to standard
 // error when profiling is started. The configuration is logged in a JSON
 // format. This option is enabled by default.
@@ -562,3 +590,49 @@ func WithCustomProfilerLabelKeys(keys ...string) Option {
 		cfg.customProfilerLabels = append(cfg.customProfilerLabels, keys...)
 	}
 }
+
+// WithFlightRecorder enables the execution trace flight recorder. Instead of
+// periodically recording execution traces, the profiler keeps the most recent
+// execution trace data (roughly 10 seconds by default, see the
+// DD_PROFILING_FLIGHT_RECORDER_WINDOW environment variable) in memory, and
+// uploads a snapshot of it whenever a span matching one of the given rules
+// finishes, or when SnapshotExecutionTrace is called. Uploaded snapshots are
+// tagged with the IDs of the triggering trace and span.
+//
+// The flight recorder can also be enabled without rules by setting the
+// DD_PROFILING_FLIGHT_RECORDER_ENABLED environment variable to true, in which
+// case snapshots are only taken with SnapshotExecutionTrace.
+func WithFlightRecorder(rules ...FlightRecorderRule) Option {
+	return func(cfg *config) {
+		cfg.flightRecorder.enabled = true
+		cfg.flightRecorder.rules = append(cfg.flightRecorder.rules, rules...)
+	}
+}
+
+// WithRemoteCapture enables scheduling on-demand captures (see Capture)
+// through remote configuration. It is disabled by default, and can also be
+// enabled with the DD_PROFILING_CAPTURE_REMOTE_ENABLED environment variable.
+func WithRemoteCapture(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.capture.remoteEnabled = enabled
+	}
+}
+
+// WithGoroutineLeakDetection enables the goroutine leak detector. At the end
+// of every profiling cycle, it looks for stacks whose number of goroutines
+// grew during each of the last 5 cycles, and for groups of goroutines with the
+// same stack blocked on channels or locks for more than 10 minutes. Suspected
+// leaks are logged as warnings, counted with the
+// datadog.profiling.go.goroutine_leak.suspected metric, and tagged on the
+// profiles of the next cycle with the goroutine_leak tag.
+//
+// It can also be enabled with the
+// DD_PROFILING_GOROUTINE_LEAK_DETECTION_ENABLED environment variable. The
+// number of cycles and the blocking threshold can be changed with the
+// DD_PROFILING_GOROUTINE_LEAK_GROWTH_CYCLES and
+// DD_PROFILING_GOROUTINE_LEAK_BLOCKED_THRESHOLD environment variables.
+func WithGoroutineLeakDetection(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.goroutineLeak.enabled = enabled
+	}
+}
@@ -92,6 +92,12 @@ var profileTypes = map[ProfileType]profileType{
 			// period so that we're sure to capture the CPU usage of
 			// this library, which mostly happens at the end
 			p.interruptibleSleep(p.cfg.period - p.cfg.cpuDuration)
+			if !p.cpuProfileMu.TryLock() {
+				// An on-demand capture is running, and records
+				// its own CPU profile.
+				return nil, errCaptureRunning
+			}
+			defer p.cpuProfileMu.Unlock()
 			if p.cfg.cpuProfileRate != 0 {
 				// The profile has to be set each time before
 				// profiling is started. Otherwise,
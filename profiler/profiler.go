@@ -11,11 +11,11 @@ import (
 	"io"
 	"math/rand"
 	"os"
-	"path/filepath"
 	"runtime"
 	"runtime/pprof"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/DataDog/dd-trace-go/v2/internal"
@@ -93,13 +93,37 @@ type profiler struct {
 	wg              sync.WaitGroup    // wg waits for all goroutines to exit when stopping.
 	met             *metrics          // metric collector state
 	deltas          map[ProfileType]*fastDeltaProfiler
-	seq             uint64         // seq is the value of the profile_seq tag
+	seq             atomic.Uint64  // seq is the value of the profile_seq tag
 	pendingProfiles sync.WaitGroup // signal that profile collection is done, for stopping CPU profiling
 
 	testHooks testHooks
 
 	// lastTrace is the last time an execution trace was collected
 	lastTrace time.Time
+	// traceEnabled mirrors cfg.traceConfig.Enabled, which is refreshed by
+	// the collect loop, for the on-demand captures running concurrently
+	traceEnabled atomic.Bool
+
+	// flightRecorder is the execution trace flight recorder, if enabled
+	flightRecorder *flightRecorder
+
+	// captures holds the scheduled on-demand capture, see Capture
+	captures *captureScheduler
+	// cpuProfileMu is held while a CPU profile is running, as a program can
+	// only run one at a time: the periodic one or an on-demand capture's
+	cpuProfileMu sync.Mutex
+
+	// latest is the most recent batch of periodic profiles, see
+	// LatestProfilesHandler
+	latest atomic.Pointer[batch]
+	// outputMu serializes writes to the output directory
+	outputMu sync.Mutex
+
+	// goroutineLeaks is the goroutine leak detector, if enabled
+	goroutineLeaks *goroutineLeakDetector
+	// goroutineLeakTags holds the tags of the leaks found at the end of the
+	// last profiling cycle, applied to the next batch
+	goroutineLeakTags []string
 }
 
 // testHooks are functions that are replaced during testing which would normally
@@ -235,17 +259,21 @@ func newProfiler(opts ...Option) (*profiler, error) {
 	cfg.tags = immutable.NewStringSlice(tags)
 
 	p := profiler{
-		cfg:    cfg,
-		out:    make(chan batch, outChannelSize),
-		exit:   make(chan struct{}),
-		met:    newMetrics(),
-		deltas: make(map[ProfileType]*fastDeltaProfiler),
+		cfg:      cfg,
+		out:      make(chan batch, outChannelSize),
+		exit:     make(chan struct{}),
+		met:      newMetrics(),
+		deltas:   make(map[ProfileType]*fastDeltaProfiler),
+		captures: newCaptureScheduler(),
 	}
 	for pt := range cfg.types {
 		if d := profileTypes[pt].DeltaValues; len(d) > 0 {
 			p.deltas[pt] = newFastDeltaProfiler(d...)
 		}
 	}
+	if cfg.goroutineLeak.enabled {
+		p.goroutineLeaks = newGoroutineLeakDetector(&p)
+	}
 	p.uploadFunc = p.upload
 	return &p, nil
 }
@@ -262,6 +290,19 @@ func (p *profiler) run() {
 	if profileEnabled(BlockProfile) {
 		runtime.SetBlockProfileRate(p.cfg.blockRate)
 	}
+	if p.cfg.flightRecorder.enabled {
+		fr := newFlightRecorder(p)
+		if err := fr.start(); err != nil {
+			log.Error("Failed to start the execution trace flight recorder: %v", err)
+		} else {
+			p.flightRecorder = fr
+		}
+	}
+	if p.cfg.capture.remoteEnabled {
+		if err := p.startRemoteCaptures(); err != nil {
+			log.Warn("Failed to enable remotely triggered profile captures: %v", err)
+		}
+	}
 	startTelemetry(p.cfg)
 	p.wg.Add(1)
 	go func() {
@@ -276,6 +317,11 @@ func (p *profiler) run() {
 		defer p.wg.Done()
 		p.send()
 	}()
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.runCaptures()
+	}()
 }
 
 // collect runs the profile types found in the configuration whenever the ticker receives
@@ -303,7 +349,7 @@ func (p *profiler) collect(ticker <-chan time.Time) {
 	exit := false
 	for !exit {
 		bat := batch{
-			seq:   p.seq,
+			seq:   p.seq.Add(1) - 1,
 			host:  p.cfg.hostname,
 			start: now(),
 			extraTags: []string{
@@ -316,7 +362,7 @@ func (p *profiler) collect(ticker <-chan time.Time) {
 			},
 			customAttributes: p.cfg.customProfilerLabels,
 		}
-		p.seq++
+		bat.extraTags = append(bat.extraTags, p.goroutineLeakTags...)
 
 		clear(completed)
 		completed = completed[:0]
@@ -331,6 +377,7 @@ func (p *profiler) collect(ticker <-chan time.Time) {
 
 		// Decide whether we should record an execution trace
 		p.cfg.traceConfig.Refresh()
+		p.traceEnabled.Store(p.cfg.traceConfig.Enabled)
 		// Randomly record a trace with probability (profile period) / (trace period).
 		// Note that if the trace period is equal to or less than the profile period,
 		// we will always record a trace
@@ -341,7 +388,9 @@ func (p *profiler) collect(ticker <-chan time.Time) {
 		// profiling cycle since startup activity is generally much
 		// different than regular operation
 		firstCycle := bat.seq == 0
-		shouldTrace := p.cfg.traceConfig.Enabled && (shouldTraceRandomly || firstCycle)
+		// The flight recorder owns the execution tracer while it is running,
+		// so periodic execution traces are only collected without it.
+		shouldTrace := p.cfg.traceConfig.Enabled && p.flightRecorder == nil && (shouldTraceRandomly || firstCycle)
 		if shouldTrace {
 			profileTypes = append(profileTypes, executionTrace)
 		}
@@ -360,7 +409,9 @@ func (p *profiler) collect(ticker <-chan time.Time) {
 				}
 				profs, err := p.runProfile(t)
 				if err != nil {
-					if err != errProfilerStopped {
+					if err == errCaptureRunning {
+						log.Debug("Skipping the %s profile: %v", t, err)
+					} else if err != errProfilerStopped {
 						log.Error("Error getting %s profile: %v; skipping.", t, err)
 						tags := append(p.cfg.tags.Slice(), t.Tag())
 						p.cfg.statsd.Count("datadog.profiling.go.collect_error", 1, tags, 1)
@@ -373,6 +424,11 @@ func (p *profiler) collect(ticker <-chan time.Time) {
 			}(t)
 		}
 		wg.Wait()
+		if p.goroutineLeaks != nil {
+			// The profiles of the batch are already collected when the
+			// leaks are found, so they are tagged on the next one.
+			p.goroutineLeakTags = p.goroutineLeaks.check(completed)
+		}
 		for _, prof := range completed {
 			if prof.pt == executionTrace {
 				// If the profile batch includes a runtime execution trace, add a tag so
@@ -412,6 +468,7 @@ func (p *profiler) collect(ticker <-chan time.Time) {
 		// period) results in a factor of 1.
 		bat.end = time.Now()
 		// Upload profiling data.
+		p.latest.Store(&bat)
 		p.enqueueUpload(bat)
 	}
 }
@@ -485,28 +542,6 @@ func (p *profiler) send() {
 	}
 }
 
-func (p *profiler) outputDir(bat batch) error {
-	if p.cfg.outputDir == "" {
-		return nil
-	}
-	// Basic ISO 8601 Format in UTC as the name for the directories.
-	dir := bat.end.UTC().Format("20060102T150405Z")
-	dirPath := filepath.Join(p.cfg.outputDir, dir)
-	// 0755 is what mkdir does, should be reasonable for the use cases here.
-	if err := os.MkdirAll(dirPath, 0755); err != nil {
-		return err
-	}
-
-	for _, prof := range bat.profiles {
-		filePath := filepath.Join(dirPath, prof.name)
-		// 0644 is what touch does, should be reasonable for the use cases here.
-		if err := os.WriteFile(filePath, prof.data, 0644); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 // interruptibleSleep sleeps for the given duration or until interrupted by the
 // p.exit channel being closed.
 // Returns whether the sleep was interrupted
@@ -524,7 +559,17 @@ func (p *profiler) stop() {
 	p.stopOnce.Do(func() {
 		close(p.exit)
 	})
+	if p.flightRecorder != nil {
+		// Stop triggering snapshots before waiting for the pending ones.
+		p.flightRecorder.halt()
+	}
 	p.wg.Wait()
+	if p.flightRecorder != nil {
+		p.flightRecorder.stop()
+	}
+	if p.cfg.capture.remoteEnabled {
+		p.stopRemoteCaptures()
+	}
 	if p.cfg.logStartup {
 		log.Info("Profiling stopped")
 	}
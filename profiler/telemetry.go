@@ -46,6 +46,8 @@ func startTelemetry(c *config) {
 			{Name: "execution_trace_enabled", Value: c.traceConfig.Enabled},
 			{Name: "execution_trace_period", Value: c.traceConfig.Period.String()},
 			{Name: "execution_trace_size_limit", Value: c.traceConfig.Limit},
+			{Name: "remote_capture_enabled", Value: c.capture.remoteEnabled},
+			{Name: "goroutine_leak_detection_enabled", Value: c.goroutineLeak.enabled},
 			{Name: "endpoint_count_enabled", Value: c.endpointCountEnabled},
 			{Name: "num_custom_profiler_label_keys", Value: len(c.customProfilerLabels)},
 			{Name: "enabled", Value: c.enabled},